@@ -19,6 +19,8 @@ type Env struct {
 	Captcha     CaptchaEnv
 	SMTP        SMTPEnv
 	App         AppEnv
+	Surat       SuratEnv
+	Activity    ActivityEnv
 }
 
 type ServerEnv struct {
@@ -70,12 +72,56 @@ type SMTPEnv struct {
 	Password string `envconfig:"SMTP_PASSWORD" default:""`
 	From     string `envconfig:"SMTP_FROM" default:"noreply@raharja.info"`
 	FromName string `envconfig:"SMTP_FROM_NAME" default:"SIMAWA Raharja"`
+	// NoAuth mengirim tanpa login SMTP, untuk server lokal seperti Mailpit/MailHog.
+	NoAuth bool `envconfig:"SMTP_NO_AUTH" default:"false"`
 }
 
 type AppEnv struct {
 	EmailDomain string `envconfig:"EMAIL_DOMAIN" default:"@raharja.info"`
 }
 
+type ActivityEnv struct {
+	// CompleteCheckMinutes adalah jeda job yang menutup kegiatan APPROVED yang sudah berakhir (0 = nonaktif).
+	CompleteCheckMinutes int `envconfig:"ACTIVITY_COMPLETE_CHECK_MINUTES" default:"15"`
+	// LPJDueDays adalah batas pengiriman LPJ (hari) sejak kegiatan berakhir.
+	LPJDueDays int `envconfig:"ACTIVITY_LPJ_DUE_DAYS" default:"14"`
+	// VenueConflict menentukan perlakuan jadwal ruangan yang bentrok saat diajukan:
+	// REJECT menolak pengajuan, FLAG tetap menerima dan menandai kegiatan.
+	VenueConflict string `envconfig:"ACTIVITY_VENUE_CONFLICT" default:"REJECT"`
+	// RegistrationBaseURL adalah halaman status pendaftaran peserta yang ditautkan di email; token ditambahkan di belakangnya.
+	RegistrationBaseURL string `envconfig:"ACTIVITY_REGISTRATION_BASE_URL" default:"http://localhost:8080/public/registrations"`
+	// CheckinRotateSeconds adalah umur satu QR presensi sebelum diganti yang baru.
+	CheckinRotateSeconds int `envconfig:"ACTIVITY_CHECKIN_ROTATE_SECONDS" default:"30"`
+	// CheckinGraceMinutes membuka presensi sekian menit sebelum mulai dan sesudah selesai.
+	CheckinGraceMinutes int `envconfig:"ACTIVITY_CHECKIN_GRACE_MINUTES" default:"60"`
+}
+
+type SuratEnv struct {
+	// NumberPattern dipakai untuk menyusun nomor surat otomatis. Placeholder yang
+	// didukung: {seq}, {org_slug}, {variant_code}, {roman_month}, {month}, {year}.
+	NumberPattern string `envconfig:"SURAT_NUMBER_PATTERN" default:"{seq}/{org_slug}/{variant_code}/{roman_month}/{year}"`
+	SeqDigits     int    `envconfig:"SURAT_NUMBER_SEQ_DIGITS" default:"3"`
+
+	// VerifyBaseURL adalah URL publik yang dicetak di QR surat; kode verifikasi ditambahkan di belakangnya.
+	VerifyBaseURL string `envconfig:"SURAT_VERIFY_BASE_URL" default:"http://localhost:8080/public/surat/verify"`
+
+	// SignKeyPath menunjuk kunci privat Ed25519 (PKCS#8 PEM) untuk menandatangani surat yang disetujui.
+	// File dibuat otomatis bila belum ada; kosongkan untuk menonaktifkan penandatanganan.
+	SignKeyPath string `envconfig:"SURAT_SIGN_KEY_PATH" default:"keys/surat_signing.pem"`
+	SignKeyID   string `envconfig:"SURAT_SIGN_KEY_ID" default:"simawa-surat-1"`
+
+	// EmailMaxAttempts adalah jumlah percobaan kirim email surat per alamat sebelum dicatat gagal.
+	EmailMaxAttempts int `envconfig:"SURAT_EMAIL_MAX_ATTEMPTS" default:"3"`
+
+	// SignReminderHours adalah jeda pengingat otomatis ke penanda tangan yang belum bertindak (0 = nonaktif).
+	SignReminderHours int `envconfig:"SURAT_SIGN_REMINDER_HOURS" default:"24"`
+
+	// RetentionCheckHours adalah jeda job retensi arsip surat (0 = nonaktif); surat yang
+	// aturannya ARCHIVE dipindah ke ColdPrefix di bucket yang sama.
+	RetentionCheckHours int    `envconfig:"SURAT_RETENTION_CHECK_HOURS" default:"24"`
+	ColdPrefix          string `envconfig:"SURAT_COLD_PREFIX" default:"cold/"`
+}
+
 // GetEnv mirrors the backoffice-backend style: load .env files by gin mode,
 // then populate structured configuration.
 func GetEnv() (*Env, error) {
@@ -117,5 +163,11 @@ func GetEnv() (*Env, error) {
 	if err := envconfig.Process("", &env.App); err != nil {
 		return nil, fmt.Errorf("load app env: %w", err)
 	}
+	if err := envconfig.Process("", &env.Surat); err != nil {
+		return nil, fmt.Errorf("load surat env: %w", err)
+	}
+	if err := envconfig.Process("", &env.Activity); err != nil {
+		return nil, fmt.Errorf("load activity env: %w", err)
+	}
 	return env, nil
 }
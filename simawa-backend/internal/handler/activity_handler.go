@@ -12,6 +12,7 @@ import (
 	"github.com/minio/minio-go/v7"
 
 	"simawa-backend/internal/model"
+	"simawa-backend/internal/repository"
 	"simawa-backend/internal/service"
 	"simawa-backend/internal/util/sanitize"
 	"simawa-backend/internal/util/storage"
@@ -33,6 +34,7 @@ type createActivityRequest struct {
 	Title              string         `json:"title" binding:"required"`
 	Description        string         `json:"description"`
 	Location           string         `json:"location"`
+	VenueID            *uint          `json:"venue_id"` // ruangan dari katalog /v1/venues
 	Type               string         `json:"type"`
 	CollabType         string         `json:"collab_type"`          // INTERNAL, COLLAB, CAMPUS
 	CollaboratorOrgIDs []string       `json:"collaborator_org_ids"` // UUIDs of collaborating orgs
@@ -41,6 +43,8 @@ type createActivityRequest struct {
 	EndAt              int64          `json:"end_at" binding:"required"`
 	CoverKey           string         `json:"cover_key"`
 	Metadata           map[string]any `json:"metadata"`
+	RRule              string         `json:"rrule"`   // RFC 5545, mis. FREQ=WEEKLY;COUNT=10;BYDAY=MO
+	ExDates            []int64        `json:"exdates"` // epoch seconds kemunculan yang dikecualikan
 }
 
 func (h *ActivityHandler) Create(c *gin.Context) {
@@ -59,6 +63,10 @@ func (h *ActivityHandler) Create(c *gin.Context) {
 	orgID, _ := uuid.Parse(req.OrgID)
 	start := time.Unix(req.StartAt, 0)
 	end := time.Unix(req.EndAt, 0)
+	exdates := make([]time.Time, 0, len(req.ExDates))
+	for _, v := range req.ExDates {
+		exdates = append(exdates, time.Unix(v, 0))
+	}
 	userID, err := uuid.Parse(c.GetString("sub"))
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, response.Err("invalid user"))
@@ -69,6 +77,7 @@ func (h *ActivityHandler) Create(c *gin.Context) {
 		Title:              req.Title,
 		Description:        req.Description,
 		Location:           req.Location,
+		VenueID:            req.VenueID,
 		Type:               req.Type,
 		CollabType:         req.CollabType,
 		CollaboratorOrgIDs: req.CollaboratorOrgIDs,
@@ -78,6 +87,8 @@ func (h *ActivityHandler) Create(c *gin.Context) {
 		CoverKey:           req.CoverKey,
 		Metadata:           req.Metadata,
 		CreatedBy:          userID,
+		RRule:              req.RRule,
+		ExDates:            exdates,
 	})
 	if err != nil {
 		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
@@ -168,6 +179,29 @@ func (h *ActivityHandler) ListByOrg(c *gin.Context) {
 	if v := c.Query("start_to"); v != "" {
 		end, _ = time.Parse(time.RFC3339, v)
 	}
+	if c.Query("expand") == "true" {
+		// kalender: seri berulang diuraikan per kemunculan di jendela waktu
+		if start.IsZero() {
+			start = time.Now()
+		}
+		if end.IsZero() {
+			end = start.AddDate(0, 0, 90)
+		}
+		rows, err := h.svc.Occurrences(c.Request.Context(), repository.ActivityWindowQuery{
+			OrgID:      &orgID,
+			Status:     status,
+			Type:       actType,
+			PublicOnly: publicOnly,
+			From:       start,
+			To:         end,
+		})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"items": rows})
+		return
+	}
 	rows, err := h.svc.ListByOrg(c.Request.Context(), orgID, status, actType, publicOnly, page, size, start, end)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
@@ -181,12 +215,17 @@ func (h *ActivityHandler) Public(c *gin.Context) {
 	if days <= 0 {
 		days = 30
 	}
+	ahead, _ := strconv.Atoi(c.DefaultQuery("ahead", "180"))
+	if ahead <= 0 {
+		ahead = 180
+	}
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "0"))
 	if limit < 0 {
 		limit = 0
 	}
-	from := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
-	rows, err := h.svc.ListPublic(c.Request.Context(), from)
+	now := time.Now()
+	from := now.Add(-time.Duration(days) * 24 * time.Hour)
+	rows, err := h.svc.ListPublicOccurrences(c.Request.Context(), from, now.AddDate(0, 0, ahead))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
 		return
@@ -221,9 +260,11 @@ func (h *ActivityHandler) PublicRSS(c *gin.Context) {
 	c.Writer.WriteString("</channel></rss>")
 }
 
-// PublicICS returns a simple ICS calendar for public activities.
+// PublicICS returns a simple ICS calendar for public activities. Recurring series
+// are expanded to one VEVENT per occurrence within the feed window.
 func (h *ActivityHandler) PublicICS(c *gin.Context) {
-	rows, err := h.svc.ListPublic(c.Request.Context(), time.Now().Add(-30*24*time.Hour))
+	now := time.Now()
+	rows, err := h.svc.ListPublicOccurrences(c.Request.Context(), now.Add(-30*24*time.Hour), now.AddDate(1, 0, 0))
 	if err != nil {
 		c.String(http.StatusInternalServerError, "")
 		return
@@ -232,7 +273,15 @@ func (h *ActivityHandler) PublicICS(c *gin.Context) {
 	c.Writer.WriteString("BEGIN:VCALENDAR\nVERSION:2.0\nPRODID:-//SIMAWA//EN\n")
 	for _, a := range rows {
 		c.Writer.WriteString("BEGIN:VEVENT\n")
-		c.Writer.WriteString(fmt.Sprintf("UID:%s@simawa\n", a.ID))
+		if a.RecurrenceID != nil {
+			root := a.ID
+			if a.SeriesID != nil {
+				root = *a.SeriesID
+			}
+			c.Writer.WriteString(fmt.Sprintf("UID:%s-%d@simawa\n", root, a.RecurrenceID.Unix()))
+		} else {
+			c.Writer.WriteString(fmt.Sprintf("UID:%s@simawa\n", a.ID))
+		}
 		c.Writer.WriteString(fmt.Sprintf("DTSTAMP:%s\n", a.CreatedAt.UTC().Format("20060102T150405Z")))
 		c.Writer.WriteString(fmt.Sprintf("DTSTART:%s\n", a.StartAt.UTC().Format("20060102T150405Z")))
 		c.Writer.WriteString(fmt.Sprintf("DTEND:%s\n", a.EndAt.UTC().Format("20060102T150405Z")))
@@ -423,3 +472,95 @@ func (h *ActivityHandler) GetActivityPhotos(c *gin.Context) {
 		"gallery": a.GalleryURLs,
 	}))
 }
+
+// VenueConflicts menampilkan jadwal lain yang bentrok dengan ruangan kegiatan (layar approval).
+func (h *ActivityHandler) VenueConflicts(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("invalid id"))
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	rows, err := h.svc.VenueConflicts(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(rows))
+}
+
+type occurrenceReq struct {
+	Scope       string `json:"scope"` // this | following
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Location    string `json:"location"`
+	Type        string `json:"type"`
+	StartAt     int64  `json:"start_at"` // epoch seconds, 0 = tidak diubah
+	EndAt       int64  `json:"end_at"`
+	RRule       string `json:"rrule"` // hanya untuk scope following
+}
+
+// occurrenceParams membaca :id dan :start (epoch seconds awal asli kemunculan).
+func occurrenceParams(c *gin.Context) (uuid.UUID, time.Time, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("invalid id"))
+		return uuid.Nil, time.Time{}, false
+	}
+	sec, err := strconv.ParseInt(c.Param("start"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("invalid occurrence start"))
+		return uuid.Nil, time.Time{}, false
+	}
+	return id, time.Unix(sec, 0), true
+}
+
+// UpdateOccurrence mengubah satu kemunculan seri atau kemunculan itu dan seterusnya.
+func (h *ActivityHandler) UpdateOccurrence(c *gin.Context) {
+	id, occ, ok := occurrenceParams(c)
+	if !ok {
+		return
+	}
+	var req occurrenceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	in := &service.UpdateOccurrenceInput{
+		Title:       sanitize.String(req.Title),
+		Description: sanitize.String(req.Description),
+		Location:    sanitize.String(req.Location),
+		Type:        sanitize.String(req.Type),
+		RRule:       req.RRule,
+	}
+	if req.StartAt > 0 {
+		t := time.Unix(req.StartAt, 0)
+		in.StartAt = &t
+	}
+	if req.EndAt > 0 {
+		t := time.Unix(req.EndAt, 0)
+		in.EndAt = &t
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	a, err := h.svc.UpdateOccurrence(c.Request.Context(), userID, id, occ, req.Scope, in)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(a))
+}
+
+// CancelOccurrence membatalkan satu kemunculan (?scope=this) atau kemunculan itu dan seterusnya (?scope=following).
+func (h *ActivityHandler) CancelOccurrence(c *gin.Context) {
+	id, occ, ok := occurrenceParams(c)
+	if !ok {
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	a, err := h.svc.CancelOccurrence(c.Request.Context(), userID, id, occ, c.Query("scope"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(a))
+}
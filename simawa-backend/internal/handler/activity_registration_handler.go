@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/service"
+	"simawa-backend/internal/util/sanitize"
+	"simawa-backend/pkg/response"
+)
+
+type RegistrationHandler struct {
+	svc *service.RegistrationService
+}
+
+func NewRegistrationHandler(svc *service.RegistrationService) *RegistrationHandler {
+	return &RegistrationHandler{svc: svc}
+}
+
+func activityParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("invalid id"))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+type registrationFormRequest struct {
+	Enabled       bool                         `json:"enabled"`
+	Quota         int                          `json:"quota"`          // 0 = tanpa batas
+	WaitlistLimit int                          `json:"waitlist_limit"` // 0 = tanpa batas
+	OpensAt       int64                        `json:"opens_at"`       // epoch seconds, 0 = langsung dibuka
+	ClosesAt      int64                        `json:"closes_at"`      // epoch seconds, 0 = sampai kegiatan berakhir
+	Questions     []model.RegistrationQuestion `json:"questions"`
+}
+
+// SaveForm PUT /v1/activities/:id/registration
+func (h *RegistrationHandler) SaveForm(c *gin.Context) {
+	id, ok := activityParam(c)
+	if !ok {
+		return
+	}
+	var req registrationFormRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	in := &service.RegistrationFormInput{
+		Enabled:       req.Enabled,
+		Quota:         req.Quota,
+		WaitlistLimit: req.WaitlistLimit,
+		Questions:     req.Questions,
+	}
+	for i := range in.Questions {
+		in.Questions[i].Label = sanitize.String(in.Questions[i].Label)
+	}
+	if req.OpensAt > 0 {
+		t := time.Unix(req.OpensAt, 0)
+		in.OpensAt = &t
+	}
+	if req.ClosesAt > 0 {
+		t := time.Unix(req.ClosesAt, 0)
+		in.ClosesAt = &t
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	v, err := h.svc.SaveForm(c.Request.Context(), userID, id, in)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(v))
+}
+
+// Form GET /public/activities/:id/registration
+func (h *RegistrationHandler) Form(c *gin.Context) {
+	id, ok := activityParam(c)
+	if !ok {
+		return
+	}
+	v, err := h.svc.Form(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(v))
+}
+
+type registerRequest struct {
+	Name    string         `json:"name"`
+	Email   string         `json:"email"`
+	Phone   string         `json:"phone"`
+	Answers map[string]any `json:"answers"`
+}
+
+func (h *RegistrationHandler) register(c *gin.Context, userID *uuid.UUID) {
+	id, ok := activityParam(c)
+	if !ok {
+		return
+	}
+	var req registerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	for k, v := range req.Answers {
+		if s, ok := v.(string); ok {
+			req.Answers[k] = sanitize.String(s)
+		}
+	}
+	reg, err := h.svc.Register(c.Request.Context(), id, &service.RegisterInput{
+		UserID:  userID,
+		Name:    sanitize.String(req.Name),
+		Email:   req.Email,
+		Phone:   sanitize.String(req.Phone),
+		Answers: req.Answers,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	out := gin.H{"registration": reg}
+	// token hanya diberikan bila email pendaftar sudah terverifikasi lewat akunnya;
+	// selain itu token hanya dikirim ke email pendaftar
+	if userID != nil && reg.Status != model.RegistrationStatusUnconfirmed {
+		out["token"] = reg.Token
+	}
+	c.JSON(http.StatusCreated, response.OK(out))
+}
+
+// Register POST /public/activities/:id/register (tanpa akun)
+func (h *RegistrationHandler) Register(c *gin.Context) {
+	h.register(c, nil)
+}
+
+// RegisterMe POST /v1/activities/:id/register; nama dan email diambil dari akun bila kosong.
+func (h *RegistrationHandler) RegisterMe(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("sub"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err("invalid user"))
+		return
+	}
+	h.register(c, &userID)
+}
+
+// Status GET /public/registrations/:token
+func (h *RegistrationHandler) Status(c *gin.Context) {
+	reg, a, err := h.svc.GetByToken(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(gin.H{
+		"registration": reg,
+		"activity": gin.H{
+			"id":       a.ID,
+			"title":    a.Title,
+			"location": a.Location,
+			"start_at": a.StartAt,
+			"end_at":   a.EndAt,
+		},
+	}))
+}
+
+// ConfirmByToken POST /public/registrations/:token/confirm
+func (h *RegistrationHandler) ConfirmByToken(c *gin.Context) {
+	reg, err := h.svc.ConfirmByToken(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(reg))
+}
+
+// CancelByToken POST /public/registrations/:token/cancel
+func (h *RegistrationHandler) CancelByToken(c *gin.Context) {
+	reg, err := h.svc.CancelByToken(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(reg))
+}
+
+// List GET /v1/activities/:id/registrations?status=
+func (h *RegistrationHandler) List(c *gin.Context) {
+	id, ok := activityParam(c)
+	if !ok {
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	rows, err := h.svc.List(c.Request.Context(), userID, id, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(rows))
+}
+
+// Export GET /v1/activities/:id/registrations/export (CSV)
+func (h *RegistrationHandler) Export(c *gin.Context) {
+	id, ok := activityParam(c)
+	if !ok {
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	data, filename, err := h.svc.Export(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "text/csv", data)
+}
+
+// Cancel DELETE /v1/activities/:id/registrations/:reg_id (oleh penyelenggara)
+func (h *RegistrationHandler) Cancel(c *gin.Context) {
+	id, ok := activityParam(c)
+	if !ok {
+		return
+	}
+	regID, err := strconv.ParseUint(c.Param("reg_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("invalid registration id"))
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	reg, err := h.svc.Cancel(c.Request.Context(), userID, id, uint(regID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(reg))
+}
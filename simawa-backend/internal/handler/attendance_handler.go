@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	qrcode "github.com/skip2/go-qrcode"
+
+	"simawa-backend/internal/service"
+	"simawa-backend/pkg/response"
+)
+
+type AttendanceHandler struct {
+	svc *service.AttendanceService
+}
+
+func NewAttendanceHandler(svc *service.AttendanceService) *AttendanceHandler {
+	return &AttendanceHandler{svc: svc}
+}
+
+type checkInRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// writeToken mengirim token sebagai JSON, atau gambar QR bila ?format=png.
+func writeToken(c *gin.Context, t *service.CheckInToken) {
+	if c.Query("format") != "png" {
+		c.JSON(http.StatusOK, response.OK(t))
+		return
+	}
+	png, err := qrcode.Encode(t.Token, qrcode.Medium, 512)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// ActivityQR GET /v1/activities/:id/attendance/qr
+func (h *AttendanceHandler) ActivityQR(c *gin.Context) {
+	id, ok := activityParam(c)
+	if !ok {
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	t, err := h.svc.ActivityToken(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	writeToken(c, t)
+}
+
+// MyQR GET /v1/attendance/me/qr
+func (h *AttendanceHandler) MyQR(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("sub"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err("unauthorized"))
+		return
+	}
+	writeToken(c, h.svc.PersonalToken(userID))
+}
+
+// CheckIn POST /v1/attendance/check-in
+func (h *AttendanceHandler) CheckIn(c *gin.Context) {
+	var req checkInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	userID, err := uuid.Parse(c.GetString("sub"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err("unauthorized"))
+		return
+	}
+	res, err := h.svc.CheckIn(c.Request.Context(), userID, req.Token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(res))
+}
+
+// Scan POST /v1/activities/:id/attendance/scan
+func (h *AttendanceHandler) Scan(c *gin.Context) {
+	id, ok := activityParam(c)
+	if !ok {
+		return
+	}
+	var req checkInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	res, err := h.svc.Scan(c.Request.Context(), userID, id, req.Token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(res))
+}
+
+// Overview GET /v1/activities/:id/attendance/summary
+func (h *AttendanceHandler) Overview(c *gin.Context) {
+	id, ok := activityParam(c)
+	if !ok {
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	v, err := h.svc.Overview(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(v))
+}
+
+// List GET /v1/activities/:id/attendance
+func (h *AttendanceHandler) List(c *gin.Context) {
+	id, ok := activityParam(c)
+	if !ok {
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	items, err := h.svc.List(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(items))
+}
+
+// Export GET /v1/activities/:id/attendance/export
+func (h *AttendanceHandler) Export(c *gin.Context) {
+	id, ok := activityParam(c)
+	if !ok {
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	data, filename, err := h.svc.Export(c.Request.Context(), userID, id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "text/csv", data)
+}
@@ -310,3 +310,20 @@ func (h *LPJHandler) Download(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"url": url, "file_key": row.ReportKey})
 }
+
+// AttendanceExport returns the activity attendance list (CSV) as LPJ evidence.
+func (h *LPJHandler) AttendanceExport(c *gin.Context) {
+	lpjID, err := uuid.Parse(c.Param("lpj_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("invalid id"))
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	data, filename, err := h.svc.AttendanceCSV(c.Request.Context(), userID, lpjID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "text/csv", data)
+}
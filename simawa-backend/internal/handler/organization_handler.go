@@ -11,8 +11,10 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/minio/minio-go/v7"
 	"gorm.io/gorm"
+	"simawa-backend/internal/model"
 	"simawa-backend/internal/service"
 	"simawa-backend/internal/util/sanitize"
+	"simawa-backend/internal/util/suratpdf"
 	"simawa-backend/pkg/response"
 )
 
@@ -57,6 +59,27 @@ type updateOrgRequest struct {
 	LinkedinURL  *string        `json:"linkedin_url"`
 	GalleryURLs  []string       `json:"gallery_urls"`
 	Links        map[string]any `json:"links"`
+
+	SuratNumberPattern *string              `json:"surat_number_pattern"`
+	Letterhead         *model.OrgLetterhead `json:"letterhead"`
+}
+
+// validateLetterhead memeriksa gaya garis dan warna profil kop surat.
+func validateLetterhead(kop *model.OrgLetterhead) string {
+	switch strings.ToLower(strings.TrimSpace(kop.LineStyle)) {
+	case "", suratpdf.LineSingle, suratpdf.LineDouble, suratpdf.LineThickThin, suratpdf.LineNone:
+	default:
+		return "Gaya garis kop harus single, double, thick_thin, atau none."
+	}
+	for _, v := range []string{kop.TextColor, kop.LineColor} {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		if _, _, _, ok := suratpdf.ParseHexColor(v); !ok || !strings.HasPrefix(strings.TrimSpace(v), "#") {
+			return "Warna kop harus dalam format #RRGGBB."
+		}
+	}
+	return ""
 }
 
 func mapOrgUpdateError(err error) (int, string) {
@@ -201,6 +224,18 @@ func (h *OrganizationHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if req.Letterhead != nil {
+		if msg := validateLetterhead(req.Letterhead); msg != "" {
+			c.JSON(http.StatusBadRequest, response.Err(msg))
+			return
+		}
+		kop := req.Letterhead
+		kop.Institution = sanitize.String(kop.Institution)
+		kop.Unit = sanitize.String(kop.Unit)
+		kop.Address = sanitize.String(kop.Address)
+		kop.Phone = sanitize.String(kop.Phone)
+	}
+
 	// Sanitize input
 	if req.Name != nil {
 		s := sanitize.String(*req.Name)
@@ -237,6 +272,9 @@ func (h *OrganizationHandler) Update(c *gin.Context) {
 		LinkedinURL:  req.LinkedinURL,
 		GalleryURLs:  req.GalleryURLs,
 		Links:        req.Links,
+
+		SuratNumberPattern: req.SuratNumberPattern,
+		Letterhead:         req.Letterhead,
 	})
 	if err != nil {
 		status, message := mapOrgUpdateError(err)
@@ -13,7 +13,8 @@ import (
 )
 
 // UploadImage uploads an organization image (hero/logo) to Minio and updates the org profile.
-// Query param: kind=hero|logo (default: hero). Multipart field: file.
+// Query param: kind=hero|logo|gallery|kop_left|kop_right (default: hero). Multipart field: file.
+// kop_left/kop_right mengisi logo kiri/kanan profil kop surat.
 func (h *OrganizationHandler) UploadImage(c *gin.Context) {
 	if h.minio == nil || h.bucket == "" || h.minioPublicBaseURL == "" {
 		c.JSON(http.StatusBadRequest, response.Err("storage not configured"))
@@ -40,7 +41,7 @@ func (h *OrganizationHandler) UploadImage(c *gin.Context) {
 	}
 
 	kind := strings.ToLower(strings.TrimSpace(c.DefaultQuery("kind", "hero")))
-	if kind != "hero" && kind != "logo" && kind != "gallery" {
+	if kind != "hero" && kind != "logo" && kind != "gallery" && kind != "kop_left" && kind != "kop_right" {
 		c.JSON(http.StatusBadRequest, response.Err("invalid kind"))
 		return
 	}
@@ -129,6 +130,22 @@ func (h *OrganizationHandler) UploadImage(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
 			return
 		}
+	case "kop_left", "kop_right":
+		kop := org.Letterhead
+		if kind == "kop_left" {
+			kop.LeftLogoKey = key
+		} else {
+			kop.RightLogoKey = key
+		}
+		if _, err := h.svc.Update(
+			c.Request.Context(),
+			userID,
+			org,
+			service.UpdateOrgInput{Letterhead: &kop},
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+			return
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{"file_key": key, "url": url})
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/service"
+	"simawa-backend/pkg/response"
+)
+
+type SearchHandler struct {
+	svc *service.SearchService
+}
+
+func NewSearchHandler(svc *service.SearchService) *SearchHandler {
+	return &SearchHandler{svc: svc}
+}
+
+// Search GET /v1/search?q=&type=surat,activity,lpj&page=&size=
+func (h *SearchHandler) Search(c *gin.Context) {
+	sub, _ := c.Get("sub")
+	userID, err := uuid.Parse(fmt.Sprint(sub))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err("invalid user context"))
+		return
+	}
+	var types []string
+	for _, v := range c.QueryArray("type") {
+		types = append(types, strings.Split(v, ",")...)
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+	res, err := h.svc.Search(c.Request.Context(), userID, c.Query("q"), types, page, size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": res})
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/repository"
+	"simawa-backend/internal/service"
+	"simawa-backend/internal/util/sanitize"
+	"simawa-backend/pkg/response"
+)
+
+// SuratMasukTypes adalah tipe scan surat masuk yang diterima.
+var SuratMasukTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+// RegisterIncoming mencatat surat masuk dari luar sistem (multipart: file, org_id,
+// sender, sender_number, subject, letter_date, received_at, classification, note).
+func (h *SuratHandler) RegisterIncoming(c *gin.Context) {
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	orgID, err := uuid.Parse(c.PostForm("org_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("invalid org_id"))
+		return
+	}
+	if h.rbac != nil {
+		ok, _ := h.rbac.CanManageOrg(c.Request.Context(), userID, &model.Organization{ID: orgID})
+		if !ok {
+			c.JSON(http.StatusForbidden, response.Err("forbidden"))
+			return
+		}
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("file is required"))
+		return
+	}
+	if fh.Size > maxLampiranSize {
+		c.JSON(http.StatusBadRequest, response.Err("file size exceeds 10MB limit"))
+		return
+	}
+	up, err := readLampiran(fh)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	if !SuratMasukTypes[up.ContentType] {
+		c.JSON(http.StatusBadRequest, response.Err("only PDF, JPEG or PNG scans are allowed"))
+		return
+	}
+
+	in := &service.RegisterIncomingInput{
+		OrgID:          orgID,
+		Sender:         sanitize.String(c.PostForm("sender")),
+		SenderNumber:   sanitize.String(c.PostForm("sender_number")),
+		Subject:        sanitize.String(c.PostForm("subject")),
+		Classification: c.PostForm("classification"),
+		Note:           sanitize.String(c.PostForm("note")),
+		File:           bytes.NewReader(up.Data),
+		FileName:       filepath.Base(up.Name),
+		ContentType:    up.ContentType,
+		RegisteredBy:   userID,
+	}
+	if v := c.PostForm("letter_date"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("invalid letter_date format, use YYYY-MM-DD"))
+			return
+		}
+		in.LetterDate = &d
+	}
+	if v := c.PostForm("received_at"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("invalid received_at format, use YYYY-MM-DD"))
+			return
+		}
+		in.ReceivedAt = d
+	}
+
+	row, err := h.svc.RegisterIncoming(c.Request.Context(), in, h.minio, h.bucket)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(row))
+}
+
+// ListAgenda menampilkan buku agenda surat masuk organisasi.
+func (h *SuratHandler) ListAgenda(c *gin.Context) {
+	q, ok := h.agendaQuery(c)
+	if !ok {
+		return
+	}
+	q.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	q.Size, _ = strconv.Atoi(c.DefaultQuery("size", "10"))
+	if q.Size <= 0 {
+		q.Size = 10
+	}
+	rows, total, err := h.svc.ListAgenda(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"items": rows,
+			"total": total,
+		},
+	})
+}
+
+// ExportAgenda mengunduh buku agenda per periode (?from=&to=) sebagai CSV atau PDF (?format=pdf).
+func (h *SuratHandler) ExportAgenda(c *gin.Context) {
+	q, ok := h.agendaQuery(c)
+	if !ok {
+		return
+	}
+	data, filename, contentType, err := h.svc.ExportAgenda(c.Request.Context(), q, c.DefaultQuery("format", "csv"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// agendaQuery parses org_id/from/to/q and checks the caller can manage the org.
+func (h *SuratHandler) agendaQuery(c *gin.Context) (repository.ListSuratAgendaQuery, bool) {
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("invalid org id"))
+		return repository.ListSuratAgendaQuery{}, false
+	}
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return repository.ListSuratAgendaQuery{}, false
+	}
+	if h.rbac != nil {
+		ok, _ := h.rbac.CanManageOrg(c.Request.Context(), userID, &model.Organization{ID: orgID})
+		if !ok {
+			c.JSON(http.StatusForbidden, response.Err("forbidden"))
+			return repository.ListSuratAgendaQuery{}, false
+		}
+	}
+	q := repository.ListSuratAgendaQuery{OrgID: orgID, Q: c.Query("q")}
+	if v := c.Query("from"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("invalid from format, use YYYY-MM-DD"))
+			return repository.ListSuratAgendaQuery{}, false
+		}
+		q.From = &d
+	}
+	if v := c.Query("to"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("invalid to format, use YYYY-MM-DD"))
+			return repository.ListSuratAgendaQuery{}, false
+		}
+		// "to" inklusif untuk user, eksklusif di query
+		d = d.AddDate(0, 0, 1)
+		q.To = &d
+	}
+	return q, true
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/service"
+	"simawa-backend/internal/util/sanitize"
+	"simawa-backend/pkg/response"
+)
+
+const maxLampiranSize = 10 * 1024 * 1024
+
+// ListAttachments menampilkan lampiran surat sesuai urutan halaman.
+func (h *SuratHandler) ListAttachments(c *gin.Context) {
+	row, ok := h.accessibleSurat(c)
+	if !ok {
+		return
+	}
+	rows, err := h.svc.ListAttachments(c.Request.Context(), row.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"items": rows,
+			"total": len(rows),
+		},
+	})
+}
+
+// AddAttachments mengunggah satu atau beberapa lampiran (field "files" atau "file").
+func (h *SuratHandler) AddAttachments(c *gin.Context) {
+	row, ok := h.managedSurat(c)
+	if !ok {
+		return
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("file is required"))
+		return
+	}
+	headers := append(form.File["files"], form.File["file"]...)
+	if len(headers) == 0 {
+		c.JSON(http.StatusBadRequest, response.Err("file is required"))
+		return
+	}
+
+	files := make([]service.SuratAttachmentUpload, 0, len(headers))
+	for _, fh := range headers {
+		if fh.Size > maxLampiranSize {
+			c.JSON(http.StatusBadRequest, response.Err("file size exceeds 10MB limit"))
+			return
+		}
+		up, err := readLampiran(fh)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+			return
+		}
+		files = append(files, up)
+	}
+
+	userID, _ := h.currentUser(c)
+	res, err := h.svc.AddAttachments(c.Request.Context(), userID, row.ID, files, h.minio, h.bucket)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(res))
+}
+
+func (h *SuratHandler) DeleteAttachment(c *gin.Context) {
+	row, ok := h.managedSurat(c)
+	if !ok {
+		return
+	}
+	attID, _ := strconv.Atoi(c.Param("aid"))
+	userID, _ := h.currentUser(c)
+	if err := h.svc.DeleteAttachment(c.Request.Context(), userID, row.ID, uint(attID), h.minio, h.bucket); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK("deleted"))
+}
+
+// DownloadBundle mengunduh ZIP berisi PDF surat dan semua lampiran aslinya.
+func (h *SuratHandler) DownloadBundle(c *gin.Context) {
+	row, ok := h.accessibleSurat(c)
+	if !ok {
+		return
+	}
+	data, filename, err := h.svc.AttachmentBundle(c.Request.Context(), row.ID, h.minio, h.bucket)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "application/zip", data)
+}
+
+// managedSurat memuat surat dari param :id dan memastikan user mengelola organisasi pengirimnya.
+func (h *SuratHandler) managedSurat(c *gin.Context) (*model.Surat, bool) {
+	idNum, _ := strconv.Atoi(c.Param("id"))
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return nil, false
+	}
+	row, err := h.svc.Get(c.Request.Context(), uint(idNum))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err(err.Error()))
+		return nil, false
+	}
+	if h.rbac != nil {
+		ok, _ := h.rbac.CanManageOrg(c.Request.Context(), userID, &model.Organization{ID: row.OrgID})
+		if !ok {
+			c.JSON(http.StatusForbidden, response.Err("forbidden"))
+			return nil, false
+		}
+	}
+	return row, true
+}
+
+func readLampiran(fh *multipart.FileHeader) (service.SuratAttachmentUpload, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return service.SuratAttachmentUpload{}, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return service.SuratAttachmentUpload{}, err
+	}
+	return service.SuratAttachmentUpload{
+		Name:        sanitize.String(filepath.Base(fh.Filename)),
+		ContentType: http.DetectContentType(data),
+		Data:        data,
+	}, nil
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/service"
+	"simawa-backend/internal/util/sanitize"
+	"simawa-backend/internal/util/suratpdf"
+	"simawa-backend/pkg/response"
+)
+
+type createSuratBatchRequest struct {
+	OrgID         string                   `json:"org_id" binding:"required,uuid"`
+	Payload       suratpdf.Payload         `json:"payload" binding:"required"`
+	Theme         *suratpdf.Theme          `json:"theme"`
+	TemplateID    *uint                    `json:"template_id"`
+	Variables     map[string]string        `json:"variables"`
+	ActivityID    string                   `json:"activity_id"`
+	Recipients    []service.SuratRecipient `json:"recipients"`
+	RecipientsCSV string                   `json:"recipients_csv"`
+	EmailCc       []string                 `json:"email_cc"`
+}
+
+// CreateBatch memulai mail-merge. Terima JSON (recipients atau recipients_csv),
+// atau multipart dengan field "data" berisi JSON yang sama dan file CSV "recipients".
+func (h *SuratHandler) CreateBatch(c *gin.Context) {
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	var req createSuratBatchRequest
+	if c.ContentType() == "multipart/form-data" {
+		if err := json.Unmarshal([]byte(c.PostForm("data")), &req); err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("invalid data field"))
+			return
+		}
+		if _, err := uuid.Parse(req.OrgID); err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("invalid org_id"))
+			return
+		}
+		if file, _, err := c.Request.FormFile("recipients"); err == nil {
+			defer file.Close()
+			rcps, err := service.ParseSuratRecipientsCSV(file)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+				return
+			}
+			req.Recipients = append(req.Recipients, rcps...)
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	if strings.TrimSpace(req.RecipientsCSV) != "" {
+		rcps, err := service.ParseSuratRecipientsCSV(strings.NewReader(req.RecipientsCSV))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+			return
+		}
+		req.Recipients = append(req.Recipients, rcps...)
+	}
+
+	orgID, _ := uuid.Parse(req.OrgID)
+	if h.rbac != nil {
+		ok, _ := h.rbac.CanManageOrg(c.Request.Context(), userID, &model.Organization{ID: orgID})
+		if !ok {
+			c.JSON(http.StatusForbidden, response.Err("forbidden"))
+			return
+		}
+	}
+	var activityID *uuid.UUID
+	if strings.TrimSpace(req.ActivityID) != "" {
+		aid, err := uuid.Parse(req.ActivityID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("invalid activity_id"))
+			return
+		}
+		activityID = &aid
+	}
+	for k, v := range req.Variables {
+		req.Variables[k] = sanitize.String(v)
+	}
+	for i := range req.Recipients {
+		r := &req.Recipients[i]
+		r.ToRole = sanitize.String(r.ToRole)
+		r.ToName = sanitize.String(r.ToName)
+		r.ToPlace = sanitize.String(r.ToPlace)
+		r.ToCity = sanitize.String(r.ToCity)
+		r.Email = strings.TrimSpace(r.Email)
+	}
+	sanitizeSuratPayload(&req.Payload)
+
+	b, err := h.svc.CreateBatch(c.Request.Context(), &service.CreateSuratBatchInput{
+		OrgID:      orgID,
+		Payload:    req.Payload,
+		Theme:      req.Theme,
+		TemplateID: req.TemplateID,
+		Variables:  req.Variables,
+		ActivityID: activityID,
+		Recipients: req.Recipients,
+		EmailCc:    req.EmailCc,
+		CreatedBy:  userID,
+	}, h.minio, h.bucket)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusAccepted, response.OK(b))
+}
+
+// GetBatch menampilkan progress batch beserta surat yang sudah dibuat.
+func (h *SuratHandler) GetBatch(c *gin.Context) {
+	b, ok := h.managedBatch(c)
+	if !ok {
+		return
+	}
+	rows, err := h.svc.ListBatchSurat(c.Request.Context(), b.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"batch": b,
+			"items": rows,
+			"total": len(rows),
+		},
+	})
+}
+
+// SubmitBatch mengajukan semua draft dalam batch sekaligus.
+func (h *SuratHandler) SubmitBatch(c *gin.Context) {
+	b, ok := h.managedBatch(c)
+	if !ok {
+		return
+	}
+	userID, _ := h.currentUser(c)
+	res, err := h.svc.SubmitBatch(c.Request.Context(), userID, b.ID, h.minio, h.bucket)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(res))
+}
+
+// DownloadBatch mengunduh ZIP berisi PDF semua surat dalam batch.
+func (h *SuratHandler) DownloadBatch(c *gin.Context) {
+	b, ok := h.managedBatch(c)
+	if !ok {
+		return
+	}
+	data, filename, err := h.svc.BatchArchive(c.Request.Context(), b.ID, h.minio, h.bucket)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "application/zip", data)
+}
+
+// managedBatch memuat batch dari param :bid dan memastikan user mengelola organisasi pengirimnya.
+func (h *SuratHandler) managedBatch(c *gin.Context) (*model.SuratBatch, bool) {
+	bid, _ := strconv.Atoi(c.Param("bid"))
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return nil, false
+	}
+	b, err := h.svc.GetBatch(c.Request.Context(), uint(bid))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err(err.Error()))
+		return nil, false
+	}
+	if h.rbac != nil {
+		ok, _ := h.rbac.CanManageOrg(c.Request.Context(), userID, &model.Organization{ID: b.OrgID})
+		if !ok {
+			c.JSON(http.StatusForbidden, response.Err("forbidden"))
+			return nil, false
+		}
+	}
+	return b, true
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"simawa-backend/pkg/response"
+)
+
+type suratEmailsRequest struct {
+	EmailTo []string `json:"email_to"`
+	EmailCc []string `json:"email_cc"`
+}
+
+// SetEmails mengganti alamat email penerima dan tembusan surat.
+func (h *SuratHandler) SetEmails(c *gin.Context) {
+	row, ok := h.managedSurat(c)
+	if !ok {
+		return
+	}
+	var req suratEmailsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	userID, _ := h.currentUser(c)
+	res, err := h.svc.SetEmailRecipients(c.Request.Context(), userID, row.ID, req.EmailTo, req.EmailCc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(res))
+}
+
+// ListDeliveries menampilkan log pengiriman email surat (setiap percobaan).
+func (h *SuratHandler) ListDeliveries(c *gin.Context) {
+	row, ok := h.accessibleSurat(c)
+	if !ok {
+		return
+	}
+	rows, err := h.svc.ListDeliveries(c.Request.Context(), row.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"items": rows,
+			"total": len(rows),
+		},
+	})
+}
+
+// RetryDelivery mengirim ulang email ke alamat yang belum berhasil menerima surat.
+func (h *SuratHandler) RetryDelivery(c *gin.Context) {
+	row, ok := h.managedSurat(c)
+	if !ok {
+		return
+	}
+	userID, _ := h.currentUser(c)
+	n, err := h.svc.RetryDelivery(c.Request.Context(), userID, row.ID, h.minio, h.bucket)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusAccepted, response.OK(gin.H{"queued": n}))
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/service"
+	"simawa-backend/internal/util/sanitize"
+	"simawa-backend/pkg/response"
+)
+
+type disposeSuratRequest struct {
+	OrgID       string   `json:"org_id"`
+	ParentID    *uint    `json:"parent_id"`
+	Assignees   []string `json:"assignees" binding:"required,min=1"`
+	Instruction string   `json:"instruction" binding:"required"`
+	Note        string   `json:"note"`
+	DueDate     string   `json:"due_date"`
+}
+
+type completeDispositionRequest struct {
+	Note string `json:"note"`
+}
+
+// Dispose meneruskan surat masuk ke anggota organisasi penerima. Tanpa parent_id
+// hanya admin organisasi tujuan surat yang boleh; dengan parent_id, penerima
+// disposisi induk meneruskannya lagi (dicek di service).
+func (h *SuratHandler) Dispose(c *gin.Context) {
+	idNum, _ := strconv.Atoi(c.Param("id"))
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	var req disposeSuratRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	row, err := h.svc.Get(c.Request.Context(), uint(idNum))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err(err.Error()))
+		return
+	}
+
+	in := &service.DisposeSuratInput{
+		SuratID:     row.ID,
+		UserID:      userID,
+		ParentID:    req.ParentID,
+		Instruction: req.Instruction,
+		Note:        sanitize.String(req.Note),
+	}
+	for _, v := range req.Assignees {
+		uid, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("invalid assignee id"))
+			return
+		}
+		in.Assignees = append(in.Assignees, uid)
+	}
+	if req.DueDate != "" {
+		due, err := time.Parse("2006-01-02", req.DueDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("invalid due_date format, use YYYY-MM-DD"))
+			return
+		}
+		in.DueDate = &due
+	}
+
+	if req.ParentID == nil {
+		switch {
+		case req.OrgID != "":
+			orgID, err := uuid.Parse(req.OrgID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, response.Err("invalid org_id"))
+				return
+			}
+			in.OrgID = orgID
+		case row.TargetOrgID != nil:
+			in.OrgID = *row.TargetOrgID
+		default:
+			c.JSON(http.StatusBadRequest, response.Err("org_id required"))
+			return
+		}
+		// Surat harus memang masuk ke organisasi tersebut: ditujukan langsung,
+		// atau ditujukan ke role yang dipegang user di organisasi itu.
+		addressed := row.TargetOrgID != nil && *row.TargetOrgID == in.OrgID
+		if !addressed && row.TargetOrgID == nil && row.ToRole != "" {
+			addressed = h.holdsRoleInOrg(c, userID, row.ToRole, in.OrgID)
+		}
+		if !addressed {
+			c.JSON(http.StatusBadRequest, response.Err("surat tidak ditujukan ke organisasi ini"))
+			return
+		}
+		if h.rbac != nil {
+			ok, _ := h.rbac.CanManageOrg(c.Request.Context(), userID, &model.Organization{ID: in.OrgID})
+			if !ok {
+				c.JSON(http.StatusForbidden, response.Err("forbidden"))
+				return
+			}
+		}
+	}
+
+	rows, err := h.svc.Dispose(c.Request.Context(), in)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(rows))
+}
+
+// holdsRoleInOrg melaporkan apakah user memegang role di organisasi orgID.
+func (h *SuratHandler) holdsRoleInOrg(c *gin.Context, userID uuid.UUID, role string, orgID uuid.UUID) bool {
+	if h.rbac == nil {
+		return true
+	}
+	assignments, err := h.rbac.ListAssignments(c.Request.Context(), userID)
+	if err != nil {
+		return false
+	}
+	for _, a := range assignments {
+		if a.OrgID != nil && *a.OrgID == orgID && strings.EqualFold(a.RoleCode, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListDispositions menampilkan rantai disposisi sebuah surat.
+func (h *SuratHandler) ListDispositions(c *gin.Context) {
+	row, ok := h.accessibleSurat(c)
+	if !ok {
+		return
+	}
+	rows, err := h.svc.ListDispositions(c.Request.Context(), row.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"items": rows,
+			"total": len(rows),
+		},
+	})
+}
+
+// ListMyDispositions menampilkan disposisi yang ditujukan ke user beserta tautan PDF suratnya.
+func (h *SuratHandler) ListMyDispositions(c *gin.Context) {
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
+	rows, total, err := h.svc.ListMyDispositions(c.Request.Context(), userID, c.Query("status"), page, size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	type item struct {
+		model.SuratDisposition
+		URL string `json:"url"`
+	}
+	items := make([]item, 0, len(rows))
+	for _, d := range rows {
+		var url string
+		if d.Surat != nil {
+			url, _ = h.svc.PresignOrURL(c.Request.Context(), h.minio, h.bucket, d.Surat.FileKey, 15*time.Minute)
+		}
+		items = append(items, item{SuratDisposition: d, URL: url})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"items": items,
+			"total": total,
+		},
+	})
+}
+
+// CompleteDisposition dipanggil penerima disposisi setelah instruksinya dikerjakan.
+func (h *SuratHandler) CompleteDisposition(c *gin.Context) {
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	var req completeDispositionRequest
+	_ = c.ShouldBindJSON(&req)
+	did, _ := strconv.Atoi(c.Param("did"))
+	row, err := h.svc.CompleteDisposition(c.Request.Context(), userID, uint(did), sanitize.String(req.Note))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(row))
+}
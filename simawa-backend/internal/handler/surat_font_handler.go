@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"simawa-backend/internal/service"
+	"simawa-backend/pkg/response"
+)
+
+// ListFonts menampilkan font TTF unggahan organisasi.
+func (h *SuratHandler) ListFonts(c *gin.Context) {
+	_, orgID, ok := h.managedOrgParam(c)
+	if !ok {
+		return
+	}
+	rows, err := h.svc.ListFonts(c.Request.Context(), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"items": rows, "total": len(rows)}})
+}
+
+// UploadFont mengunggah/mengganti satu gaya font organisasi.
+// Multipart: file (.ttf), family, style (regular|B|I|BI).
+func (h *SuratHandler) UploadFont(c *gin.Context) {
+	userID, orgID, ok := h.managedOrgParam(c)
+	if !ok {
+		return
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("file is required"))
+		return
+	}
+	if fh.Size > service.MaxFontSize {
+		c.JSON(http.StatusBadRequest, response.Err("file too large (max 5MB)"))
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	m, err := h.svc.SaveFont(c.Request.Context(), &service.SuratFontUpload{
+		OrgID:      orgID,
+		Family:     c.PostForm("family"),
+		Style:      c.PostForm("style"),
+		Data:       data,
+		UploadedBy: userID,
+	}, h.minio, h.bucket)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(m))
+}
+
+func (h *SuratHandler) DeleteFont(c *gin.Context) {
+	userID, orgID, ok := h.managedOrgParam(c)
+	if !ok {
+		return
+	}
+	fontID, err := strconv.Atoi(c.Param("font_id"))
+	if err != nil || fontID <= 0 {
+		c.JSON(http.StatusBadRequest, response.Err("invalid font_id"))
+		return
+	}
+	if err := h.svc.DeleteFont(c.Request.Context(), userID, orgID, uint(fontID), h.minio, h.bucket); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK("deleted"))
+}
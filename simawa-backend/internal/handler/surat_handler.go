@@ -2,6 +2,7 @@ package handler
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"simawa-backend/internal/repository"
 	"simawa-backend/internal/service"
 	"simawa-backend/internal/util/sanitize"
+	"simawa-backend/internal/util/suratdocx"
 	"simawa-backend/internal/util/suratpdf"
 	"simawa-backend/pkg/response"
 )
@@ -31,11 +33,19 @@ func NewSuratHandler(svc service.SuratService, minio *minio.Client, bucket strin
 }
 
 type createSuratRequest struct {
-	OrgID       string           `json:"org_id" binding:"required,uuid"`
-	TargetOrgID string           `json:"target_org_id"`
-	Status      string           `json:"status"`
-	Payload     suratpdf.Payload `json:"payload" binding:"required"`
-	Theme       *suratpdf.Theme  `json:"theme"`
+	OrgID       string            `json:"org_id" binding:"required,uuid"`
+	TargetOrgID string            `json:"target_org_id"`
+	Status      string            `json:"status"`
+	Payload     suratpdf.Payload  `json:"payload" binding:"required"`
+	Theme       *suratpdf.Theme   `json:"theme"`
+	TemplateID  *uint             `json:"template_id"`
+	Variables   map[string]string `json:"variables"`
+	ActivityID  string            `json:"activity_id"`
+	EmailTo     []string          `json:"email_to"`
+	EmailCc     []string          `json:"email_cc"`
+
+	// TembusanOrgIDs organisasi yang menerima tembusan (lihat inbox ?section=cc).
+	TembusanOrgIDs []string `json:"tembusan_org_ids"`
 }
 
 type approveSuratRequest struct {
@@ -47,6 +57,29 @@ type reviseSuratRequest struct {
 	Note string `json:"note" binding:"required"`
 }
 
+type resubmitSuratRequest struct {
+	Payload *suratpdf.Payload `json:"payload"`
+	Theme   *suratpdf.Theme   `json:"theme"`
+	Reason  string            `json:"reason"`
+}
+
+// sanitizeSuratPayload membersihkan field teks payload yang dikirim user.
+func sanitizeSuratPayload(p *suratpdf.Payload) {
+	p.Meta.Subject = sanitize.String(p.Meta.Subject)
+	p.Meta.ToName = sanitize.String(p.Meta.ToName)
+	p.Meta.ToRole = sanitize.String(p.Meta.ToRole)
+	p.Meta.ToPlace = sanitize.String(p.Meta.ToPlace)
+	p.Meta.ToCity = sanitize.String(p.Meta.ToCity)
+
+	if p.Header != nil {
+		for i, t := range p.Header.Title {
+			p.Header.Title[i] = sanitize.String(t)
+		}
+		p.Header.OrgName = sanitize.String(p.Header.OrgName)
+		p.Header.OrgAddress = sanitize.String(p.Header.OrgAddress)
+	}
+}
+
 // Upload handles direct PDF upload without generating from template
 func (h *SuratHandler) Upload(c *gin.Context) {
 	userID, err := h.currentUser(c)
@@ -82,7 +115,6 @@ func (h *SuratHandler) Upload(c *gin.Context) {
 		targetOrgID = &tid
 	}
 
-	number := sanitize.String(c.PostForm("number"))
 	toRole := sanitize.String(c.PostForm("to_role"))
 	toName := sanitize.String(c.PostForm("to_name"))
 	variant := sanitize.String(c.PostForm("variant"))
@@ -113,7 +145,6 @@ func (h *SuratHandler) Upload(c *gin.Context) {
 		OrgID:       orgID,
 		TargetOrgID: targetOrgID,
 		Subject:     subject,
-		Number:      number,
 		ToRole:      toRole,
 		ToName:      toName,
 		Variant:     variant,
@@ -155,29 +186,39 @@ func (h *SuratHandler) Create(c *gin.Context) {
 		}
 		targetOrg = &tid
 	}
-
-	// Sanitize payload fields
-	req.Payload.Meta.Subject = sanitize.String(req.Payload.Meta.Subject)
-	req.Payload.Meta.ToName = sanitize.String(req.Payload.Meta.ToName)
-	req.Payload.Meta.ToRole = sanitize.String(req.Payload.Meta.ToRole)
-	req.Payload.Meta.ToPlace = sanitize.String(req.Payload.Meta.ToPlace)
-	req.Payload.Meta.ToCity = sanitize.String(req.Payload.Meta.ToCity)
-	
-	if req.Payload.Header != nil {
-		for i, t := range req.Payload.Header.Title {
-			req.Payload.Header.Title[i] = sanitize.String(t)
+	var activityID *uuid.UUID
+	if strings.TrimSpace(req.ActivityID) != "" {
+		aid, err := uuid.Parse(req.ActivityID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("invalid activity_id"))
+			return
 		}
-		req.Payload.Header.OrgName = sanitize.String(req.Payload.Header.OrgName)
-		req.Payload.Header.OrgAddress = sanitize.String(req.Payload.Header.OrgAddress)
+		activityID = &aid
+	}
+	tembusan, err := parseOrgIDs(req.TembusanOrgIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("invalid tembusan_org_ids"))
+		return
 	}
+	for k, v := range req.Variables {
+		req.Variables[k] = sanitize.String(v)
+	}
+
+	sanitizeSuratPayload(&req.Payload)
 
 	row, err := h.svc.Create(c.Request.Context(), &service.CreateSuratInput{
-		OrgID:       orgID,
-		TargetOrgID: targetOrg,
-		Payload:     req.Payload,
-		Theme:       req.Theme,
-		CreatedBy:   userID,
-		Status:      req.Status,
+		OrgID:          orgID,
+		TargetOrgID:    targetOrg,
+		Payload:        req.Payload,
+		Theme:          req.Theme,
+		CreatedBy:      userID,
+		Status:         req.Status,
+		TemplateID:     req.TemplateID,
+		Variables:      req.Variables,
+		ActivityID:     activityID,
+		EmailTo:        req.EmailTo,
+		EmailCc:        req.EmailCc,
+		TembusanOrgIDs: tembusan,
 	}, h.minio, h.bucket)
 	if err != nil {
 		fmt.Printf("❌ Surat Create Error: %v\n", err)
@@ -199,7 +240,7 @@ func (h *SuratHandler) Submit(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
 		return
 	}
-	row, err := h.svc.Submit(c.Request.Context(), userID, uint(idNum))
+	row, err := h.svc.Submit(c.Request.Context(), userID, uint(idNum), h.minio, h.bucket)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
 		return
@@ -245,7 +286,7 @@ func (h *SuratHandler) Approve(c *gin.Context) {
 	// Sanitize note
 	req.Note = sanitize.String(req.Note)
 
-	res, err := h.svc.Decide(c.Request.Context(), userID, uint(idNum), req.Approve, req.Note)
+	res, err := h.svc.Decide(c.Request.Context(), userID, uint(idNum), req.Approve, req.Note, h.minio, h.bucket)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
 		return
@@ -265,10 +306,20 @@ func (h *SuratHandler) Get(c *gin.Context) {
 		c.JSON(http.StatusNotFound, response.Err(err.Error()))
 		return
 	}
-	if ok := h.canAccessSurat(c, userID, row); !ok {
+	if ok := h.canViewSurat(c, userID, row); !ok {
 		c.JSON(http.StatusForbidden, response.Err("forbidden"))
 		return
 	}
+	if row.Status != model.SuratStatusDraft {
+		row.Dispositions, _ = h.svc.ListDispositions(c.Request.Context(), row.ID)
+	}
+	if row.Source == model.SuratSourceExternal {
+		row.Agenda, _ = h.svc.GetAgenda(c.Request.Context(), row.ID)
+	}
+	row.Tembusan, _ = h.svc.ListTembusan(c.Request.Context(), row.ID)
+	if st, err := h.svc.SigningStatus(c.Request.Context(), row.ID); err == nil && st.Total > 0 {
+		row.Signatures = st.Steps
+	}
 	c.JSON(http.StatusOK, response.OK(row))
 }
 
@@ -284,10 +335,20 @@ func (h *SuratHandler) Download(c *gin.Context) {
 		c.JSON(http.StatusNotFound, response.Err(err.Error()))
 		return
 	}
-	if ok := h.canAccessSurat(c, userID, row); !ok {
+	if ok := h.canViewSurat(c, userID, row); !ok {
 		c.JSON(http.StatusForbidden, response.Err("forbidden"))
 		return
 	}
+	if c.Query("format") == "docx" {
+		data, filename, err := h.svc.ExportDOCX(c.Request.Context(), row.ID, h.minio, h.bucket)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename="+filename)
+		c.Data(http.StatusOK, suratdocx.ContentType, data)
+		return
+	}
 	url, err := h.svc.PresignOrURL(c.Request.Context(), h.minio, h.bucket, row.FileKey, 15*time.Minute)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
@@ -364,6 +425,7 @@ func (h *SuratHandler) ListOutbox(c *gin.Context) {
 	})
 }
 
+// ListInbox menampilkan surat masuk; ?section=cc berisi surat yang ditembuskan ke organisasi user.
 func (h *SuratHandler) ListInbox(c *gin.Context) {
 	userID, assignments, err := h.userAssignments(c)
 	if err != nil {
@@ -371,6 +433,7 @@ func (h *SuratHandler) ListInbox(c *gin.Context) {
 		return
 	}
 	status := c.Query("status")
+	section := strings.ToLower(c.DefaultQuery("section", "inbox"))
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
 
@@ -387,6 +450,7 @@ func (h *SuratHandler) ListInbox(c *gin.Context) {
 		OrgIDs: orgIDs,
 		Roles:  roleCodes,
 		Status: status,
+		CC:     section == "cc",
 		Page:   page,
 		Size:   size,
 	})
@@ -407,8 +471,9 @@ func (h *SuratHandler) ListInbox(c *gin.Context) {
 	_ = userID // silence unused if future use
 	c.JSON(http.StatusOK, gin.H{
 		"data": gin.H{
-			"items": items,
-			"total": total,
+			"items":   items,
+			"total":   total,
+			"section": section,
 		},
 	})
 }
@@ -420,7 +485,18 @@ func (h *SuratHandler) Generate(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
 		return
 	}
-	pdfBytes, err := h.svc.Generate(c.Request.Context(), req.Payload, req.Theme)
+	orgID, _ := uuid.Parse(req.OrgID)
+	if c.Query("format") == "docx" {
+		data, err := h.svc.GenerateDOCX(c.Request.Context(), orgID, req.Payload, req.Theme, h.minio, h.bucket)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename=surat-preview.docx")
+		c.Data(http.StatusOK, suratdocx.ContentType, data)
+		return
+	}
+	pdfBytes, err := h.svc.Generate(c.Request.Context(), orgID, req.Payload, req.Theme, h.minio, h.bucket)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
 		return
@@ -476,6 +552,74 @@ func (h *SuratHandler) Revise(c *gin.Context) {
 	c.JSON(http.StatusOK, response.OK(res))
 }
 
+// Resubmit mengirim ulang surat REVISION. Terima JSON {payload, theme, reason} untuk
+// surat yang dirender ulang, atau multipart (file, reason) untuk PDF pengganti.
+func (h *SuratHandler) Resubmit(c *gin.Context) {
+	idNum, _ := strconv.Atoi(c.Param("id"))
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	row, err := h.svc.Get(c.Request.Context(), uint(idNum))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err(err.Error()))
+		return
+	}
+	// hanya pengelola organisasi pengirim yang boleh memperbaiki surat
+	if h.rbac != nil {
+		ok, _ := h.rbac.CanManageOrg(c.Request.Context(), userID, &model.Organization{ID: row.OrgID})
+		if !ok {
+			c.JSON(http.StatusForbidden, response.Err("forbidden"))
+			return
+		}
+	}
+
+	in := &service.ResubmitSuratInput{ID: row.ID, UserID: userID}
+	if c.ContentType() == "multipart/form-data" {
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("file is required"))
+			return
+		}
+		defer file.Close()
+		if !strings.HasSuffix(strings.ToLower(header.Filename), ".pdf") {
+			c.JSON(http.StatusBadRequest, response.Err("only PDF files are allowed"))
+			return
+		}
+		if header.Size > 10*1024*1024 {
+			c.JSON(http.StatusBadRequest, response.Err("file size exceeds 10MB limit"))
+			return
+		}
+		in.File = file
+		in.FileName = header.Filename
+		in.Reason = sanitize.String(c.PostForm("reason"))
+	} else {
+		var req resubmitSuratRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+			return
+		}
+		if req.Payload != nil {
+			sanitizeSuratPayload(req.Payload)
+		}
+		in.Payload = req.Payload
+		in.Theme = req.Theme
+		in.Reason = sanitize.String(req.Reason)
+	}
+
+	res, err := h.svc.Resubmit(c.Request.Context(), in, h.minio, h.bucket)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	url, _ := h.svc.PresignOrURL(c.Request.Context(), h.minio, h.bucket, res.FileKey, 15*time.Minute)
+	if url != "" {
+		res.FileURL = url
+	}
+	c.JSON(http.StatusOK, response.OK(res))
+}
+
 // ListArchive returns archived surat for BEM/DEMA/ORG
 func (h *SuratHandler) ListArchive(c *gin.Context) {
 	userID, assignments, err := h.userAssignments(c)
@@ -517,6 +661,134 @@ func (h *SuratHandler) ListArchive(c *gin.Context) {
 	})
 }
 
+// Verify adalah endpoint publik untuk memeriksa keaslian surat dari kode/QR verifikasi.
+func (h *SuratHandler) Verify(c *gin.Context) {
+	res, err := h.svc.Verify(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err("surat tidak ditemukan atau kode verifikasi tidak valid"))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(res))
+}
+
+// ValidateSignature mencocokkan PDF yang diunggah dengan tanda tangan digital surat.
+func (h *SuratHandler) ValidateSignature(c *gin.Context) {
+	idNum, _ := strconv.Atoi(c.Param("id"))
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	row, err := h.svc.Get(c.Request.Context(), uint(idNum))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err(err.Error()))
+		return
+	}
+	if ok := h.canViewSurat(c, userID, row); !ok {
+		c.JSON(http.StatusForbidden, response.Err("forbidden"))
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("file is required"))
+		return
+	}
+	defer file.Close()
+	if header.Size > 10*1024*1024 {
+		c.JSON(http.StatusBadRequest, response.Err("file size exceeds 10MB limit"))
+		return
+	}
+	content, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+
+	res, err := h.svc.ValidateSignature(c.Request.Context(), row.ID, content)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(res))
+}
+
+// SigningKey mengembalikan kunci publik penandatangan surat.
+func (h *SuratHandler) SigningKey(c *gin.Context) {
+	res, err := h.svc.SigningKey()
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(res))
+}
+
+// ListNumberRegister menampilkan buku register nomor surat milik organisasi.
+func (h *SuratHandler) ListNumberRegister(c *gin.Context) {
+	q, ok := h.numberRegisterQuery(c)
+	if !ok {
+		return
+	}
+	q.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	q.Size, _ = strconv.Atoi(c.DefaultQuery("size", "10"))
+	if q.Size <= 0 {
+		q.Size = 10
+	}
+	rows, total, err := h.svc.ListNumberRegister(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"items": rows,
+			"total": total,
+		},
+	})
+}
+
+// ExportNumberRegister mengunduh buku register nomor surat dalam format CSV.
+func (h *SuratHandler) ExportNumberRegister(c *gin.Context) {
+	q, ok := h.numberRegisterQuery(c)
+	if !ok {
+		return
+	}
+	data, filename, err := h.svc.ExportNumberRegister(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, "text/csv", data)
+}
+
+// numberRegisterQuery parses org_id/variant/year and checks the caller can manage the org.
+func (h *SuratHandler) numberRegisterQuery(c *gin.Context) (repository.ListSuratNumberQuery, bool) {
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("invalid org id"))
+		return repository.ListSuratNumberQuery{}, false
+	}
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return repository.ListSuratNumberQuery{}, false
+	}
+	if h.rbac != nil {
+		ok, _ := h.rbac.CanManageOrg(c.Request.Context(), userID, &model.Organization{ID: orgID})
+		if !ok {
+			c.JSON(http.StatusForbidden, response.Err("forbidden"))
+			return repository.ListSuratNumberQuery{}, false
+		}
+	}
+	year, _ := strconv.Atoi(c.Query("year"))
+	return repository.ListSuratNumberQuery{
+		OrgID:   orgID,
+		Variant: c.Query("variant"),
+		Year:    year,
+	}, true
+}
+
 func (h *SuratHandler) currentUser(c *gin.Context) (uuid.UUID, error) {
 	raw := c.GetString("sub")
 	if raw == "" {
@@ -567,5 +839,45 @@ func (h *SuratHandler) canAccessSurat(c *gin.Context, userID uuid.UUID, s *model
 	if _, ok := roleSet[strings.ToUpper(s.ToRole)]; ok && s.ToRole != "" {
 		return true
 	}
-	return false
+	// penanda tangan dan penerima disposisi boleh membaca surat yang melibatkannya
+	if h.svc.IsSigner(c.Request.Context(), s.ID, userID) {
+		return true
+	}
+	return h.svc.IsDispositionAssignee(c.Request.Context(), s.ID, userID)
+}
+
+// canViewSurat = canAccessSurat ditambah akses baca untuk organisasi tembusan
+// pada surat yang sudah disetujui (atau kemudian dibatalkan). Pakai hanya untuk endpoint read-only.
+func (h *SuratHandler) canViewSurat(c *gin.Context, userID uuid.UUID, s *model.Surat) bool {
+	if h.canAccessSurat(c, userID, s) {
+		return true
+	}
+	if s == nil || (s.Status != model.SuratStatusApproved && s.Status != model.SuratStatusVoided) {
+		return false
+	}
+	assignments, _ := h.rbac.ListAssignments(c.Request.Context(), userID)
+	orgIDs := make([]uuid.UUID, 0, len(assignments))
+	for _, a := range assignments {
+		if a.OrgID != nil {
+			orgIDs = append(orgIDs, *a.OrgID)
+		}
+	}
+	return h.svc.IsTembusanRecipient(c.Request.Context(), s.ID, orgIDs)
+}
+
+// parseOrgIDs mengubah daftar UUID string menjadi uuid.UUID (string kosong dilewati).
+func parseOrgIDs(raw []string) ([]uuid.UUID, error) {
+	out := make([]uuid.UUID, 0, len(raw))
+	for _, v := range raw {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, nil
 }
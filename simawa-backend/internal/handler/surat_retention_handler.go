@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"simawa-backend/internal/service"
+	"simawa-backend/pkg/response"
+)
+
+type suratRetentionRequest struct {
+	RetainMonths int    `json:"retain_months" binding:"required"`
+	Action       string `json:"action"`
+}
+
+func (h *SuratHandler) ListRetentionRules(c *gin.Context) {
+	rows, err := h.svc.ListRetentionRules(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(rows))
+}
+
+// SaveRetentionRule membuat atau mengganti aturan retensi untuk :variant.
+func (h *SuratHandler) SaveRetentionRule(c *gin.Context) {
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	var req suratRetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	m, err := h.svc.SaveRetentionRule(c.Request.Context(), userID, &service.SuratRetentionInput{
+		Variant:      c.Param("variant"),
+		RetainMonths: req.RetainMonths,
+		Action:       req.Action,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(m))
+}
+
+func (h *SuratHandler) DeleteRetentionRule(c *gin.Context) {
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	if err := h.svc.DeleteRetentionRule(c.Request.Context(), userID, c.Param("variant")); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK("deleted"))
+}
+
+// RunRetention menjalankan job retensi sekarang tanpa menunggu jadwal berikutnya.
+func (h *SuratHandler) RunRetention(c *gin.Context) {
+	res, err := h.svc.ApplyRetention(c.Request.Context(), h.minio, h.bucket)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(res))
+}
+
+// ExportArchive mengalirkan ZIP arsip surat organisasi (PDF + index.csv) untuk
+// serah terima kepengurusan. Query from/to berformat YYYY-MM-DD dan opsional.
+func (h *SuratHandler) ExportArchive(c *gin.Context) {
+	_, orgID, ok := h.managedOrgParam(c)
+	if !ok {
+		return
+	}
+	q := service.SuratArchiveExport{OrgID: orgID}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &q.From}, {"to", &q.To}} {
+		v := c.Query(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("invalid "+p.name+" (YYYY-MM-DD)"))
+			return
+		}
+		*p.dst = &t
+	}
+	if q.From != nil && q.To != nil && q.To.Before(*q.From) {
+		c.JSON(http.StatusBadRequest, response.Err("to must not be before from"))
+		return
+	}
+
+	filename := fmt.Sprintf("arsip_surat_%s_%s.zip", orgID.String()[:8], time.Now().Format("20060102"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(http.StatusOK)
+	if err := h.svc.ExportArchive(c.Request.Context(), q, c.Writer, h.minio, h.bucket); err != nil {
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "application/json; charset=utf-8")
+			c.Header("Content-Disposition", "")
+			c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+			return
+		}
+		fmt.Printf("[SURAT] ekspor arsip org %s terputus: %v\n", orgID, err)
+	}
+}
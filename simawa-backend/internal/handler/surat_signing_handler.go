@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"simawa-backend/internal/util/sanitize"
+	"simawa-backend/pkg/response"
+)
+
+type signSuratRequest struct {
+	Approve bool   `json:"approve"`
+	Note    string `json:"note"`
+}
+
+// Sign dipanggil penanda tangan yang sedang mendapat giliran. Izin dicek di service
+// (hanya user pada langkah berjalan yang boleh menandatangani).
+func (h *SuratHandler) Sign(c *gin.Context) {
+	idNum, _ := strconv.Atoi(c.Param("id"))
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	var req signSuratRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	req.Note = sanitize.String(req.Note)
+	if !req.Approve && req.Note == "" {
+		c.JSON(http.StatusBadRequest, response.Err("note wajib diisi saat menolak"))
+		return
+	}
+	row, err := h.svc.Sign(c.Request.Context(), userID, uint(idNum), req.Approve, req.Note, h.minio, h.bucket)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(row))
+}
+
+// SigningStatus menampilkan linimasa tanda tangan berurutan surat.
+func (h *SuratHandler) SigningStatus(c *gin.Context) {
+	row, ok := h.accessibleSurat(c)
+	if !ok {
+		return
+	}
+	res, err := h.svc.SigningStatus(c.Request.Context(), row.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(res))
+}
+
+// ListMySignatures menampilkan surat yang menunggu tanda tangan user yang login.
+func (h *SuratHandler) ListMySignatures(c *gin.Context) {
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	rows, err := h.svc.ListMySignatures(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"items": rows,
+			"total": len(rows),
+		},
+	})
+}
+
+// RemindSigner mengirim pengingat ke penanda tangan yang sedang mendapat giliran.
+func (h *SuratHandler) RemindSigner(c *gin.Context) {
+	row, ok := h.managedSurat(c)
+	if !ok {
+		return
+	}
+	userID, _ := h.currentUser(c)
+	res, err := h.svc.RemindSigner(c.Request.Context(), userID, row.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(res))
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/service"
+	"simawa-backend/pkg/response"
+)
+
+// GetSignatureSpecimen menampilkan spesimen TTD milik user yang login.
+func (h *SuratHandler) GetSignatureSpecimen(c *gin.Context) {
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	h.getSpecimen(c, model.SuratSpecimenSignature, userID)
+}
+
+// UploadSignatureSpecimen mengunggah/mengganti spesimen TTD milik user yang login.
+func (h *SuratHandler) UploadSignatureSpecimen(c *gin.Context) {
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	h.saveSpecimen(c, userID, model.SuratSpecimenSignature, userID)
+}
+
+func (h *SuratHandler) DeleteSignatureSpecimen(c *gin.Context) {
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	h.deleteSpecimen(c, userID, model.SuratSpecimenSignature, userID)
+}
+
+// GetStampSpecimen menampilkan cap resmi organisasi (hanya pengelola organisasi).
+func (h *SuratHandler) GetStampSpecimen(c *gin.Context) {
+	if _, orgID, ok := h.managedOrgParam(c); ok {
+		h.getSpecimen(c, model.SuratSpecimenStamp, orgID)
+	}
+}
+
+func (h *SuratHandler) UploadStampSpecimen(c *gin.Context) {
+	if userID, orgID, ok := h.managedOrgParam(c); ok {
+		h.saveSpecimen(c, userID, model.SuratSpecimenStamp, orgID)
+	}
+}
+
+func (h *SuratHandler) DeleteStampSpecimen(c *gin.Context) {
+	if userID, orgID, ok := h.managedOrgParam(c); ok {
+		h.deleteSpecimen(c, userID, model.SuratSpecimenStamp, orgID)
+	}
+}
+
+// managedOrgParam membaca :org_id dan memastikan user boleh mengelola organisasi tersebut.
+func (h *SuratHandler) managedOrgParam(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return uuid.Nil, uuid.Nil, false
+	}
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("invalid org_id"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	if h.rbac != nil {
+		ok, _ := h.rbac.CanManageOrg(c.Request.Context(), userID, &model.Organization{ID: orgID})
+		if !ok {
+			c.JSON(http.StatusForbidden, response.Err("forbidden"))
+			return uuid.Nil, uuid.Nil, false
+		}
+	}
+	return userID, orgID, true
+}
+
+// getSpecimen mengembalikan metadata spesimen dengan URL pratinjau berumur pendek.
+func (h *SuratHandler) getSpecimen(c *gin.Context, kind string, ownerID uuid.UUID) {
+	m, err := h.svc.GetSpecimen(c.Request.Context(), kind, ownerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err(err.Error()))
+		return
+	}
+	url, _ := h.svc.PresignOrURL(c.Request.Context(), h.minio, h.bucket, m.FileKey, 5*time.Minute)
+	c.JSON(http.StatusOK, response.OK(gin.H{"specimen": m, "url": url}))
+}
+
+func (h *SuratHandler) saveSpecimen(c *gin.Context, userID uuid.UUID, kind string, ownerID uuid.UUID) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("file is required"))
+		return
+	}
+	if fh.Size > service.MaxSpecimenSize {
+		c.JSON(http.StatusBadRequest, response.Err("file too large (max 2MB)"))
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	m, err := h.svc.SaveSpecimen(c.Request.Context(), &service.SuratSpecimenUpload{
+		Kind:       kind,
+		OwnerID:    ownerID,
+		Data:       data,
+		UploadedBy: userID,
+	}, h.minio, h.bucket)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(m))
+}
+
+func (h *SuratHandler) deleteSpecimen(c *gin.Context, userID uuid.UUID, kind string, ownerID uuid.UUID) {
+	if err := h.svc.DeleteSpecimen(c.Request.Context(), userID, kind, ownerID, h.minio, h.bucket); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK("deleted"))
+}
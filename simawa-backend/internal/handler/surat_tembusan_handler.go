@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"simawa-backend/pkg/response"
+)
+
+type suratTembusanRequest struct {
+	OrgIDs []string `json:"org_ids"`
+}
+
+// SetTembusan mengganti organisasi tembusan surat draft/revisi.
+func (h *SuratHandler) SetTembusan(c *gin.Context) {
+	row, ok := h.managedSurat(c)
+	if !ok {
+		return
+	}
+	var req suratTembusanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	orgIDs, err := parseOrgIDs(req.OrgIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("invalid org_ids"))
+		return
+	}
+	userID, _ := h.currentUser(c)
+	rows, err := h.svc.SetTembusan(c.Request.Context(), userID, row.ID, orgIDs, h.minio, h.bucket)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"items": rows,
+			"total": len(rows),
+		},
+	})
+}
+
+// ListTembusan menampilkan organisasi penerima tembusan surat.
+func (h *SuratHandler) ListTembusan(c *gin.Context) {
+	row, ok := h.accessibleSurat(c)
+	if !ok {
+		return
+	}
+	rows, err := h.svc.ListTembusan(c.Request.Context(), row.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"items": rows,
+			"total": len(rows),
+		},
+	})
+}
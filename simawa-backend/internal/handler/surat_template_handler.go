@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/service"
+	"simawa-backend/internal/util/sanitize"
+	"simawa-backend/internal/util/suratpdf"
+	"simawa-backend/pkg/response"
+)
+
+type suratTemplateRequest struct {
+	OrgID       string            `json:"org_id"` // kosong = template global
+	Variant     string            `json:"variant" binding:"required"`
+	Name        string            `json:"name" binding:"required"`
+	Description string            `json:"description"`
+	Subject     string            `json:"subject"`
+	BodyOpening string            `json:"body_opening"`
+	BodyContent []string          `json:"body_content"`
+	BodyClosing string            `json:"body_closing"`
+	Footer      string            `json:"footer"`
+	Signs       []suratpdf.Signer `json:"signs"`
+	Tembusan    []string          `json:"tembusan"`
+	Theme       *suratpdf.Theme   `json:"theme"`
+}
+
+func (r suratTemplateRequest) toInput(orgID *uuid.UUID) *service.SuratTemplateInput {
+	return &service.SuratTemplateInput{
+		OrgID:       orgID,
+		Variant:     r.Variant,
+		Name:        sanitize.String(r.Name),
+		Description: sanitize.String(r.Description),
+		Subject:     r.Subject,
+		BodyOpening: r.BodyOpening,
+		BodyContent: r.BodyContent,
+		BodyClosing: r.BodyClosing,
+		Footer:      r.Footer,
+		Signs:       r.Signs,
+		Tembusan:    r.Tembusan,
+		Theme:       r.Theme,
+	}
+}
+
+// ListTemplates menampilkan template global plus template milik org_id (query).
+func (h *SuratHandler) ListTemplates(c *gin.Context) {
+	var orgID *uuid.UUID
+	if raw := c.Query("org_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("invalid org_id"))
+			return
+		}
+		orgID = &id
+	}
+	rows, err := h.svc.ListTemplates(c.Request.Context(), orgID, c.Query("variant"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"items": rows,
+			"total": len(rows),
+		},
+	})
+}
+
+func (h *SuratHandler) GetTemplate(c *gin.Context) {
+	idNum, _ := strconv.Atoi(c.Param("tid"))
+	row, err := h.svc.GetTemplate(c.Request.Context(), uint(idNum))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(row))
+}
+
+func (h *SuratHandler) CreateTemplate(c *gin.Context) {
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	var req suratTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	var orgID *uuid.UUID
+	if req.OrgID != "" {
+		id, err := uuid.Parse(req.OrgID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("invalid org_id"))
+			return
+		}
+		orgID = &id
+	}
+	if !h.canManageTemplate(c, userID, orgID) {
+		c.JSON(http.StatusForbidden, response.Err("forbidden"))
+		return
+	}
+	row, err := h.svc.CreateTemplate(c.Request.Context(), userID, req.toInput(orgID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, response.OK(row))
+}
+
+// UpdateTemplate mengganti isi template; pemilik (org_id) template tidak bisa dipindah.
+func (h *SuratHandler) UpdateTemplate(c *gin.Context) {
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	idNum, _ := strconv.Atoi(c.Param("tid"))
+	existing, err := h.svc.GetTemplate(c.Request.Context(), uint(idNum))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err(err.Error()))
+		return
+	}
+	if !h.canManageTemplate(c, userID, existing.OrgID) {
+		c.JSON(http.StatusForbidden, response.Err("forbidden"))
+		return
+	}
+	var req suratTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	row, err := h.svc.UpdateTemplate(c.Request.Context(), userID, existing.ID, req.toInput(existing.OrgID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(row))
+}
+
+func (h *SuratHandler) DeleteTemplate(c *gin.Context) {
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return
+	}
+	idNum, _ := strconv.Atoi(c.Param("tid"))
+	existing, err := h.svc.GetTemplate(c.Request.Context(), uint(idNum))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err(err.Error()))
+		return
+	}
+	if !h.canManageTemplate(c, userID, existing.OrgID) {
+		c.JSON(http.StatusForbidden, response.Err("forbidden"))
+		return
+	}
+	if err := h.svc.DeleteTemplate(c.Request.Context(), userID, existing.ID); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK("deleted"))
+}
+
+// canManageTemplate: template global hanya untuk BEM/admin, template organisasi untuk pengelola org tersebut.
+func (h *SuratHandler) canManageTemplate(c *gin.Context, userID uuid.UUID, orgID *uuid.UUID) bool {
+	if h.rbac == nil {
+		return true
+	}
+	if orgID == nil {
+		ok, err := h.rbac.CanApproveSurat(c.Request.Context(), userID)
+		return err == nil && ok
+	}
+	ok, err := h.rbac.CanManageOrg(c.Request.Context(), userID, &model.Organization{ID: *orgID})
+	return err == nil && ok
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/pkg/response"
+)
+
+// ListVersions menampilkan riwayat PDF surat, versi terbaru lebih dulu.
+func (h *SuratHandler) ListVersions(c *gin.Context) {
+	row, ok := h.accessibleSurat(c)
+	if !ok {
+		return
+	}
+	rows, err := h.svc.ListVersions(c.Request.Context(), row.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"items": rows,
+			"total": len(rows),
+		},
+	})
+}
+
+// DownloadVersion mengembalikan URL unduhan PDF untuk versi tertentu.
+func (h *SuratHandler) DownloadVersion(c *gin.Context) {
+	row, ok := h.accessibleSurat(c)
+	if !ok {
+		return
+	}
+	version, _ := strconv.Atoi(c.Param("version"))
+	v, err := h.svc.GetVersion(c.Request.Context(), row.ID, version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err("versi tidak ditemukan"))
+		return
+	}
+	url, err := h.svc.PresignOrURL(c.Request.Context(), h.minio, h.bucket, v.FileKey, 15*time.Minute)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"url": url, "file_key": v.FileKey, "version": v.Version})
+}
+
+// DiffVersions membandingkan payload dua versi: ?from=1&to=2.
+func (h *SuratHandler) DiffVersions(c *gin.Context) {
+	row, ok := h.accessibleSurat(c)
+	if !ok {
+		return
+	}
+	from, _ := strconv.Atoi(c.Query("from"))
+	to, _ := strconv.Atoi(c.Query("to"))
+	res, err := h.svc.DiffVersions(c.Request.Context(), row.ID, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(res))
+}
+
+// accessibleSurat memuat surat dari param :id dan memastikan user boleh melihatnya
+// (termasuk organisasi tembusan, karena hanya dipakai endpoint read-only).
+func (h *SuratHandler) accessibleSurat(c *gin.Context) (*model.Surat, bool) {
+	idNum, _ := strconv.Atoi(c.Param("id"))
+	userID, err := h.currentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Err(err.Error()))
+		return nil, false
+	}
+	row, err := h.svc.Get(c.Request.Context(), uint(idNum))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err(err.Error()))
+		return nil, false
+	}
+	if ok := h.canViewSurat(c, userID, row); !ok {
+		c.JSON(http.StatusForbidden, response.Err("forbidden"))
+		return nil, false
+	}
+	return row, true
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"simawa-backend/internal/util/sanitize"
+	"simawa-backend/pkg/response"
+)
+
+type voidSuratRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// Withdraw menarik kembali surat PENDING ke DRAFT (pengelola organisasi pembuat).
+func (h *SuratHandler) Withdraw(c *gin.Context) {
+	row, ok := h.managedSurat(c)
+	if !ok {
+		return
+	}
+	userID, _ := h.currentUser(c)
+	res, err := h.svc.Withdraw(c.Request.Context(), userID, row.ID, h.minio, h.bucket)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(res))
+}
+
+// Void membatalkan surat APPROVED dengan alasan (pengelola organisasi pembuat).
+func (h *SuratHandler) Void(c *gin.Context) {
+	row, ok := h.managedSurat(c)
+	if !ok {
+		return
+	}
+	var req voidSuratRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	userID, _ := h.currentUser(c)
+	res, err := h.svc.Void(c.Request.Context(), userID, row.ID, sanitize.String(req.Reason), h.minio, h.bucket)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(res))
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/service"
+	"simawa-backend/internal/util/sanitize"
+	"simawa-backend/pkg/response"
+)
+
+type VenueHandler struct {
+	svc *service.VenueService
+}
+
+func NewVenueHandler(svc *service.VenueService) *VenueHandler {
+	return &VenueHandler{svc: svc}
+}
+
+type venueRequest struct {
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Capacity    int      `json:"capacity"`
+	Facilities  []string `json:"facilities"`
+	OwnerUnit   string   `json:"owner_unit"`
+	Active      *bool    `json:"active"`
+}
+
+func (r *venueRequest) input() *service.VenueInput {
+	facilities := make([]string, 0, len(r.Facilities))
+	for _, f := range r.Facilities {
+		facilities = append(facilities, sanitize.String(f))
+	}
+	return &service.VenueInput{
+		Code:        sanitize.String(r.Code),
+		Name:        sanitize.String(r.Name),
+		Description: sanitize.String(r.Description),
+		Capacity:    r.Capacity,
+		Facilities:  facilities,
+		OwnerUnit:   sanitize.String(r.OwnerUnit),
+		Active:      r.Active,
+	}
+}
+
+func venueID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err("invalid id"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// List GET /v1/venues?all=true (default hanya ruangan aktif)
+func (h *VenueHandler) List(c *gin.Context) {
+	rows, err := h.svc.List(c.Request.Context(), c.Query("all") != "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(rows))
+}
+
+func (h *VenueHandler) Get(c *gin.Context) {
+	id, ok := venueID(c)
+	if !ok {
+		return
+	}
+	v, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.Err("ruangan tidak ditemukan"))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(v))
+}
+
+func (h *VenueHandler) Create(c *gin.Context) {
+	var req venueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	v, err := h.svc.Create(c.Request.Context(), userID, req.input())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, response.OK(v))
+}
+
+func (h *VenueHandler) Update(c *gin.Context) {
+	id, ok := venueID(c)
+	if !ok {
+		return
+	}
+	var req venueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	v, err := h.svc.Update(c.Request.Context(), userID, id, req.input())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(v))
+}
+
+func (h *VenueHandler) Delete(c *gin.Context) {
+	id, ok := venueID(c)
+	if !ok {
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("sub"))
+	if err := h.svc.Delete(c.Request.Context(), userID, id); err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(gin.H{"deleted": true}))
+}
+
+// Availability GET /v1/venues/:id/availability?from=&to=&pending=false
+// from/to RFC3339, default 30 hari ke depan. Slot PENDING ikut kecuali pending=false.
+func (h *VenueHandler) Availability(c *gin.Context) {
+	id, ok := venueID(c)
+	if !ok {
+		return
+	}
+	from, to := time.Now(), time.Time{}
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("invalid from"))
+			return
+		}
+		from = t
+	}
+	to = from.AddDate(0, 0, 30)
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Err("invalid to"))
+			return
+		}
+		to = t
+	}
+	venue, rows, err := h.svc.Availability(c.Request.Context(), id, from, to, c.Query("pending") != "false")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Err(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response.OK(gin.H{"venue": venue, "from": from, "to": to, "bookings": rows}))
+}
+
+// ICS returns the approved bookings of a venue as an ICS calendar. Non-public
+// activities only show as busy.
+func (h *VenueHandler) ICS(c *gin.Context) {
+	id, ok := venueID(c)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	venue, rows, err := h.svc.Availability(c.Request.Context(), id, now.Add(-30*24*time.Hour), now.AddDate(0, 0, 365), false)
+	if err != nil {
+		c.String(http.StatusNotFound, "")
+		return
+	}
+	c.Header("Content-Type", "text/calendar")
+	c.Writer.WriteString("BEGIN:VCALENDAR\nVERSION:2.0\nPRODID:-//SIMAWA//EN\n")
+	c.Writer.WriteString(fmt.Sprintf("X-WR-CALNAME:%s\n", venue.Name))
+	for _, b := range rows {
+		title := b.Title
+		if !b.Public {
+			title = "Terpesan"
+		}
+		c.Writer.WriteString("BEGIN:VEVENT\n")
+		c.Writer.WriteString(fmt.Sprintf("UID:%s-%d-venue%d@simawa\n", b.ActivityID, b.StartAt.Unix(), venue.ID))
+		c.Writer.WriteString(fmt.Sprintf("DTSTAMP:%s\n", now.UTC().Format("20060102T150405Z")))
+		c.Writer.WriteString(fmt.Sprintf("DTSTART:%s\n", b.StartAt.UTC().Format("20060102T150405Z")))
+		c.Writer.WriteString(fmt.Sprintf("DTEND:%s\n", b.EndAt.UTC().Format("20060102T150405Z")))
+		c.Writer.WriteString(fmt.Sprintf("SUMMARY:%s\n", title))
+		c.Writer.WriteString(fmt.Sprintf("LOCATION:%s\n", venue.Name))
+		c.Writer.WriteString("END:VEVENT\n")
+	}
+	c.Writer.WriteString("END:VCALENDAR\n")
+}
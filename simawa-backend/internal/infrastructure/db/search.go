@@ -0,0 +1,41 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// CreateSearchIndexes menambahkan kolom tsvector (generated, selalu sinkron dengan
+// barisnya) dan indeks GIN untuk pencarian teks penuh di /v1/search. Konfigurasi
+// 'simple' dipakai karena isi data campuran bahasa Indonesia dan Inggris.
+func CreateSearchIndexes(db *gorm.DB) error {
+	stmts := []string{
+		// Surat: perihal dan nomor berbobot A, penerima B
+		`ALTER TABLE surats ADD COLUMN IF NOT EXISTS search_tsv tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(subject, '') || ' ' || coalesce(number, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce(to_name, '') || ' ' || coalesce(to_role, '') || ' ' || coalesce(to_place, '') || ' ' || coalesce(to_city, '')), 'B')
+		) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_surats_search_tsv ON surats USING GIN (search_tsv)`,
+
+		// Kegiatan: judul A, deskripsi B
+		`ALTER TABLE activities ADD COLUMN IF NOT EXISTS search_tsv tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+		) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_activities_search_tsv ON activities USING GIN (search_tsv)`,
+
+		// LPJ: ringkasan
+		`ALTER TABLE lpjs ADD COLUMN IF NOT EXISTS search_tsv tsvector GENERATED ALWAYS AS (
+			to_tsvector('simple', coalesce(summary, ''))
+		) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_lpjs_search_tsv ON lpjs USING GIN (search_tsv)`,
+	}
+
+	for _, stmt := range stmts {
+		if err := db.Exec(stmt).Error; err != nil {
+			// Sama seperti CreateIndexes: jangan gagalkan startup
+			continue
+		}
+	}
+
+	return nil
+}
@@ -61,10 +61,49 @@ func ValidateFileType(allowedTypes map[string]bool) gin.HandlerFunc {
 			return
 		}
 
+		form, err := c.MultipartForm()
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid multipart form"})
+			return
+		}
+		for _, files := range form.File {
+			for _, fh := range files {
+				f, err := fh.Open()
+				if err != nil {
+					c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "cannot read uploaded file"})
+					return
+				}
+				header, err := ReadFileHeader(f, 512)
+				f.Close()
+				if err != nil || !matchesAllowedType(header, allowedTypes) {
+					c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+						"message": "file type not allowed",
+						"file":    SanitizeFilename(fh.Filename),
+					})
+					return
+				}
+			}
+		}
+
 		c.Next()
 	}
 }
 
+// matchesAllowedType reports whether header carries the magic bytes of one of the allowed types.
+func matchesAllowedType(header []byte, allowedTypes map[string]bool) bool {
+	for mimeType, ok := range allowedTypes {
+		if !ok || !ValidateUploadedFile(header, mimeType) {
+			continue
+		}
+		// RIFF is shared by WAV/AVI; WEBP is marked at offset 8
+		if mimeType == "image/webp" && (len(header) < 12 || string(header[8:12]) != "WEBP") {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
 // ValidateUploadedFile validates a single file's content against magic bytes
 func ValidateUploadedFile(fileBytes []byte, declaredType string) bool {
 	if len(fileBytes) < 8 {
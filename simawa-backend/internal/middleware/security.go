@@ -71,12 +71,14 @@ func SecurityMiddleware(redisClient *redis.Client) gin.HandlerFunc {
 	globalRL := newRateLimiter(300, time.Minute) // per IP
 	authRL := newRateLimiter(20, time.Minute)    // per IP for auth-like endpoints
 	uploadRL := newRateLimiter(30, time.Minute)  // per IP for upload endpoints
+	publicRL := newRateLimiter(10, time.Minute)  // per IP for public endpoints that send email
 
-	var rGlobal, rAuth, rUpload *redisRateLimiter
+	var rGlobal, rAuth, rUpload, rPublic *redisRateLimiter
 	if redisClient != nil {
 		rGlobal = newRedisRateLimiter(redisClient, 300, time.Minute)
 		rAuth = newRedisRateLimiter(redisClient, 20, time.Minute)
 		rUpload = newRedisRateLimiter(redisClient, 30, time.Minute)
+		rPublic = newRedisRateLimiter(redisClient, 10, time.Minute)
 	}
 
 	allow := func(ctx context.Context, rl *redisRateLimiter, mem *rateLimiter, key string) bool {
@@ -101,6 +103,11 @@ func SecurityMiddleware(redisClient *redis.Client) gin.HandlerFunc {
 				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"message": "rate limit exceeded"})
 				return
 			}
+		case path == "/public/activities/:id/register" || path == "/v1/activities/:id/register":
+			if !allow(c, rPublic, publicRL, key) {
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"message": "rate limit exceeded"})
+				return
+			}
 		default:
 			if !allow(c, rGlobal, globalRL, key) {
 				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"message": "rate limit exceeded"})
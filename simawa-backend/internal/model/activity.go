@@ -21,6 +21,8 @@ type Activity struct {
 	Title              string            `gorm:"size:200" json:"title"`
 	Description        string            `gorm:"type:text" json:"description"`
 	Location           string            `gorm:"size:255" json:"location"`
+	VenueID            *uint             `gorm:"index" json:"venue_id,omitempty"` // ruangan dari katalog venue; Location diisi nama ruangan
+	VenueConflict      bool              `json:"venue_conflict"` // bentrok jadwal ruangan saat diajukan (mode FLAG)
 	Type               string            `gorm:"size:50" json:"type"` // Rapat/Seminar/Lomba
 	CollabType         string            `gorm:"size:20;default:'INTERNAL'" json:"collab_type"` // INTERNAL, COLLAB, CAMPUS
 	CollaboratorOrgIDs datatypes.JSON    `gorm:"type:jsonb" json:"collaborator_org_ids"`
@@ -29,6 +31,13 @@ type Activity struct {
 	ApprovalNote       string            `gorm:"type:text" json:"approval_note"`
 	StartAt            time.Time         `json:"start_at"`
 	EndAt              time.Time         `json:"end_at"`
+	CompletedAt        *time.Time        `json:"completed_at,omitempty"`
+	LPJDueAt           *time.Time        `gorm:"index" json:"lpj_due_at,omitempty"` // batas kirim LPJ, dibuka saat kegiatan COMPLETED
+	RRule              string            `gorm:"type:text" json:"rrule,omitempty"`           // RFC 5545, mis. FREQ=WEEKLY;BYDAY=MO
+	ExDates            datatypes.JSON    `gorm:"type:jsonb" json:"exdates,omitempty"`        // awal kemunculan yang dibatalkan (EXDATE)
+	RecurUntil         *time.Time        `gorm:"index" json:"recur_until,omitempty"`         // akhir kemunculan terakhir; kosong bila seri tanpa akhir
+	SeriesID           *uuid.UUID        `gorm:"type:uuid;index" json:"series_id,omitempty"` // induk seri untuk pecahan seri dan kemunculan yang diubah
+	RecurrenceID       *time.Time        `json:"recurrence_id,omitempty"`                    // awal asli kemunculan (RECURRENCE-ID)
 	CoverKey           string            `gorm:"size:255" json:"cover_key"`
 	ProposalKey        string            `gorm:"size:255" json:"proposal_key"`
 	ProposalURL        string            `gorm:"size:512" json:"proposal_url"`
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AttendanceMethodSelf = "SELF" // peserta memindai QR kegiatan
+	AttendanceMethodScan = "SCAN" // penyelenggara memindai QR pribadi peserta
+)
+
+// ActivityAttendance adalah satu presensi peserta pada satu kemunculan kegiatan.
+// OccurrenceStart sama dengan StartAt untuk kegiatan tunggal.
+type ActivityAttendance struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	ActivityID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_attendance_once,priority:1" json:"activity_id"`
+	OccurrenceStart time.Time `gorm:"not null;uniqueIndex:idx_attendance_once,priority:2" json:"occurrence_start"`
+	UserID          uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_attendance_once,priority:3;index" json:"user_id"`
+	Method          string    `gorm:"size:10;not null" json:"method"`
+	RecordedBy      uuid.UUID `gorm:"type:uuid" json:"recorded_by"`
+	CheckedInAt     time.Time `gorm:"not null" json:"checked_in_at"`
+	CreatedAt       time.Time `json:"created_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
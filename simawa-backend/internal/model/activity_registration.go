@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+const (
+	// RegistrationStatusUnconfirmed: pendaftaran tanpa akun yang emailnya belum
+	// dikonfirmasi; belum memegang kursi dan tokennya belum bisa membatalkan.
+	RegistrationStatusUnconfirmed = "UNCONFIRMED"
+	RegistrationStatusRegistered  = "REGISTERED"
+	RegistrationStatusWaitlisted  = "WAITLISTED"
+	RegistrationStatusCancelled   = "CANCELLED"
+)
+
+// RegistrationQuestion adalah pertanyaan tambahan pada formulir pendaftaran.
+type RegistrationQuestion struct {
+	Key      string   `json:"key"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type"` // text | number | choice
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"` // untuk type choice
+}
+
+// ActivityRegistrationForm mengatur pendaftaran peserta satu kegiatan publik.
+// Quota 0 berarti tanpa batas; pendaftar di atas kuota masuk daftar tunggu.
+type ActivityRegistrationForm struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	ActivityID    uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"activity_id"`
+	Enabled       bool           `json:"enabled"`
+	Quota         int            `json:"quota"`
+	WaitlistLimit int            `json:"waitlist_limit"` // 0 = tanpa batas
+	OpensAt       *time.Time     `json:"opens_at,omitempty"`
+	ClosesAt      *time.Time     `json:"closes_at,omitempty"`
+	Questions     datatypes.JSON `gorm:"type:jsonb" json:"questions"` // []RegistrationQuestion
+	UpdatedBy     *uuid.UUID     `gorm:"type:uuid" json:"updated_by,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// ActivityRegistration adalah satu pendaftar kegiatan. Token dikirim lewat email
+// dan dipakai pendaftar untuk melihat atau membatalkan pendaftarannya.
+type ActivityRegistration struct {
+	ID          uint              `gorm:"primaryKey" json:"id"`
+	ActivityID  uuid.UUID         `gorm:"type:uuid;not null;index" json:"activity_id"`
+	UserID      *uuid.UUID        `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	Name        string            `gorm:"size:128;not null" json:"name"`
+	Email       string            `gorm:"size:128;not null;index" json:"email"`
+	Phone       string            `gorm:"size:32" json:"phone"`
+	Answers     datatypes.JSONMap `json:"answers"`
+	Status      string            `gorm:"size:20;not null;index" json:"status"`
+	Token       string            `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	PromotedAt  *time.Time        `json:"promoted_at,omitempty"` // naik dari daftar tunggu
+	CancelledAt *time.Time        `json:"cancelled_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
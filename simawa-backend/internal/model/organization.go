@@ -41,6 +41,26 @@ type Organization struct {
 	GalleryURLs     datatypes.JSON    `gorm:"type:jsonb" json:"gallery_urls"`
 	StructureJSON   datatypes.JSON    `gorm:"type:jsonb" json:"structure_json"`
 
+	// SuratNumberPattern override pattern nomor surat untuk org ini (kosong = pakai SURAT_NUMBER_PATTERN).
+	SuratNumberPattern string `gorm:"size:128" json:"surat_number_pattern"`
+
+	// Letterhead profil kop surat; diterapkan otomatis saat surat dibuat.
+	Letterhead OrgLetterhead `gorm:"embedded;embeddedPrefix:kop_" json:"letterhead"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// OrgLetterhead adalah profil kop surat organisasi. Logo disimpan sebagai object key
+// MinIO; kosong = pakai LogoKey organisasi. Warna dalam format "#RRGGBB".
+type OrgLetterhead struct {
+	LeftLogoKey  string `gorm:"size:255" json:"left_logo_key"`
+	RightLogoKey string `gorm:"size:255" json:"right_logo_key"`
+	Institution  string `gorm:"size:255" json:"institution"`
+	Unit         string `gorm:"size:255" json:"unit"`
+	Address      string `gorm:"size:512" json:"address"`
+	Phone        string `gorm:"size:64" json:"phone"`
+	LineStyle    string `gorm:"size:16" json:"line_style"`
+	TextColor    string `gorm:"size:7" json:"text_color"`
+	LineColor    string `gorm:"size:7" json:"line_color"`
+}
@@ -13,6 +13,13 @@ const (
 	SuratStatusApproved = "APPROVED"
 	SuratStatusRejected = "REJECTED"
 	SuratStatusRevision = "REVISION"
+	SuratStatusReceived = "RECEIVED" // surat masuk dari luar sistem yang sudah dicatat di buku agenda
+	SuratStatusVoided   = "VOIDED"   // surat APPROVED yang dibatalkan organisasi pembuatnya
+)
+
+const (
+	SuratSourceInternal = "INTERNAL"
+	SuratSourceExternal = "EXTERNAL"
 )
 
 const (
@@ -26,6 +33,8 @@ type Surat struct {
 	ID           uint           `gorm:"primaryKey" json:"id"`
 	OrgID        uuid.UUID      `gorm:"type:uuid;index" json:"org_id"`
 	TargetOrgID  *uuid.UUID     `gorm:"type:uuid;index" json:"target_org_id,omitempty"`
+	BatchID      *uint          `gorm:"index" json:"batch_id,omitempty"` // terisi untuk surat hasil mail-merge
+	Source       string         `gorm:"type:varchar(16);default:INTERNAL;index" json:"source"` // EXTERNAL: surat masuk hasil scan, OrgID = org penerima
 	Variant      string         `gorm:"type:varchar(32);index" json:"variant"`
 	Status       string         `gorm:"type:varchar(20);index" json:"status"`
 	Number       string         `gorm:"type:varchar(128);index" json:"number"`
@@ -40,7 +49,24 @@ type Surat struct {
 	CreatedBy    *uuid.UUID     `gorm:"type:uuid;column:created_by" json:"created_by"`
 	SubmittedBy  *uuid.UUID     `gorm:"type:uuid;column:submitted_by" json:"submitted_by"`
 	ApprovedBy   *uuid.UUID     `gorm:"type:uuid;column:approved_by" json:"approved_by"`
+	ApprovedAt   *time.Time     `json:"approved_at,omitempty"`
+	VerifyCode   *string        `gorm:"type:varchar(32);uniqueIndex" json:"verify_code,omitempty"` // kode verifikasi publik, terisi saat APPROVED
+	ContentHash  string         `gorm:"type:varchar(64)" json:"content_hash,omitempty"` // sha256 PDF final yang ditandatangani
+	Signature    string         `gorm:"type:text" json:"signature,omitempty"`
+	SignKeyID    string         `gorm:"type:varchar(64)" json:"sign_key_id,omitempty"`
+	SignedAt     *time.Time     `json:"signed_at,omitempty"`
+	VoidReason   string         `gorm:"type:text" json:"void_reason,omitempty"`
+	VoidedBy     *uuid.UUID     `gorm:"type:uuid;column:voided_by" json:"voided_by,omitempty"`
+	VoidedAt     *time.Time     `json:"voided_at,omitempty"`
+	ExpiredAt    *time.Time     `gorm:"index" json:"expired_at,omitempty"` // ditandai job retensi saat masa simpan habis
+	ArchivedAt   *time.Time     `json:"archived_at,omitempty"`             // PDF sudah dipindah ke cold storage
+	EmailTo      datatypes.JSON `json:"email_to,omitempty"` // alamat email penerima di luar sistem
+	EmailCc      datatypes.JSON `json:"email_cc,omitempty"` // alamat email tembusan
 	MetaJSON     datatypes.JSON `json:"meta_json"`
+	Dispositions []SuratDisposition `gorm:"-" json:"dispositions,omitempty"` // rantai disposisi, diisi pada detail surat masuk
+	Agenda       *SuratAgenda       `gorm:"-" json:"agenda,omitempty"`       // data buku agenda untuk surat EXTERNAL
+	Tembusan     []SuratTembusan    `gorm:"-" json:"tembusan,omitempty"`     // organisasi penerima tembusan
+	Signatures   []SuratSignature   `gorm:"-" json:"signatures,omitempty"`   // linimasa tanda tangan berurutan
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 }
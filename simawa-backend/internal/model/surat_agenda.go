@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SuratAgendaCounterVariant adalah kunci counter nomor agenda di tabel
+// surat_number_counters (per organisasi penerima dan tahun).
+const SuratAgendaCounterVariant = "AGENDA"
+
+const (
+	SuratSifatBiasa   = "BIASA"
+	SuratSifatPenting = "PENTING"
+	SuratSifatSegera  = "SEGERA"
+	SuratSifatRahasia = "RAHASIA"
+)
+
+// SuratAgenda adalah baris buku agenda surat masuk: surat dari luar sistem
+// (rektorat, mitra) yang discan lalu dicatat oleh organisasi penerima.
+type SuratAgenda struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	OrgID          uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:ux_surat_agenda_seq" json:"org_id"`
+	Year           int        `gorm:"not null;uniqueIndex:ux_surat_agenda_seq" json:"year"`
+	Seq            int        `gorm:"not null;uniqueIndex:ux_surat_agenda_seq" json:"seq"`
+	AgendaNumber   string     `gorm:"type:varchar(128);index" json:"agenda_number"`
+	SuratID        uint       `gorm:"uniqueIndex" json:"surat_id"`
+	Sender         string     `gorm:"type:varchar(255)" json:"sender"`
+	SenderNumber   string     `gorm:"type:varchar(128)" json:"sender_number"` // nomor surat asal
+	LetterDate     *time.Time `json:"letter_date,omitempty"`
+	ReceivedAt     time.Time  `gorm:"index" json:"received_at"`
+	Subject        string     `gorm:"type:varchar(255)" json:"subject"`
+	Classification string     `gorm:"type:varchar(20)" json:"classification"`
+	Note           string     `gorm:"type:text" json:"note"`
+	RegisteredBy   *uuid.UUID `gorm:"type:uuid" json:"registered_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SuratAttachment adalah berkas lampiran surat (proposal, rundown, daftar peserta, ...)
+// yang disimpan di MinIO dan digabung ke PDF surat saat dirender.
+type SuratAttachment struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	SuratID     uint       `gorm:"index" json:"surat_id"`
+	FileKey     string     `gorm:"type:varchar(512)" json:"file_key"`
+	FileName    string     `gorm:"type:varchar(255)" json:"file_name"`
+	ContentType string     `gorm:"type:varchar(100)" json:"content_type"`
+	Size        int64      `json:"size"`
+	UploadedBy  *uuid.UUID `gorm:"type:uuid" json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
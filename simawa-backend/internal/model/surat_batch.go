@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+const (
+	SuratBatchStatusQueued  = "QUEUED"
+	SuratBatchStatusRunning = "RUNNING"
+	SuratBatchStatusDone    = "DONE"
+	SuratBatchStatusFailed  = "FAILED"
+)
+
+// SuratBatch mengelompokkan surat hasil mail-merge: satu payload dasar yang
+// dirender untuk banyak penerima. Progress diperbarui selama proses berjalan.
+type SuratBatch struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	OrgID      uuid.UUID      `gorm:"type:uuid;index" json:"org_id"`
+	Variant    string         `gorm:"type:varchar(32)" json:"variant"`
+	Subject    string         `gorm:"type:varchar(255)" json:"subject"`
+	Status     string         `gorm:"type:varchar(20);index" json:"status"`
+	Total      int            `json:"total"`
+	Processed  int            `json:"processed"`
+	Failed     int            `json:"failed"`
+	Errors     datatypes.JSON `json:"errors,omitempty"` // [{index, to_name, error}]
+	CreatedBy  *uuid.UUID     `gorm:"type:uuid" json:"created_by,omitempty"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+const (
+	SuratDeliveryTo       = "TO"
+	SuratDeliveryTembusan = "TEMBUSAN"
+)
+
+const (
+	SuratDeliverySent    = "SENT"
+	SuratDeliveryFailed  = "FAILED"
+	SuratDeliverySkipped = "SKIPPED" // SMTP belum dikonfigurasi
+)
+
+// SuratDelivery mencatat satu percobaan kirim email PDF surat yang disetujui.
+// Percobaan ulang ke alamat yang sama menambah baris baru dengan Attempt berikutnya.
+type SuratDelivery struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	SuratID   uint      `gorm:"index" json:"surat_id"`
+	Email     string    `gorm:"type:varchar(255);index" json:"email"`
+	Kind      string    `gorm:"type:varchar(16)" json:"kind"`
+	Attempt   int       `json:"attempt"`
+	Status    string    `gorm:"type:varchar(16);index" json:"status"`
+	Error     string    `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DisposisiTindakLanjuti = "TINDAK_LANJUTI"
+	DisposisiHadiri        = "HADIRI"
+	DisposisiArsipkan      = "ARSIPKAN"
+)
+
+const (
+	DisposisiStatusOpen = "OPEN"
+	DisposisiStatusDone = "DONE"
+)
+
+// SuratDisposition adalah disposisi surat masuk dari admin organisasi penerima
+// ke anggotanya. ParentID terisi bila disposisi diteruskan oleh penerima
+// disposisi sebelumnya, sehingga baris-barisnya membentuk rantai disposisi.
+type SuratDisposition struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	SuratID     uint       `gorm:"index" json:"surat_id"`
+	Surat       *Surat     `gorm:"foreignKey:SuratID" json:"surat,omitempty"`
+	ParentID    *uint      `gorm:"index" json:"parent_id,omitempty"`
+	OrgID       uuid.UUID  `gorm:"type:uuid;index" json:"org_id"`
+	FromUserID  uuid.UUID  `gorm:"type:uuid;index" json:"from_user_id"`
+	FromUser    *User      `gorm:"foreignKey:FromUserID;references:ID" json:"from_user,omitempty"`
+	ToUserID    uuid.UUID  `gorm:"type:uuid;index" json:"to_user_id"`
+	ToUser      *User      `gorm:"foreignKey:ToUserID;references:ID" json:"to_user,omitempty"`
+	Instruction string     `gorm:"type:varchar(32)" json:"instruction"`
+	Note        string     `gorm:"type:text" json:"note"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Status      string     `gorm:"type:varchar(20);index" json:"status"`
+	DoneAt      *time.Time `json:"done_at,omitempty"`
+	DoneNote    string     `gorm:"type:text" json:"done_note,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SuratFont adalah font TTF unggahan organisasi untuk satu gaya (regular/B/I/BI).
+// Dipakai suratpdf bila Theme.FontFamily surat organisasi tersebut sama dengan Family.
+type SuratFont struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	OrgID      uuid.UUID `gorm:"type:uuid;uniqueIndex:ux_surat_font" json:"org_id"`
+	Family     string    `gorm:"type:varchar(64);uniqueIndex:ux_surat_font" json:"family"`
+	Style      string    `gorm:"type:varchar(2);uniqueIndex:ux_surat_font" json:"style"` // "", "B", "I", "BI"
+	FileKey    string    `gorm:"type:text" json:"-"`
+	Size       int64     `json:"size"`
+	UploadedBy uuid.UUID `gorm:"type:uuid" json:"uploaded_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SuratNumberCounter menyimpan nomor urut terakhir per organisasi, varian, dan tahun.
+type SuratNumberCounter struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	OrgID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:ux_surat_number_counter" json:"org_id"`
+	Variant   string    `gorm:"type:varchar(32);not null;uniqueIndex:ux_surat_number_counter" json:"variant"`
+	Year      int       `gorm:"not null;uniqueIndex:ux_surat_number_counter" json:"year"`
+	LastSeq   int       `gorm:"not null;default:0" json:"last_seq"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SuratNumber adalah buku register nomor surat yang sudah diterbitkan.
+type SuratNumber struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	OrgID     uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:ux_surat_number_seq" json:"org_id"`
+	Variant   string     `gorm:"type:varchar(32);not null;uniqueIndex:ux_surat_number_seq" json:"variant"`
+	Year      int        `gorm:"not null;uniqueIndex:ux_surat_number_seq" json:"year"`
+	Seq       int        `gorm:"not null;uniqueIndex:ux_surat_number_seq" json:"seq"`
+	Number    string     `gorm:"type:varchar(128);index" json:"number"`
+	SuratID   uint       `gorm:"index" json:"surat_id"`
+	Subject   string     `gorm:"type:varchar(255)" json:"subject"`
+	IssuedBy  *uuid.UUID `gorm:"type:uuid" json:"issued_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
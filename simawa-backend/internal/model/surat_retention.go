@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SuratRetentionFlag    = "FLAG"    // surat kedaluwarsa hanya ditandai
+	SuratRetentionArchive = "ARCHIVE" // ditandai lalu PDF-nya dipindah ke prefix cold storage
+)
+
+// SuratRetentionRule menentukan berapa lama surat satu varian disimpan sejak
+// disetujui (atau dicatat) sebelum dianggap kedaluwarsa oleh job retensi.
+type SuratRetentionRule struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Variant      string     `gorm:"type:varchar(32);not null;uniqueIndex" json:"variant"`
+	RetainMonths int        `gorm:"not null" json:"retain_months"`
+	Action       string     `gorm:"type:varchar(16);not null;default:FLAG" json:"action"`
+	UpdatedBy    *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SuratSignPending  = "PENDING"
+	SuratSignSigned   = "SIGNED"
+	SuratSignRejected = "REJECTED"
+)
+
+// SuratSignature adalah satu langkah tanda tangan berurutan sesuai urutan
+// payload.Signs. Langkah yang sedang berjalan adalah PENDING dengan Step terkecil;
+// surat baru APPROVED setelah langkah terakhir SIGNED.
+type SuratSignature struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	SuratID    uint       `gorm:"index" json:"surat_id"`
+	Surat      *Surat     `gorm:"foreignKey:SuratID" json:"surat,omitempty"`
+	Step       int        `json:"step"`
+	UserID     uuid.UUID  `gorm:"type:uuid;index" json:"user_id"`
+	User       *User      `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
+	Name       string     `gorm:"type:varchar(255)" json:"name"`
+	Role       string     `gorm:"type:varchar(255)" json:"role"`
+	Status     string     `gorm:"type:varchar(16);index" json:"status"`
+	Note       string     `gorm:"type:text" json:"note,omitempty"`
+	SignedAt   *time.Time `json:"signed_at,omitempty"`
+	RemindedAt *time.Time `json:"reminded_at,omitempty"`
+	Reminders  int        `json:"reminders"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
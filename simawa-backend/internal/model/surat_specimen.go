@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SuratSpecimenSignature = "TTD"   // spesimen tanda tangan milik user
+	SuratSpecimenStamp     = "STAMP" // cap resmi milik organisasi
+)
+
+// SuratSpecimen adalah spesimen TTD user atau cap organisasi yang disimpan di
+// MinIO (prefix private/). FileKey tidak pernah dikirim ke klien; gambar hanya
+// dibubuhkan ke PDF oleh backend setelah penanda tangan menyetujui surat.
+type SuratSpecimen struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Kind        string     `gorm:"type:varchar(16);index" json:"kind"`
+	UserID      *uuid.UUID `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	OrgID       *uuid.UUID `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	FileKey     string     `gorm:"type:text" json:"-"`
+	ContentType string     `gorm:"type:varchar(64)" json:"content_type"`
+	Size        int64      `json:"size"`
+	UploadedBy  uuid.UUID  `gorm:"type:uuid" json:"uploaded_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
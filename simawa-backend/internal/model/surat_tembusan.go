@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SuratTembusan menghubungkan surat dengan organisasi yang menerima tembusan.
+// Organisasi tembusan dapat membaca surat yang sudah disetujui (tanpa disposisi).
+type SuratTembusan struct {
+	ID        uint          `gorm:"primaryKey" json:"id"`
+	SuratID   uint          `gorm:"uniqueIndex:ux_surat_tembusan" json:"surat_id"`
+	OrgID     uuid.UUID     `gorm:"type:uuid;uniqueIndex:ux_surat_tembusan;index" json:"org_id"`
+	Org       *Organization `gorm:"foreignKey:OrgID;references:ID" json:"org,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// SuratTemplate menyimpan kerangka surat yang bisa dipakai ulang. OrgID kosong berarti
+// template global (dikelola BEM) yang terlihat oleh semua organisasi.
+// Teks boleh berisi placeholder text/template, mis. {{.ToName}} atau {{.Activity.Title}}.
+type SuratTemplate struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	OrgID       *uuid.UUID     `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	Variant     string         `gorm:"type:varchar(32);index" json:"variant"`
+	Name        string         `gorm:"type:varchar(255);not null" json:"name"`
+	Description string         `gorm:"type:text" json:"description"`
+	Subject     string         `gorm:"type:varchar(255)" json:"subject"`
+	BodyOpening string         `gorm:"type:text" json:"body_opening"`
+	BodyContent datatypes.JSON `gorm:"type:jsonb" json:"body_content"` // []string
+	BodyClosing string         `gorm:"type:text" json:"body_closing"`
+	Footer      string         `gorm:"type:text" json:"footer"`
+	Signs       datatypes.JSON `gorm:"type:jsonb" json:"signs"`    // []suratpdf.Signer
+	Tembusan    datatypes.JSON `gorm:"type:jsonb" json:"tembusan"` // []string
+	Theme       datatypes.JSON `gorm:"type:jsonb" json:"theme"`    // suratpdf.Theme
+	CreatedBy   *uuid.UUID     `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
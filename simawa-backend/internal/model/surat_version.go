@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// SuratVersion merekam setiap PDF yang pernah dihasilkan untuk sebuah surat beserta
+// payload pembentuknya, sehingga file lama tidak hilang saat surat dirender ulang.
+type SuratVersion struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	SuratID   uint           `gorm:"uniqueIndex:ux_surat_version" json:"surat_id"`
+	Version   int            `gorm:"uniqueIndex:ux_surat_version" json:"version"`
+	Status    string         `gorm:"type:varchar(20)" json:"status"` // status surat saat versi dibuat
+	Number    string         `gorm:"type:varchar(128)" json:"number"`
+	FileKey   string         `gorm:"type:varchar(512)" json:"file_key"`
+	Payload   datatypes.JSON `gorm:"type:jsonb" json:"payload,omitempty"` // kosong untuk surat hasil upload
+	Theme     datatypes.JSON `gorm:"type:jsonb" json:"theme,omitempty"`
+	Reason    string         `gorm:"type:text" json:"reason"`
+	CreatedBy *uuid.UUID     `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Venue adalah ruangan/tempat kampus yang bisa dipesan kegiatan. Pemesanan
+// ditentukan oleh kegiatan (Activity.VenueID) berstatus PENDING/APPROVED.
+type Venue struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Code        string         `gorm:"type:varchar(32);not null;uniqueIndex" json:"code"` // mis. AULA-A
+	Name        string         `gorm:"size:255;not null" json:"name"`
+	Description string         `gorm:"size:512" json:"description"`
+	Capacity    int            `json:"capacity"`
+	Facilities  datatypes.JSON `gorm:"type:jsonb" json:"facilities"` // ["proyektor","sound system"]
+	OwnerUnit   string         `gorm:"size:255" json:"owner_unit"`   // unit pengelola, mis. Biro Umum
+	Active      bool           `gorm:"default:true;index" json:"active"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"simawa-backend/internal/model"
+)
+
+type ActivityAttendanceRepository interface {
+	// Record menyimpan presensi; false bila peserta sudah tercatat pada kemunculan itu.
+	Record(ctx context.Context, a *model.ActivityAttendance) (bool, error)
+	Get(ctx context.Context, activityID uuid.UUID, occurrence time.Time, userID uuid.UUID) (*model.ActivityAttendance, error)
+	List(ctx context.Context, activityID uuid.UUID) ([]model.ActivityAttendance, error)
+	Summary(ctx context.Context, activityID uuid.UUID) (*AttendanceSummary, error)
+}
+
+// AttendanceSummary merangkum presensi satu kegiatan (semua kemunculannya).
+type AttendanceSummary struct {
+	Total        int64                       `json:"total"`
+	Unique       int64                       `json:"unique"`
+	LastCheckIn  *time.Time                  `json:"last_check_in,omitempty"`
+	ByOccurrence []AttendanceOccurrenceCount `json:"by_occurrence"`
+}
+
+type AttendanceOccurrenceCount struct {
+	OccurrenceStart time.Time `json:"occurrence_start"`
+	Count           int64     `json:"count"`
+}
+
+type activityAttendanceRepository struct{ db *gorm.DB }
+
+func NewActivityAttendanceRepository(db *gorm.DB) ActivityAttendanceRepository {
+	return &activityAttendanceRepository{db: db}
+}
+
+func (r *activityAttendanceRepository) Record(ctx context.Context, a *model.ActivityAttendance) (bool, error) {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(a)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *activityAttendanceRepository) Get(ctx context.Context, activityID uuid.UUID, occurrence time.Time, userID uuid.UUID) (*model.ActivityAttendance, error) {
+	var a model.ActivityAttendance
+	err := r.db.WithContext(ctx).
+		Where("activity_id = ? AND occurrence_start = ? AND user_id = ?", activityID, occurrence, userID).
+		First(&a).Error
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *activityAttendanceRepository) List(ctx context.Context, activityID uuid.UUID) ([]model.ActivityAttendance, error) {
+	var rows []model.ActivityAttendance
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("activity_id = ?", activityID).
+		Order("occurrence_start ASC, checked_in_at ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+func (r *activityAttendanceRepository) Summary(ctx context.Context, activityID uuid.UUID) (*AttendanceSummary, error) {
+	out := &AttendanceSummary{ByOccurrence: []AttendanceOccurrenceCount{}}
+	db := r.db.WithContext(ctx).Model(&model.ActivityAttendance{}).Where("activity_id = ?", activityID)
+	var agg struct {
+		Total  int64
+		Unique int64
+		Last   *time.Time
+	}
+	if err := db.Session(&gorm.Session{}).
+		Select("COUNT(*) AS total, COUNT(DISTINCT user_id) AS \"unique\", MAX(checked_in_at) AS last").
+		Scan(&agg).Error; err != nil {
+		return nil, err
+	}
+	out.Total, out.Unique, out.LastCheckIn = agg.Total, agg.Unique, agg.Last
+	err := db.Session(&gorm.Session{}).
+		Select("occurrence_start, COUNT(*) AS count").
+		Group("occurrence_start").Order("occurrence_start ASC").
+		Scan(&out.ByOccurrence).Error
+	return out, err
+}
@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"simawa-backend/internal/model"
+)
+
+type ActivityRegistrationRepository interface {
+	GetForm(ctx context.Context, activityID uuid.UUID) (*model.ActivityRegistrationForm, error)
+	SaveForm(ctx context.Context, f *model.ActivityRegistrationForm) error
+	// Register menyimpan pendaftar sebagai REGISTERED bila kuota masih ada, atau
+	// WAITLISTED bila penuh. Baris formulir dikunci agar kuota tidak terlampaui.
+	Register(ctx context.Context, reg *model.ActivityRegistration) error
+	// CreateUnconfirmed menyimpan pendaftar yang belum mengonfirmasi emailnya.
+	// Permintaan konfirmasi sebelumnya untuk email yang sama tetap berlaku.
+	CreateUnconfirmed(ctx context.Context, reg *model.ActivityRegistration) error
+	// Confirm mengonfirmasi pendaftaran id lalu memberinya kursi atau daftar tunggu
+	// dengan aturan yang sama seperti Register, dan menghapus permintaan konfirmasi
+	// lain untuk email yang sama.
+	Confirm(ctx context.Context, id uint) (*model.ActivityRegistration, error)
+	// PurgeUnconfirmed menghapus pendaftaran yang tidak dikonfirmasi sebelum before.
+	PurgeUnconfirmed(ctx context.Context, before time.Time) (int64, error)
+	// Cancel membatalkan pendaftaran lalu menaikkan daftar tunggu bila kursinya kosong.
+	Cancel(ctx context.Context, id uint) (*model.ActivityRegistration, []model.ActivityRegistration, error)
+	// Promote mengisi kuota yang kosong dari daftar tunggu (mis. setelah kuota dinaikkan).
+	Promote(ctx context.Context, activityID uuid.UUID) ([]model.ActivityRegistration, error)
+	Get(ctx context.Context, id uint) (*model.ActivityRegistration, error)
+	GetByToken(ctx context.Context, token string) (*model.ActivityRegistration, error)
+	// List mengembalikan pendaftar berstatus status; kosong berarti semua kecuali UNCONFIRMED.
+	List(ctx context.Context, activityID uuid.UUID, status string) ([]model.ActivityRegistration, error)
+	CountByStatus(ctx context.Context, activityID uuid.UUID) (map[string]int64, error)
+}
+
+type activityRegistrationRepository struct{ db *gorm.DB }
+
+func NewActivityRegistrationRepository(db *gorm.DB) ActivityRegistrationRepository {
+	return &activityRegistrationRepository{db: db}
+}
+
+func (r *activityRegistrationRepository) GetForm(ctx context.Context, activityID uuid.UUID) (*model.ActivityRegistrationForm, error) {
+	var f model.ActivityRegistrationForm
+	if err := r.db.WithContext(ctx).Where("activity_id = ?", activityID).First(&f).Error; err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *activityRegistrationRepository) SaveForm(ctx context.Context, f *model.ActivityRegistrationForm) error {
+	return r.db.WithContext(ctx).Save(f).Error
+}
+
+func lockRegistrationForm(tx *gorm.DB, activityID uuid.UUID) (*model.ActivityRegistrationForm, error) {
+	var f model.ActivityRegistrationForm
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("activity_id = ?", activityID).First(&f).Error; err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func countRegistrations(tx *gorm.DB, activityID uuid.UUID, status string) (int64, error) {
+	var n int64
+	err := tx.Model(&model.ActivityRegistration{}).Where("activity_id = ? AND status = ?", activityID, status).Count(&n).Error
+	return n, err
+}
+
+func (r *activityRegistrationRepository) Register(ctx context.Context, reg *model.ActivityRegistration) error {
+	if reg == nil {
+		return errors.New("registration nil")
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		f, err := lockRegistrationForm(tx, reg.ActivityID)
+		if err != nil {
+			return err
+		}
+		if err := assignSeat(tx, f, reg); err != nil {
+			return err
+		}
+		return tx.Create(reg).Error
+	})
+}
+
+// checkDuplicate menolak email yang sudah punya pendaftaran aktif pada kegiatan
+// tersebut. Pendaftaran yang belum dikonfirmasi tidak dihitung.
+func checkDuplicate(tx *gorm.DB, reg *model.ActivityRegistration) error {
+	var dup int64
+	if err := tx.Model(&model.ActivityRegistration{}).
+		Where("activity_id = ? AND LOWER(email) = LOWER(?) AND id <> ? AND status NOT IN ?", reg.ActivityID, reg.Email, reg.ID,
+			[]string{model.RegistrationStatusCancelled, model.RegistrationStatusUnconfirmed}).
+		Count(&dup).Error; err != nil {
+		return err
+	}
+	if dup > 0 {
+		return errors.New("email ini sudah terdaftar pada kegiatan ini")
+	}
+	return nil
+}
+
+// assignSeat mengisi status reg (REGISTERED atau WAITLISTED) menurut kuota formulir f
+// yang sudah dikunci.
+func assignSeat(tx *gorm.DB, f *model.ActivityRegistrationForm, reg *model.ActivityRegistration) error {
+	if err := checkDuplicate(tx, reg); err != nil {
+		return err
+	}
+	if f.Quota <= 0 {
+		reg.Status = model.RegistrationStatusRegistered
+		return nil
+	}
+	registered, err := countRegistrations(tx, reg.ActivityID, model.RegistrationStatusRegistered)
+	if err != nil {
+		return err
+	}
+	waiting, err := countRegistrations(tx, reg.ActivityID, model.RegistrationStatusWaitlisted)
+	if err != nil {
+		return err
+	}
+	reg.Status, err = seatStatus(f, registered, waiting)
+	return err
+}
+
+// seatStatus menentukan status pendaftar baru dari jumlah pendaftar REGISTERED dan
+// WAITLISTED saat ini. Kuota 0 berarti tanpa batas; WaitlistLimit 0 berarti daftar
+// tunggu tanpa batas.
+func seatStatus(f *model.ActivityRegistrationForm, registered, waiting int64) (string, error) {
+	if f.Quota <= 0 || registered < int64(f.Quota) {
+		return model.RegistrationStatusRegistered, nil
+	}
+	if f.WaitlistLimit > 0 && waiting >= int64(f.WaitlistLimit) {
+		return "", errors.New("kuota dan daftar tunggu sudah penuh")
+	}
+	return model.RegistrationStatusWaitlisted, nil
+}
+
+// freeSeats mengembalikan jumlah kursi kosong untuk pendaftar daftar tunggu;
+// -1 berarti tanpa batas.
+func freeSeats(f *model.ActivityRegistrationForm, registered int64) int {
+	if f.Quota <= 0 {
+		return -1
+	}
+	if free := int64(f.Quota) - registered; free > 0 {
+		return int(free)
+	}
+	return 0
+}
+
+func (r *activityRegistrationRepository) CreateUnconfirmed(ctx context.Context, reg *model.ActivityRegistration) error {
+	if reg == nil {
+		return errors.New("registration nil")
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockRegistrationForm(tx, reg.ActivityID); err != nil {
+			return err
+		}
+		if err := checkDuplicate(tx, reg); err != nil {
+			return err
+		}
+		reg.Status = model.RegistrationStatusUnconfirmed
+		return tx.Create(reg).Error
+	})
+}
+
+func (r *activityRegistrationRepository) Confirm(ctx context.Context, id uint) (*model.ActivityRegistration, error) {
+	var reg model.ActivityRegistration
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&reg, id).Error; err != nil {
+			return err
+		}
+		f, err := lockRegistrationForm(tx, reg.ActivityID)
+		if err != nil {
+			return err
+		}
+		// dibaca ulang setelah formulir terkunci
+		if err := tx.First(&reg, id).Error; err != nil {
+			return err
+		}
+		if reg.Status != model.RegistrationStatusUnconfirmed {
+			return errors.New("pendaftaran sudah dikonfirmasi")
+		}
+		if err := assignSeat(tx, f, &reg); err != nil {
+			return err
+		}
+		if err := tx.Save(&reg).Error; err != nil {
+			return err
+		}
+		// permintaan konfirmasi lain untuk email yang sama tidak berlaku lagi
+		return tx.Where("activity_id = ? AND LOWER(email) = LOWER(?) AND status = ?", reg.ActivityID, reg.Email, model.RegistrationStatusUnconfirmed).
+			Delete(&model.ActivityRegistration{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &reg, nil
+}
+
+func (r *activityRegistrationRepository) PurgeUnconfirmed(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Where("status = ? AND created_at < ?", model.RegistrationStatusUnconfirmed, before).
+		Delete(&model.ActivityRegistration{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *activityRegistrationRepository) Cancel(ctx context.Context, id uint) (*model.ActivityRegistration, []model.ActivityRegistration, error) {
+	var reg model.ActivityRegistration
+	var promoted []model.ActivityRegistration
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&reg, id).Error; err != nil {
+			return err
+		}
+		f, err := lockRegistrationForm(tx, reg.ActivityID)
+		if err != nil {
+			return err
+		}
+		// dibaca ulang setelah formulir terkunci
+		if err := tx.First(&reg, id).Error; err != nil {
+			return err
+		}
+		if reg.Status == model.RegistrationStatusCancelled {
+			return errors.New("pendaftaran sudah dibatalkan")
+		}
+		now := time.Now()
+		reg.Status = model.RegistrationStatusCancelled
+		reg.CancelledAt = &now
+		if err := tx.Save(&reg).Error; err != nil {
+			return err
+		}
+		promoted, err = promoteWaitlist(tx, f)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &reg, promoted, nil
+}
+
+func (r *activityRegistrationRepository) Promote(ctx context.Context, activityID uuid.UUID) ([]model.ActivityRegistration, error) {
+	var promoted []model.ActivityRegistration
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		f, err := lockRegistrationForm(tx, activityID)
+		if err != nil {
+			return err
+		}
+		promoted, err = promoteWaitlist(tx, f)
+		return err
+	})
+	return promoted, err
+}
+
+// promoteWaitlist menaikkan pendaftar daftar tunggu tertua sampai kuota terisi.
+func promoteWaitlist(tx *gorm.DB, f *model.ActivityRegistrationForm) ([]model.ActivityRegistration, error) {
+	q := tx.Where("activity_id = ? AND status = ?", f.ActivityID, model.RegistrationStatusWaitlisted).Order("created_at ASC, id ASC")
+	if f.Quota > 0 {
+		registered, err := countRegistrations(tx, f.ActivityID, model.RegistrationStatusRegistered)
+		if err != nil {
+			return nil, err
+		}
+		free := freeSeats(f, registered)
+		if free == 0 {
+			return nil, nil
+		}
+		q = q.Limit(free)
+	}
+	var rows []model.ActivityRegistration
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range rows {
+		rows[i].Status = model.RegistrationStatusRegistered
+		rows[i].PromotedAt = &now
+		if err := tx.Save(&rows[i]).Error; err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+func (r *activityRegistrationRepository) Get(ctx context.Context, id uint) (*model.ActivityRegistration, error) {
+	var reg model.ActivityRegistration
+	if err := r.db.WithContext(ctx).First(&reg, id).Error; err != nil {
+		return nil, err
+	}
+	return &reg, nil
+}
+
+func (r *activityRegistrationRepository) GetByToken(ctx context.Context, token string) (*model.ActivityRegistration, error) {
+	var reg model.ActivityRegistration
+	if err := r.db.WithContext(ctx).Where("token = ?", token).First(&reg).Error; err != nil {
+		return nil, err
+	}
+	return &reg, nil
+}
+
+func (r *activityRegistrationRepository) List(ctx context.Context, activityID uuid.UUID, status string) ([]model.ActivityRegistration, error) {
+	var rows []model.ActivityRegistration
+	tx := r.db.WithContext(ctx).Where("activity_id = ?", activityID)
+	if status != "" {
+		tx = tx.Where("status = ?", status)
+	} else {
+		tx = tx.Where("status <> ?", model.RegistrationStatusUnconfirmed)
+	}
+	err := tx.Order("created_at ASC, id ASC").Find(&rows).Error
+	return rows, err
+}
+
+func (r *activityRegistrationRepository) CountByStatus(ctx context.Context, activityID uuid.UUID) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		N      int64
+	}
+	err := r.db.WithContext(ctx).Model(&model.ActivityRegistration{}).
+		Select("status, COUNT(*) AS n").
+		Where("activity_id = ?", activityID).
+		Group("status").Scan(&rows).Error
+	out := map[string]int64{}
+	for _, row := range rows {
+		out[row.Status] = row.N
+	}
+	return out, err
+}
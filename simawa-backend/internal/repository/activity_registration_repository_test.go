@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"testing"
+
+	"simawa-backend/internal/model"
+)
+
+func TestSeatStatus(t *testing.T) {
+	tests := []struct {
+		name                string
+		quota, waitlist     int
+		registered, waiting int64
+		want                string // kosong berarti harus error
+	}{
+		{"tanpa kuota", 0, 0, 500, 0, model.RegistrationStatusRegistered},
+		{"kuota masih ada", 10, 0, 9, 0, model.RegistrationStatusRegistered},
+		{"kuota penuh masuk daftar tunggu", 10, 5, 10, 4, model.RegistrationStatusWaitlisted},
+		{"daftar tunggu tanpa batas", 10, 0, 10, 100, model.RegistrationStatusWaitlisted},
+		{"kuota dan daftar tunggu penuh", 10, 5, 10, 5, ""},
+		{"kuota terlampaui setelah diturunkan", 5, 5, 8, 0, model.RegistrationStatusWaitlisted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &model.ActivityRegistrationForm{Quota: tt.quota, WaitlistLimit: tt.waitlist}
+			got, err := seatStatus(f, tt.registered, tt.waiting)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("seatStatus = %q, want error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("seatStatus = (%q, %v), want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreeSeats(t *testing.T) {
+	tests := []struct {
+		name       string
+		quota      int
+		registered int64
+		want       int
+	}{
+		{"tanpa kuota", 0, 42, -1},
+		{"sebagian terisi", 10, 7, 3},
+		{"penuh", 10, 10, 0},
+		{"kuota diturunkan di bawah terdaftar", 5, 8, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &model.ActivityRegistrationForm{Quota: tt.quota}
+			if got := freeSeats(f, tt.registered); got != tt.want {
+				t.Fatalf("freeSeats = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +16,28 @@ type ActivityRepository interface {
 	Get(ctx context.Context, id uuid.UUID) (*model.Activity, error)
 	List(ctx context.Context, orgID uuid.UUID, status, actType string, publicOnly bool, page, size int, start, end time.Time) ([]model.Activity, error)
 	ListPublic(ctx context.Context, from time.Time) ([]model.Activity, error)
+	CompleteOverdue(ctx context.Context, now time.Time, lpjDue time.Duration, limit int) ([]model.Activity, error)
+	ListWindow(ctx context.Context, q ActivityWindowQuery) ([]model.Activity, error)
+	ListOverrides(ctx context.Context, seriesIDs []uuid.UUID) ([]model.Activity, error)
+	GetOverride(ctx context.Context, seriesID uuid.UUID, recurrenceID time.Time) (*model.Activity, error)
+	ListSeries(ctx context.Context, rootID uuid.UUID) ([]model.Activity, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// SplitSeries menyimpan induk head yang sudah dipotong, membuat pecahan seri next,
+	// dan menghapus kemunculan diubah prune dalam satu transaksi.
+	SplitSeries(ctx context.Context, head, next *model.Activity, prune []uuid.UUID) error
+	UpdateSeriesStatus(ctx context.Context, seriesID uuid.UUID, from, to string) error
+}
+
+// ActivityWindowQuery memilih kegiatan yang (salah satu kemunculannya) bisa jatuh di [From, To].
+type ActivityWindowQuery struct {
+	OrgID      *uuid.UUID
+	VenueID    *uint
+	Status     string
+	Statuses   []string // alternatif Status untuk beberapa status sekaligus
+	Type       string
+	PublicOnly bool
+	From       time.Time
+	To         time.Time
 }
 
 type activityRepository struct {
@@ -43,7 +66,7 @@ func (r *activityRepository) Get(ctx context.Context, id uuid.UUID) (*model.Acti
 
 func (r *activityRepository) List(ctx context.Context, orgID uuid.UUID, status, actType string, publicOnly bool, page, size int, start, end time.Time) ([]model.Activity, error) {
 	var rows []model.Activity
-	q := r.db.WithContext(ctx).Model(&model.Activity{}).Where("org_id = ?", orgID)
+	q := r.db.WithContext(ctx).Model(&model.Activity{}).Where("org_id = ? AND recurrence_id IS NULL", orgID)
 	if status != "" {
 		q = q.Where("status = ?", status)
 	}
@@ -77,7 +100,7 @@ func (r *activityRepository) ListPublic(ctx context.Context, from time.Time) ([]
 	if err := r.db.WithContext(ctx).
 		Model(&model.Activity{}).
 		Where("public = ? AND status = ?", true, model.ActivityStatusApproved).
-		Where("start_at >= ?", from).
+		Where("start_at >= ? AND recurrence_id IS NULL", from).
 		Order("start_at ASC").
 		Find(&rows).Error; err != nil {
 		return nil, err
@@ -85,3 +108,118 @@ func (r *activityRepository) ListPublic(ctx context.Context, from time.Time) ([]
 	return rows, nil
 }
 
+// CompleteOverdue memindahkan kegiatan APPROVED yang EndAt-nya lewat ke COMPLETED
+// dalam satu UPDATE ... RETURNING. Seri berulang baru selesai setelah kemunculan
+// terakhirnya (RecurUntil); seri tanpa akhir dan kemunculan yang diubah dilewati.
+// Baris dikunci dengan SKIP LOCKED dan status dicek ulang, sehingga beberapa replika
+// yang berjalan bersamaan tidak memproses kegiatan yang sama dua kali; hanya baris
+// yang benar-benar berpindah yang dikembalikan.
+func (r *activityRepository) CompleteOverdue(ctx context.Context, now time.Time, lpjDue time.Duration, limit int) ([]model.Activity, error) {
+	var rows []model.Activity
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE activities SET status = ?, completed_at = ?, lpj_due_at = COALESCE(recur_until, end_at) + ?::interval, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM activities
+			WHERE status = ? AND recurrence_id IS NULL
+			AND COALESCE(recur_until, end_at) < ? AND end_at > ?
+			AND (COALESCE(rrule, '') = '' OR recur_until IS NOT NULL)
+			ORDER BY end_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		) AND status = ?
+		RETURNING *`,
+		model.ActivityStatusCompleted, now, fmt.Sprintf("%d seconds", int64(lpjDue.Seconds())), now,
+		model.ActivityStatusApproved, now, time.Time{}, limit, model.ActivityStatusApproved,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *activityRepository) ListWindow(ctx context.Context, q ActivityWindowQuery) ([]model.Activity, error) {
+	var rows []model.Activity
+	tx := r.db.WithContext(ctx).Model(&model.Activity{}).Where("recurrence_id IS NULL")
+	if q.OrgID != nil {
+		tx = tx.Where("org_id = ?", *q.OrgID)
+	}
+	if q.VenueID != nil {
+		tx = tx.Where("venue_id = ?", *q.VenueID)
+	}
+	if q.Status != "" {
+		tx = tx.Where("status = ?", q.Status)
+	}
+	if len(q.Statuses) > 0 {
+		tx = tx.Where("status IN ?", q.Statuses)
+	}
+	if q.Type != "" {
+		tx = tx.Where("type = ?", q.Type)
+	}
+	if q.PublicOnly {
+		tx = tx.Where("public = ?", true)
+	}
+	tx = tx.Where("start_at <= ?", q.To).Where(
+		"(COALESCE(rrule, '') = '' AND end_at >= ?) OR (COALESCE(rrule, '') <> '' AND (recur_until IS NULL OR recur_until >= ?))",
+		q.From, q.From,
+	)
+	if err := tx.Order("start_at ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// ListOverrides mengembalikan kemunculan yang diubah milik seri-seri tersebut.
+func (r *activityRepository) ListOverrides(ctx context.Context, seriesIDs []uuid.UUID) ([]model.Activity, error) {
+	var rows []model.Activity
+	if len(seriesIDs) == 0 {
+		return rows, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("series_id IN ? AND recurrence_id IS NOT NULL", seriesIDs).
+		Order("recurrence_id ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+func (r *activityRepository) GetOverride(ctx context.Context, seriesID uuid.UUID, recurrenceID time.Time) (*model.Activity, error) {
+	var a model.Activity
+	if err := r.db.WithContext(ctx).First(&a, "series_id = ? AND recurrence_id = ?", seriesID, recurrenceID).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListSeries mengembalikan induk seri beserta pecahan seri dan kemunculan yang diubah.
+func (r *activityRepository) ListSeries(ctx context.Context, rootID uuid.UUID) ([]model.Activity, error) {
+	var rows []model.Activity
+	err := r.db.WithContext(ctx).
+		Where("id = ? OR series_id = ?", rootID, rootID).
+		Order("start_at ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+func (r *activityRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.Activity{}, "id = ?", id).Error
+}
+
+func (r *activityRepository) SplitSeries(ctx context.Context, head, next *model.Activity, prune []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(head).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		if len(prune) == 0 {
+			return nil
+		}
+		return tx.Delete(&model.Activity{}, "id IN ?", prune).Error
+	})
+}
+
+// UpdateSeriesStatus menyamakan status pecahan seri dan kemunculan yang diubah dengan
+// induknya. Hanya baris berstatus from yang diubah, sehingga bagian seri yang sedang
+// menunggu persetujuan ulang (atau sudah disetujui) tidak ikut tertimpa.
+func (r *activityRepository) UpdateSeriesStatus(ctx context.Context, seriesID uuid.UUID, from, to string) error {
+	return r.db.WithContext(ctx).Model(&model.Activity{}).
+		Where("series_id = ? AND status = ?", seriesID, from).
+		Updates(map[string]any{"status": to, "updated_at": time.Now()}).Error
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Jenis hasil pencarian.
+const (
+	SearchTypeSurat    = "surat"
+	SearchTypeActivity = "activity"
+	SearchTypeLPJ      = "lpj"
+)
+
+// SearchTypes adalah semua jenis yang bisa dicari, urut sesuai facet.
+var SearchTypes = []string{SearchTypeSurat, SearchTypeActivity, SearchTypeLPJ}
+
+// SearchScope membatasi hasil sesuai RBAC pemanggil. All = admin global;
+// selain itu hanya data organisasi OrgIDs, surat untuk Roles, dan surat yang
+// melibatkan UserID (penanda tangan/disposisi).
+type SearchScope struct {
+	All    bool
+	UserID uuid.UUID
+	OrgIDs []uuid.UUID
+	Roles  []string
+}
+
+type SearchQuery struct {
+	Text  string
+	Types []string // kosong = semua jenis
+	Scope SearchScope
+	Page  int
+	Size  int
+}
+
+type SearchHit struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet"`
+	OrgID     uuid.UUID `json:"org_id"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	Rank      float64   `json:"rank"`
+}
+
+type SearchRepository interface {
+	// Search mengembalikan hasil halaman ini, total untuk jenis terpilih, dan
+	// facet jumlah hasil per jenis (tanpa filter jenis).
+	Search(ctx context.Context, q SearchQuery) ([]SearchHit, int64, map[string]int64, error)
+}
+
+type searchRepository struct{ db *gorm.DB }
+
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+const searchHeadline = `'MaxWords=30, MinWords=10, StartSel=<mark>, StopSel=</mark>'`
+
+func (r *searchRepository) Search(ctx context.Context, q SearchQuery) ([]SearchHit, int64, map[string]int64, error) {
+	facets := map[string]int64{}
+	for _, t := range SearchTypes {
+		facets[t] = 0
+	}
+	all, allArgs := r.union(q, SearchTypes)
+	var counts []struct {
+		Type  string
+		Total int64
+	}
+	if err := r.db.WithContext(ctx).
+		Raw("SELECT type, COUNT(*) AS total FROM ("+all+") u GROUP BY type", allArgs...).
+		Scan(&counts).Error; err != nil {
+		return nil, 0, nil, err
+	}
+	types := q.Types
+	if len(types) == 0 {
+		types = SearchTypes
+	}
+	var total int64
+	for _, c := range counts {
+		facets[c.Type] = c.Total
+		for _, t := range types {
+			if t == c.Type {
+				total += c.Total
+			}
+		}
+	}
+	if total == 0 {
+		return []SearchHit{}, 0, facets, nil
+	}
+
+	page, size := q.Page, q.Size
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 50 {
+		size = 20
+	}
+	sql, args := r.union(q, types)
+	args = append(args, size, (page-1)*size)
+	var hits []SearchHit
+	if err := r.db.WithContext(ctx).
+		Raw("SELECT * FROM ("+sql+") u ORDER BY rank DESC, created_at DESC LIMIT ? OFFSET ?", args...).
+		Scan(&hits).Error; err != nil {
+		return nil, 0, nil, err
+	}
+	return hits, total, facets, nil
+}
+
+// union menyusun UNION ALL subquery per jenis.
+func (r *searchRepository) union(q SearchQuery, types []string) (string, []any) {
+	var parts []string
+	var args []any
+	for _, t := range types {
+		var sql string
+		var a []any
+		switch t {
+		case SearchTypeSurat:
+			sql, a = searchSurat(q)
+		case SearchTypeActivity:
+			sql, a = searchActivity(q)
+		case SearchTypeLPJ:
+			sql, a = searchLPJ(q)
+		default:
+			continue
+		}
+		parts = append(parts, sql)
+		args = append(args, a...)
+	}
+	return strings.Join(parts, " UNION ALL "), args
+}
+
+func searchSurat(q SearchQuery) (string, []any) {
+	sql := `SELECT 'surat' AS type, s.id::text AS id, s.subject AS title,
+		ts_headline('simple', concat_ws(' — ', s.number, s.subject, s.to_name), tq, ` + searchHeadline + `) AS snippet,
+		s.org_id AS org_id, s.status AS status, s.created_at AS created_at, ts_rank(s.search_tsv, tq) AS rank
+		FROM surats s, websearch_to_tsquery('simple', ?) tq
+		WHERE (s.search_tsv @@ tq OR s.number ILIKE ?)`
+	args := []any{q.Text, "%" + escapeLike(q.Text) + "%"}
+	if !q.Scope.All {
+		sql += ` AND (s.org_id IN ? OR s.target_org_id IN ? OR UPPER(s.to_role) IN ?
+			OR EXISTS (SELECT 1 FROM surat_signatures g WHERE g.surat_id = s.id AND g.user_id = ?)
+			OR EXISTS (SELECT 1 FROM surat_dispositions d WHERE d.surat_id = s.id AND d.to_user_id = ?)
+			OR (s.status IN ('APPROVED', 'VOIDED') AND EXISTS (SELECT 1 FROM surat_tembusans t WHERE t.surat_id = s.id AND t.org_id IN ?)))`
+		args = append(args, q.Scope.OrgIDs, q.Scope.OrgIDs, q.Scope.Roles, q.Scope.UserID, q.Scope.UserID, q.Scope.OrgIDs)
+	}
+	return sql, args
+}
+
+func searchActivity(q SearchQuery) (string, []any) {
+	sql := `SELECT 'activity' AS type, a.id::text AS id, a.title AS title,
+		ts_headline('simple', concat_ws(' — ', a.title, a.description), tq, ` + searchHeadline + `) AS snippet,
+		a.org_id AS org_id, a.status AS status, a.created_at AS created_at, ts_rank(a.search_tsv, tq) AS rank
+		FROM activities a, websearch_to_tsquery('simple', ?) tq
+		WHERE a.search_tsv @@ tq`
+	args := []any{q.Text}
+	if !q.Scope.All {
+		// kegiatan publik yang sudah disetujui boleh ditemukan siapa saja
+		sql += ` AND (a.org_id IN ? OR (a.public = true AND a.status = 'APPROVED'))`
+		args = append(args, q.Scope.OrgIDs)
+	}
+	return sql, args
+}
+
+func searchLPJ(q SearchQuery) (string, []any) {
+	sql := `SELECT 'lpj' AS type, l.id::text AS id, COALESCE(a.title, LEFT(l.summary, 80)) AS title,
+		ts_headline('simple', l.summary, tq, ` + searchHeadline + `) AS snippet,
+		l.org_id AS org_id, l.status AS status, l.created_at AS created_at, ts_rank(l.search_tsv, tq) AS rank
+		FROM lpjs l LEFT JOIN activities a ON a.id = l.activity_id, websearch_to_tsquery('simple', ?) tq
+		WHERE l.search_tsv @@ tq`
+	args := []any{q.Text}
+	if !q.Scope.All {
+		sql += ` AND l.org_id IN ?`
+		args = append(args, q.Scope.OrgIDs)
+	}
+	return sql, args
+}
+
+func escapeLike(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(v)
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"simawa-backend/internal/model"
+)
+
+type ListSuratAgendaQuery struct {
+	OrgID uuid.UUID
+	From  *time.Time
+	To    *time.Time // eksklusif
+	Q     string
+	Page  int
+	Size  int // 0 = tanpa batas (untuk export)
+}
+
+type SuratAgendaRepository interface {
+	// Register mengambil nomor agenda berikutnya untuk org/tahun penerimaan,
+	// lalu menyimpan surat dan baris agendanya dalam satu transaksi.
+	Register(ctx context.Context, s *model.Surat, a *model.SuratAgenda, format func(seq int) string) error
+	GetBySurat(ctx context.Context, suratID uint) (*model.SuratAgenda, error)
+	List(ctx context.Context, q ListSuratAgendaQuery) ([]model.SuratAgenda, int64, error)
+}
+
+type suratAgendaRepository struct{ db *gorm.DB }
+
+func NewSuratAgendaRepository(db *gorm.DB) SuratAgendaRepository {
+	return &suratAgendaRepository{db: db}
+}
+
+func (r *suratAgendaRepository) Register(ctx context.Context, s *model.Surat, a *model.SuratAgenda, format func(seq int) string) error {
+	if s == nil || a == nil {
+		return errors.New("surat nil")
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var seq int
+		if err := tx.Raw(`
+			INSERT INTO surat_number_counters (org_id, variant, year, last_seq, updated_at)
+			VALUES (?, ?, ?, 1, ?)
+			ON CONFLICT (org_id, variant, year)
+			DO UPDATE SET last_seq = surat_number_counters.last_seq + 1, updated_at = EXCLUDED.updated_at
+			RETURNING last_seq`,
+			a.OrgID, model.SuratAgendaCounterVariant, a.Year, time.Now(),
+		).Scan(&seq).Error; err != nil {
+			return err
+		}
+		if seq <= 0 {
+			return errors.New("failed to allocate agenda number")
+		}
+		if err := tx.Create(s).Error; err != nil {
+			return err
+		}
+		a.Seq = seq
+		a.AgendaNumber = format(seq)
+		a.SuratID = s.ID
+		return tx.Create(a).Error
+	})
+}
+
+func (r *suratAgendaRepository) GetBySurat(ctx context.Context, suratID uint) (*model.SuratAgenda, error) {
+	var a model.SuratAgenda
+	if err := r.db.WithContext(ctx).First(&a, "surat_id = ?", suratID).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *suratAgendaRepository) List(ctx context.Context, q ListSuratAgendaQuery) ([]model.SuratAgenda, int64, error) {
+	var rows []model.SuratAgenda
+	tx := r.db.WithContext(ctx).Model(&model.SuratAgenda{}).Where("org_id = ?", q.OrgID)
+	if q.From != nil {
+		tx = tx.Where("received_at >= ?", *q.From)
+	}
+	if q.To != nil {
+		tx = tx.Where("received_at < ?", *q.To)
+	}
+	if q.Q != "" {
+		like := "%" + q.Q + "%"
+		tx = tx.Where("sender ILIKE ? OR subject ILIKE ? OR sender_number ILIKE ? OR agenda_number ILIKE ?", like, like, like, like)
+	}
+
+	var total int64
+	_ = tx.Count(&total).Error
+
+	tx = tx.Order("year ASC, seq ASC")
+	if q.Size > 0 {
+		page := q.Page
+		if page < 1 {
+			page = 1
+		}
+		size := q.Size
+		if size > 100 {
+			size = 100
+		}
+		tx = tx.Limit(size).Offset((page - 1) * size)
+	}
+	if err := tx.Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"simawa-backend/internal/model"
+)
+
+type SuratAttachmentRepository interface {
+	Create(ctx context.Context, a *model.SuratAttachment) error
+	Delete(ctx context.Context, id uint) error
+	Get(ctx context.Context, id uint) (*model.SuratAttachment, error)
+	ListBySurat(ctx context.Context, suratID uint) ([]model.SuratAttachment, error)
+	// ReplaceFileKey memindahkan referensi berkas lampiran (mis. ke cold storage).
+	ReplaceFileKey(ctx context.Context, suratID uint, oldKey, newKey string) error
+}
+
+type suratAttachmentRepository struct{ db *gorm.DB }
+
+func NewSuratAttachmentRepository(db *gorm.DB) SuratAttachmentRepository {
+	return &suratAttachmentRepository{db: db}
+}
+
+func (r *suratAttachmentRepository) Create(ctx context.Context, a *model.SuratAttachment) error {
+	return r.db.WithContext(ctx).Create(a).Error
+}
+
+func (r *suratAttachmentRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.SuratAttachment{}, id).Error
+}
+
+func (r *suratAttachmentRepository) Get(ctx context.Context, id uint) (*model.SuratAttachment, error) {
+	var a model.SuratAttachment
+	if err := r.db.WithContext(ctx).First(&a, id).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListBySurat mengembalikan lampiran sesuai urutan unggah (urutan halaman di PDF).
+func (r *suratAttachmentRepository) ListBySurat(ctx context.Context, suratID uint) ([]model.SuratAttachment, error) {
+	var rows []model.SuratAttachment
+	err := r.db.WithContext(ctx).Where("surat_id = ?", suratID).Order("id ASC").Find(&rows).Error
+	return rows, err
+}
+
+func (r *suratAttachmentRepository) ReplaceFileKey(ctx context.Context, suratID uint, oldKey, newKey string) error {
+	return r.db.WithContext(ctx).Model(&model.SuratAttachment{}).
+		Where("surat_id = ? AND file_key = ?", suratID, oldKey).
+		Update("file_key", newKey).Error
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"simawa-backend/internal/model"
+)
+
+type SuratBatchRepository interface {
+	Create(ctx context.Context, b *model.SuratBatch) error
+	Update(ctx context.Context, b *model.SuratBatch) error
+	Get(ctx context.Context, id uint) (*model.SuratBatch, error)
+	// ListUnfinished mengembalikan batch yang masih QUEUED/RUNNING.
+	ListUnfinished(ctx context.Context) ([]model.SuratBatch, error)
+}
+
+type suratBatchRepository struct{ db *gorm.DB }
+
+func NewSuratBatchRepository(db *gorm.DB) SuratBatchRepository {
+	return &suratBatchRepository{db: db}
+}
+
+func (r *suratBatchRepository) Create(ctx context.Context, b *model.SuratBatch) error {
+	return r.db.WithContext(ctx).Create(b).Error
+}
+
+func (r *suratBatchRepository) Update(ctx context.Context, b *model.SuratBatch) error {
+	return r.db.WithContext(ctx).Save(b).Error
+}
+
+func (r *suratBatchRepository) Get(ctx context.Context, id uint) (*model.SuratBatch, error) {
+	var b model.SuratBatch
+	if err := r.db.WithContext(ctx).First(&b, id).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *suratBatchRepository) ListUnfinished(ctx context.Context) ([]model.SuratBatch, error) {
+	var rows []model.SuratBatch
+	err := r.db.WithContext(ctx).
+		Where("status IN ?", []string{model.SuratBatchStatusQueued, model.SuratBatchStatusRunning}).
+		Order("id ASC").
+		Find(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"simawa-backend/internal/model"
+)
+
+type SuratDeliveryRepository interface {
+	Create(ctx context.Context, d *model.SuratDelivery) error
+	ListBySurat(ctx context.Context, suratID uint) ([]model.SuratDelivery, error)
+}
+
+type suratDeliveryRepository struct{ db *gorm.DB }
+
+func NewSuratDeliveryRepository(db *gorm.DB) SuratDeliveryRepository {
+	return &suratDeliveryRepository{db: db}
+}
+
+func (r *suratDeliveryRepository) Create(ctx context.Context, d *model.SuratDelivery) error {
+	return r.db.WithContext(ctx).Create(d).Error
+}
+
+func (r *suratDeliveryRepository) ListBySurat(ctx context.Context, suratID uint) ([]model.SuratDelivery, error) {
+	var rows []model.SuratDelivery
+	err := r.db.WithContext(ctx).Where("surat_id = ?", suratID).Order("id ASC").Find(&rows).Error
+	return rows, err
+}
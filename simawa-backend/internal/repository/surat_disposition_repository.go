@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"simawa-backend/internal/model"
+)
+
+type SuratDispositionRepository interface {
+	Create(ctx context.Context, rows []model.SuratDisposition) error
+	Update(ctx context.Context, d *model.SuratDisposition) error
+	Get(ctx context.Context, id uint) (*model.SuratDisposition, error)
+	ListBySurat(ctx context.Context, suratID uint) ([]model.SuratDisposition, error)
+	ListByAssignee(ctx context.Context, userID uuid.UUID, status string, page, size int) ([]model.SuratDisposition, int64, error)
+	IsAssignee(ctx context.Context, suratID uint, userID uuid.UUID) (bool, error)
+}
+
+type suratDispositionRepository struct{ db *gorm.DB }
+
+func NewSuratDispositionRepository(db *gorm.DB) SuratDispositionRepository {
+	return &suratDispositionRepository{db: db}
+}
+
+// Create menyimpan satu disposisi per penerima dalam satu transaksi.
+func (r *suratDispositionRepository) Create(ctx context.Context, rows []model.SuratDisposition) error {
+	return r.db.WithContext(ctx).Create(&rows).Error
+}
+
+func (r *suratDispositionRepository) Update(ctx context.Context, d *model.SuratDisposition) error {
+	return r.db.WithContext(ctx).Save(d).Error
+}
+
+func (r *suratDispositionRepository) Get(ctx context.Context, id uint) (*model.SuratDisposition, error) {
+	var d model.SuratDisposition
+	if err := r.db.WithContext(ctx).First(&d, id).Error; err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ListBySurat mengembalikan seluruh rantai disposisi surat, urut dari yang pertama.
+func (r *suratDispositionRepository) ListBySurat(ctx context.Context, suratID uint) ([]model.SuratDisposition, error) {
+	var rows []model.SuratDisposition
+	err := r.db.WithContext(ctx).
+		Preload("FromUser").
+		Preload("ToUser").
+		Where("surat_id = ?", suratID).
+		Order("id ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+func (r *suratDispositionRepository) ListByAssignee(ctx context.Context, userID uuid.UUID, status string, page, size int) ([]model.SuratDisposition, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size <= 0 || size > 100 {
+		size = 20
+	}
+	db := r.db.WithContext(ctx).Model(&model.SuratDisposition{}).Where("to_user_id = ?", userID)
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []model.SuratDisposition
+	err := db.Preload("Surat").
+		Preload("FromUser").
+		Order("CASE WHEN status = 'OPEN' THEN 0 ELSE 1 END, due_date ASC NULLS LAST, id DESC").
+		Offset((page - 1) * size).
+		Limit(size).
+		Find(&rows).Error
+	return rows, total, err
+}
+
+func (r *suratDispositionRepository) IsAssignee(ctx context.Context, suratID uint, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.SuratDisposition{}).
+		Where("surat_id = ? AND to_user_id = ?", suratID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"simawa-backend/internal/model"
+)
+
+type SuratFontRepository interface {
+	Save(ctx context.Context, m *model.SuratFont) error
+	Delete(ctx context.Context, id uint) error
+	Get(ctx context.Context, id uint) (*model.SuratFont, error)
+	Find(ctx context.Context, orgID uuid.UUID, family, style string) (*model.SuratFont, error)
+	ListByOrg(ctx context.Context, orgID uuid.UUID) ([]model.SuratFont, error)
+	ListFamily(ctx context.Context, orgID uuid.UUID, family string) ([]model.SuratFont, error)
+}
+
+type suratFontRepository struct{ db *gorm.DB }
+
+func NewSuratFontRepository(db *gorm.DB) SuratFontRepository {
+	return &suratFontRepository{db: db}
+}
+
+func (r *suratFontRepository) Save(ctx context.Context, m *model.SuratFont) error {
+	return r.db.WithContext(ctx).Save(m).Error
+}
+
+func (r *suratFontRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.SuratFont{}, id).Error
+}
+
+func (r *suratFontRepository) Get(ctx context.Context, id uint) (*model.SuratFont, error) {
+	var m model.SuratFont
+	if err := r.db.WithContext(ctx).First(&m, id).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *suratFontRepository) Find(ctx context.Context, orgID uuid.UUID, family, style string) (*model.SuratFont, error) {
+	var m model.SuratFont
+	err := r.db.WithContext(ctx).
+		Where("org_id = ? AND LOWER(family) = LOWER(?) AND style = ?", orgID, family, style).
+		First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *suratFontRepository) ListByOrg(ctx context.Context, orgID uuid.UUID) ([]model.SuratFont, error) {
+	var rows []model.SuratFont
+	err := r.db.WithContext(ctx).
+		Where("org_id = ?", orgID).
+		Order("family ASC, style ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+func (r *suratFontRepository) ListFamily(ctx context.Context, orgID uuid.UUID, family string) ([]model.SuratFont, error) {
+	var rows []model.SuratFont
+	err := r.db.WithContext(ctx).
+		Where("org_id = ? AND LOWER(family) = LOWER(?)", orgID, family).
+		Find(&rows).Error
+	return rows, err
+}
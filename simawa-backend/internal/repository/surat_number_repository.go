@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"simawa-backend/internal/model"
+)
+
+type ListSuratNumberQuery struct {
+	OrgID   uuid.UUID
+	Variant string
+	Year    int
+	Page    int
+	Size    int // 0 = tanpa batas (untuk export)
+}
+
+type SuratNumberRepository interface {
+	// Allocate mencadangkan nomor urut berikutnya: menaikkan counter, mengisi
+	// s.Number lewat format, menyimpan surat, dan mencatatnya di register dalam
+	// satu transaksi. Baris counter terkunci sampai commit, jadi format tidak
+	// boleh melakukan I/O (render/upload dilakukan setelah nomor tercadang).
+	Allocate(ctx context.Context, s *model.Surat, year int, issuedBy *uuid.UUID, format func(seq int) string) (*model.SuratNumber, error)
+	// GetBySurat mengembalikan nomor yang sudah dicadangkan untuk surat, atau nil.
+	GetBySurat(ctx context.Context, suratID uint) (*model.SuratNumber, error)
+	List(ctx context.Context, q ListSuratNumberQuery) ([]model.SuratNumber, int64, error)
+}
+
+type suratNumberRepository struct{ db *gorm.DB }
+
+func NewSuratNumberRepository(db *gorm.DB) SuratNumberRepository {
+	return &suratNumberRepository{db: db}
+}
+
+func (r *suratNumberRepository) Allocate(ctx context.Context, s *model.Surat, year int, issuedBy *uuid.UUID, format func(seq int) string) (*model.SuratNumber, error) {
+	if s == nil {
+		return nil, errors.New("surat nil")
+	}
+	var entry *model.SuratNumber
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var seq int
+		if err := tx.Raw(`
+			INSERT INTO surat_number_counters (org_id, variant, year, last_seq, updated_at)
+			VALUES (?, ?, ?, 1, ?)
+			ON CONFLICT (org_id, variant, year)
+			DO UPDATE SET last_seq = surat_number_counters.last_seq + 1, updated_at = EXCLUDED.updated_at
+			RETURNING last_seq`,
+			s.OrgID, s.Variant, year, time.Now(),
+		).Scan(&seq).Error; err != nil {
+			return err
+		}
+		if seq <= 0 {
+			return errors.New("failed to allocate surat number")
+		}
+		s.Number = format(seq)
+		if err := tx.Save(s).Error; err != nil {
+			return err
+		}
+		entry = &model.SuratNumber{
+			OrgID:    s.OrgID,
+			Variant:  s.Variant,
+			Year:     year,
+			Seq:      seq,
+			Number:   s.Number,
+			SuratID:  s.ID,
+			Subject:  s.Subject,
+			IssuedBy: issuedBy,
+		}
+		return tx.Create(entry).Error
+	})
+	if err != nil {
+		s.Number = ""
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (r *suratNumberRepository) GetBySurat(ctx context.Context, suratID uint) (*model.SuratNumber, error) {
+	var row model.SuratNumber
+	err := r.db.WithContext(ctx).Where("surat_id = ?", suratID).Order("id DESC").First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+func (r *suratNumberRepository) List(ctx context.Context, q ListSuratNumberQuery) ([]model.SuratNumber, int64, error) {
+	var rows []model.SuratNumber
+	tx := r.db.WithContext(ctx).Model(&model.SuratNumber{}).Where("org_id = ?", q.OrgID)
+	if q.Variant != "" {
+		tx = tx.Where("variant = ?", q.Variant)
+	}
+	if q.Year > 0 {
+		tx = tx.Where("year = ?", q.Year)
+	}
+
+	var total int64
+	_ = tx.Count(&total).Error
+
+	tx = tx.Order("year DESC, variant ASC, seq DESC")
+	if q.Size > 0 {
+		page := q.Page
+		if page < 1 {
+			page = 1
+		}
+		size := q.Size
+		if size > 100 {
+			size = 100
+		}
+		tx = tx.Limit(size).Offset((page - 1) * size)
+	}
+	if err := tx.Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
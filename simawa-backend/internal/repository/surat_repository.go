@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -22,15 +23,26 @@ type ListSuratQuery struct {
 	ForOrgIDs   []uuid.UUID
 	ForRoles    []string
 	InboxOnly   bool // when true, ForOrgIDs only matches target_org_id (received surat)
-	Page        int
-	Size        int
+	// TembusanOnly limits to surat carbon-copied to TembusanOrgIDs (any org when empty).
+	TembusanOnly   bool
+	TembusanOrgIDs []uuid.UUID
+	Page           int
+	Size           int
 }
 
 type SuratRepository interface {
 	Create(ctx context.Context, m *model.Surat) error
 	Update(ctx context.Context, m *model.Surat) error
 	Get(ctx context.Context, id uint) (*model.Surat, error)
+	GetByVerifyCode(ctx context.Context, code string) (*model.Surat, error)
 	List(ctx context.Context, q ListSuratQuery) ([]model.Surat, int64, error)
+	ListByBatch(ctx context.Context, batchID uint) ([]model.Surat, error)
+	// ListExpired mengembalikan surat final satu varian yang disetujui/dicatat sebelum
+	// batas waktu dan belum ditandai (atau belum diarsipkan bila archive true).
+	ListExpired(ctx context.Context, variant string, before time.Time, archive bool, limit int) ([]model.Surat, error)
+	// WithLock menjalankan fn selama memegang kunci transisi surat id, sehingga
+	// persetujuan/tanda tangan pada surat yang sama berjalan satu per satu.
+	WithLock(ctx context.Context, id uint, fn func() error) error
 }
 
 type suratRepository struct{ db *gorm.DB }
@@ -53,6 +65,51 @@ func (r *suratRepository) Get(ctx context.Context, id uint) (*model.Surat, error
 	return &row, nil
 }
 
+// WithLock memakai advisory lock transaksi, bukan SELECT ... FOR UPDATE: fn menulis
+// baris surat (dan versinya, yang juga mengunci baris surat) lewat koneksi lain,
+// sehingga row lock yang dipegang di sini akan membuat fn menunggu dirinya sendiri.
+func (r *suratRepository) WithLock(ctx context.Context, id uint, fn func() error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('surats'), CAST(? AS integer))", id).Error; err != nil {
+			return err
+		}
+		return fn()
+	})
+}
+
+func (r *suratRepository) GetByVerifyCode(ctx context.Context, code string) (*model.Surat, error) {
+	var row model.Surat
+	if err := r.db.WithContext(ctx).First(&row, "verify_code = ?", code).Error; err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// ListByBatch mengembalikan semua surat satu batch mail-merge sesuai urutan penerima.
+func (r *suratRepository) ListByBatch(ctx context.Context, batchID uint) ([]model.Surat, error) {
+	var rows []model.Surat
+	err := r.db.WithContext(ctx).Where("batch_id = ?", batchID).Order("id ASC").Find(&rows).Error
+	return rows, err
+}
+
+func (r *suratRepository) ListExpired(ctx context.Context, variant string, before time.Time, archive bool, limit int) ([]model.Surat, error) {
+	var rows []model.Surat
+	tx := r.db.WithContext(ctx).
+		Where("variant = ?", variant).
+		Where("status IN ?", []string{model.SuratStatusApproved, model.SuratStatusRejected, model.SuratStatusVoided, model.SuratStatusReceived}).
+		Where("COALESCE(approved_at, created_at) < ?", before)
+	if archive {
+		tx = tx.Where("archived_at IS NULL")
+	} else {
+		tx = tx.Where("expired_at IS NULL")
+	}
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	err := tx.Order("id ASC").Find(&rows).Error
+	return rows, err
+}
+
 func (r *suratRepository) List(ctx context.Context, q ListSuratQuery) ([]model.Surat, int64, error) {
 	var rows []model.Surat
 	tx := r.db.WithContext(ctx).Model(&model.Surat{})
@@ -81,6 +138,13 @@ func (r *suratRepository) List(ctx context.Context, q ListSuratQuery) ([]model.S
 	if q.ToRole != "" {
 		tx = tx.Where("to_role = ?", q.ToRole)
 	}
+	if q.TembusanOnly {
+		if len(q.TembusanOrgIDs) > 0 {
+			tx = tx.Where("id IN (SELECT surat_id FROM surat_tembusans WHERE org_id IN ?)", q.TembusanOrgIDs)
+		} else {
+			tx = tx.Where("id IN (SELECT surat_id FROM surat_tembusans)")
+		}
+	}
 	if len(q.ForOrgIDs) > 0 || len(q.ForRoles) > 0 {
 		tx = tx.Where(func(db *gorm.DB) *gorm.DB {
 			sub := db
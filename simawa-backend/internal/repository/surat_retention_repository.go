@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"simawa-backend/internal/model"
+)
+
+type SuratRetentionRepository interface {
+	List(ctx context.Context) ([]model.SuratRetentionRule, error)
+	GetByVariant(ctx context.Context, variant string) (*model.SuratRetentionRule, error)
+	Save(ctx context.Context, m *model.SuratRetentionRule) error
+	Delete(ctx context.Context, variant string) error
+}
+
+type suratRetentionRepository struct{ db *gorm.DB }
+
+func NewSuratRetentionRepository(db *gorm.DB) SuratRetentionRepository {
+	return &suratRetentionRepository{db: db}
+}
+
+func (r *suratRetentionRepository) List(ctx context.Context) ([]model.SuratRetentionRule, error) {
+	var rows []model.SuratRetentionRule
+	err := r.db.WithContext(ctx).Order("variant ASC").Find(&rows).Error
+	return rows, err
+}
+
+func (r *suratRetentionRepository) GetByVariant(ctx context.Context, variant string) (*model.SuratRetentionRule, error) {
+	var m model.SuratRetentionRule
+	if err := r.db.WithContext(ctx).Where("variant = ?", variant).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *suratRetentionRepository) Save(ctx context.Context, m *model.SuratRetentionRule) error {
+	return r.db.WithContext(ctx).Save(m).Error
+}
+
+func (r *suratRetentionRepository) Delete(ctx context.Context, variant string) error {
+	return r.db.WithContext(ctx).Where("variant = ?", variant).Delete(&model.SuratRetentionRule{}).Error
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"simawa-backend/internal/model"
+)
+
+type SuratSignatureRepository interface {
+	Replace(ctx context.Context, suratID uint, rows []model.SuratSignature) error
+	Update(ctx context.Context, m *model.SuratSignature) error
+	ListBySurat(ctx context.Context, suratID uint) ([]model.SuratSignature, error)
+	ListCurrentForUser(ctx context.Context, userID uuid.UUID) ([]model.SuratSignature, error)
+	ListStale(ctx context.Context, before time.Time) ([]model.SuratSignature, error)
+}
+
+type suratSignatureRepository struct{ db *gorm.DB }
+
+func NewSuratSignatureRepository(db *gorm.DB) SuratSignatureRepository {
+	return &suratSignatureRepository{db: db}
+}
+
+// currentStep membatasi ke langkah yang sedang berjalan pada surat PENDING.
+const currentStep = `surat_signatures.status = 'PENDING'
+	AND surat_signatures.step = (SELECT MIN(s2.step) FROM surat_signatures s2 WHERE s2.surat_id = surat_signatures.surat_id AND s2.status = 'PENDING')
+	AND surat_signatures.surat_id IN (SELECT id FROM surats WHERE status = 'PENDING')`
+
+// Replace mengganti seluruh langkah tanda tangan surat dalam satu transaksi.
+func (r *suratSignatureRepository) Replace(ctx context.Context, suratID uint, rows []model.SuratSignature) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("surat_id = ?", suratID).Delete(&model.SuratSignature{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+func (r *suratSignatureRepository) Update(ctx context.Context, m *model.SuratSignature) error {
+	return r.db.WithContext(ctx).Omit("Surat", "User").Save(m).Error
+}
+
+func (r *suratSignatureRepository) ListBySurat(ctx context.Context, suratID uint) ([]model.SuratSignature, error) {
+	var rows []model.SuratSignature
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("surat_id = ?", suratID).
+		Order("step ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+// ListCurrentForUser mengembalikan surat yang sedang menunggu tanda tangan user.
+func (r *suratSignatureRepository) ListCurrentForUser(ctx context.Context, userID uuid.UUID) ([]model.SuratSignature, error) {
+	var rows []model.SuratSignature
+	err := r.db.WithContext(ctx).
+		Preload("Surat").
+		Where("surat_signatures.user_id = ?", userID).
+		Where(currentStep).
+		Order("surat_signatures.updated_at ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+// ListStale mengembalikan langkah berjalan yang belum diingatkan sejak before.
+func (r *suratSignatureRepository) ListStale(ctx context.Context, before time.Time) ([]model.SuratSignature, error) {
+	var rows []model.SuratSignature
+	err := r.db.WithContext(ctx).
+		Preload("Surat").
+		Where(currentStep).
+		Where("COALESCE(surat_signatures.reminded_at, surat_signatures.updated_at) < ?", before).
+		Find(&rows).Error
+	return rows, err
+}
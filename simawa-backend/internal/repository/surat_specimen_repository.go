@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"simawa-backend/internal/model"
+)
+
+type SuratSpecimenRepository interface {
+	Save(ctx context.Context, m *model.SuratSpecimen) error
+	Delete(ctx context.Context, id uint) error
+	GetSignature(ctx context.Context, userID uuid.UUID) (*model.SuratSpecimen, error)
+	GetStamp(ctx context.Context, orgID uuid.UUID) (*model.SuratSpecimen, error)
+}
+
+type suratSpecimenRepository struct{ db *gorm.DB }
+
+func NewSuratSpecimenRepository(db *gorm.DB) SuratSpecimenRepository {
+	return &suratSpecimenRepository{db: db}
+}
+
+func (r *suratSpecimenRepository) Save(ctx context.Context, m *model.SuratSpecimen) error {
+	return r.db.WithContext(ctx).Save(m).Error
+}
+
+func (r *suratSpecimenRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.SuratSpecimen{}, id).Error
+}
+
+func (r *suratSpecimenRepository) GetSignature(ctx context.Context, userID uuid.UUID) (*model.SuratSpecimen, error) {
+	var m model.SuratSpecimen
+	err := r.db.WithContext(ctx).
+		Where("kind = ? AND user_id = ?", model.SuratSpecimenSignature, userID).
+		First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *suratSpecimenRepository) GetStamp(ctx context.Context, orgID uuid.UUID) (*model.SuratSpecimen, error) {
+	var m model.SuratSpecimen
+	err := r.db.WithContext(ctx).
+		Where("kind = ? AND org_id = ?", model.SuratSpecimenStamp, orgID).
+		First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
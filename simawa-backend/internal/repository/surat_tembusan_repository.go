@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"simawa-backend/internal/model"
+)
+
+type SuratTembusanRepository interface {
+	Replace(ctx context.Context, suratID uint, orgIDs []uuid.UUID) error
+	ListBySurat(ctx context.Context, suratID uint) ([]model.SuratTembusan, error)
+	IsRecipient(ctx context.Context, suratID uint, orgIDs []uuid.UUID) (bool, error)
+}
+
+type suratTembusanRepository struct{ db *gorm.DB }
+
+func NewSuratTembusanRepository(db *gorm.DB) SuratTembusanRepository {
+	return &suratTembusanRepository{db: db}
+}
+
+// Replace mengganti seluruh organisasi tembusan sebuah surat dalam satu transaksi.
+func (r *suratTembusanRepository) Replace(ctx context.Context, suratID uint, orgIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("surat_id = ?", suratID).Delete(&model.SuratTembusan{}).Error; err != nil {
+			return err
+		}
+		if len(orgIDs) == 0 {
+			return nil
+		}
+		rows := make([]model.SuratTembusan, 0, len(orgIDs))
+		for _, id := range orgIDs {
+			rows = append(rows, model.SuratTembusan{SuratID: suratID, OrgID: id})
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+func (r *suratTembusanRepository) ListBySurat(ctx context.Context, suratID uint) ([]model.SuratTembusan, error) {
+	var rows []model.SuratTembusan
+	err := r.db.WithContext(ctx).
+		Preload("Org").
+		Where("surat_id = ?", suratID).
+		Order("id ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+func (r *suratTembusanRepository) IsRecipient(ctx context.Context, suratID uint, orgIDs []uuid.UUID) (bool, error) {
+	if len(orgIDs) == 0 {
+		return false, nil
+	}
+	var n int64
+	err := r.db.WithContext(ctx).Model(&model.SuratTembusan{}).
+		Where("surat_id = ? AND org_id IN ?", suratID, orgIDs).
+		Count(&n).Error
+	return n > 0, err
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"simawa-backend/internal/model"
+)
+
+type SuratTemplateRepository interface {
+	Create(ctx context.Context, t *model.SuratTemplate) error
+	Update(ctx context.Context, t *model.SuratTemplate) error
+	Delete(ctx context.Context, id uint) error
+	Get(ctx context.Context, id uint) (*model.SuratTemplate, error)
+	// List mengembalikan template global ditambah template milik orgID (jika diisi).
+	List(ctx context.Context, orgID *uuid.UUID, variant string) ([]model.SuratTemplate, error)
+}
+
+type suratTemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewSuratTemplateRepository(db *gorm.DB) SuratTemplateRepository {
+	return &suratTemplateRepository{db: db}
+}
+
+func (r *suratTemplateRepository) Create(ctx context.Context, t *model.SuratTemplate) error {
+	return r.db.WithContext(ctx).Create(t).Error
+}
+
+func (r *suratTemplateRepository) Update(ctx context.Context, t *model.SuratTemplate) error {
+	return r.db.WithContext(ctx).Save(t).Error
+}
+
+func (r *suratTemplateRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.SuratTemplate{}, id).Error
+}
+
+func (r *suratTemplateRepository) Get(ctx context.Context, id uint) (*model.SuratTemplate, error) {
+	var t model.SuratTemplate
+	if err := r.db.WithContext(ctx).First(&t, id).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *suratTemplateRepository) List(ctx context.Context, orgID *uuid.UUID, variant string) ([]model.SuratTemplate, error) {
+	var rows []model.SuratTemplate
+	db := r.db.WithContext(ctx).Model(&model.SuratTemplate{})
+	if orgID != nil {
+		db = db.Where("org_id IS NULL OR org_id = ?", *orgID)
+	} else {
+		db = db.Where("org_id IS NULL")
+	}
+	if v := strings.TrimSpace(variant); v != "" {
+		db = db.Where("variant = ?", strings.ToUpper(v))
+	}
+	err := db.Order("org_id NULLS FIRST, name ASC").Find(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"simawa-backend/internal/model"
+)
+
+type SuratVersionRepository interface {
+	// Create memberi nomor versi berikutnya untuk surat tersebut lalu menyimpannya.
+	// Baris surat dikunci selama transaksi agar dua versi tidak mendapat nomor sama.
+	Create(ctx context.Context, v *model.SuratVersion) error
+	Latest(ctx context.Context, suratID uint) (*model.SuratVersion, error)
+	Get(ctx context.Context, suratID uint, version int) (*model.SuratVersion, error)
+	List(ctx context.Context, suratID uint) ([]model.SuratVersion, error)
+	// ReplaceFileKey memindahkan referensi berkas versi (mis. saat PDF dipindah ke cold storage).
+	ReplaceFileKey(ctx context.Context, suratID uint, oldKey, newKey string) error
+}
+
+type suratVersionRepository struct{ db *gorm.DB }
+
+func NewSuratVersionRepository(db *gorm.DB) SuratVersionRepository {
+	return &suratVersionRepository{db: db}
+}
+
+func (r *suratVersionRepository) Create(ctx context.Context, v *model.SuratVersion) error {
+	if v == nil || v.SuratID == 0 {
+		return errors.New("surat version requires surat_id")
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked model.Surat
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, v.SuratID).Error; err != nil {
+			return err
+		}
+		var last int
+		if err := tx.Model(&model.SuratVersion{}).Where("surat_id = ?", v.SuratID).
+			Select("COALESCE(MAX(version), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+		v.Version = last + 1
+		return tx.Create(v).Error
+	})
+}
+
+func (r *suratVersionRepository) Latest(ctx context.Context, suratID uint) (*model.SuratVersion, error) {
+	var v model.SuratVersion
+	if err := r.db.WithContext(ctx).Where("surat_id = ?", suratID).Order("version DESC").First(&v).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *suratVersionRepository) Get(ctx context.Context, suratID uint, version int) (*model.SuratVersion, error) {
+	var v model.SuratVersion
+	if err := r.db.WithContext(ctx).Where("surat_id = ? AND version = ?", suratID, version).First(&v).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *suratVersionRepository) List(ctx context.Context, suratID uint) ([]model.SuratVersion, error) {
+	var rows []model.SuratVersion
+	// payload bisa besar (logo/ttd base64), jadi tidak ikut di daftar
+	err := r.db.WithContext(ctx).Omit("payload", "theme").Where("surat_id = ?", suratID).Order("version DESC").Find(&rows).Error
+	return rows, err
+}
+
+func (r *suratVersionRepository) ReplaceFileKey(ctx context.Context, suratID uint, oldKey, newKey string) error {
+	return r.db.WithContext(ctx).Model(&model.SuratVersion{}).
+		Where("surat_id = ? AND file_key = ?", suratID, oldKey).
+		Update("file_key", newKey).Error
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"simawa-backend/internal/model"
+)
+
+type VenueRepository interface {
+	Create(ctx context.Context, v *model.Venue) error
+	Update(ctx context.Context, v *model.Venue) error
+	Delete(ctx context.Context, id uint) error
+	Get(ctx context.Context, id uint) (*model.Venue, error)
+	List(ctx context.Context, activeOnly bool) ([]model.Venue, error)
+	CountActivities(ctx context.Context, id uint) (int64, error)
+	// WithLock menjalankan fn selama memegang kunci jadwal ruangan id, sehingga
+	// pengecekan bentrok dan penyimpanan jadwal pada ruangan yang sama berurutan.
+	WithLock(ctx context.Context, id uint, fn func() error) error
+}
+
+type venueRepository struct{ db *gorm.DB }
+
+func NewVenueRepository(db *gorm.DB) VenueRepository {
+	return &venueRepository{db: db}
+}
+
+func (r *venueRepository) Create(ctx context.Context, v *model.Venue) error {
+	return r.db.WithContext(ctx).Create(v).Error
+}
+
+func (r *venueRepository) Update(ctx context.Context, v *model.Venue) error {
+	return r.db.WithContext(ctx).Save(v).Error
+}
+
+func (r *venueRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.Venue{}, id).Error
+}
+
+func (r *venueRepository) Get(ctx context.Context, id uint) (*model.Venue, error) {
+	var v model.Venue
+	if err := r.db.WithContext(ctx).First(&v, id).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *venueRepository) List(ctx context.Context, activeOnly bool) ([]model.Venue, error) {
+	var rows []model.Venue
+	tx := r.db.WithContext(ctx).Order("name ASC")
+	if activeOnly {
+		tx = tx.Where("active = ?", true)
+	}
+	err := tx.Find(&rows).Error
+	return rows, err
+}
+
+// CountActivities menghitung kegiatan yang pernah memakai ruangan ini.
+func (r *venueRepository) CountActivities(ctx context.Context, id uint) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&model.Activity{}).Where("venue_id = ?", id).Count(&n).Error
+	return n, err
+}
+
+// WithLock memakai advisory lock transaksi seperti SuratRepository.WithLock: fn
+// membaca dan menulis kegiatan lewat koneksi lain, bukan lewat tx ini.
+func (r *venueRepository) WithLock(ctx context.Context, id uint, fn func() error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('venues'), CAST(? AS integer))", id).Error; err != nil {
+			return err
+		}
+		return fn()
+	})
+}
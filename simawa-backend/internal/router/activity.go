@@ -27,5 +27,8 @@ func RegisterActivityRoutes(r *gin.Engine, cfg *config.Env, ah *handler.Activity
 	api.POST("/:id/revision", middleware.RequireRoles(rbac, model.RoleAdmin, model.RoleOrgAdmin, model.RoleBEMAdmin), ah.Revision) // BEM only (not DEMA)
 	api.POST("/:id/gallery", middleware.RequireRoles(rbac, model.RoleAdmin, model.RoleOrgAdmin), ah.AddGalleryPhoto) // Upload Photo
 	api.DELETE("/:id/gallery", middleware.RequireRoles(rbac, model.RoleAdmin, model.RoleOrgAdmin), ah.RemoveGalleryPhoto) // Remove Photo
+	api.GET("/:id/venue-conflicts", middleware.RequireRoles(rbac, model.RoleAdmin, model.RoleOrgAdmin, model.RoleBEMAdmin), ah.VenueConflicts)
+	api.PUT("/:id/occurrences/:start", middleware.RequireRoles(rbac, model.RoleAdmin, model.RoleOrgAdmin), ah.UpdateOccurrence)
+	api.DELETE("/:id/occurrences/:start", middleware.RequireRoles(rbac, model.RoleAdmin, model.RoleOrgAdmin), ah.CancelOccurrence)
 	api.GET("/org/:org_id", middleware.RequireRoles(rbac, model.RoleAdmin, model.RoleOrgAdmin, model.RoleBEMAdmin, model.RoleDEMAAdmin), ah.ListByOrg)
 }
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"simawa-backend/internal/config"
+	"simawa-backend/internal/handler"
+	"simawa-backend/internal/middleware"
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/service"
+)
+
+// RegisterAttendanceRoutes: presensi kegiatan via QR. Penyelenggara menampilkan QR
+// kegiatan yang berganti berkala atau memindai QR pribadi peserta.
+func RegisterAttendanceRoutes(r *gin.Engine, cfg *config.Env, h *handler.AttendanceHandler, rbac *service.RBACService) {
+	me := r.Group("/v1/attendance")
+	me.Use(middleware.AuthJWT(cfg))
+	me.GET("/me/qr", h.MyQR)
+	me.POST("/check-in", h.CheckIn)
+
+	api := r.Group("/v1/activities")
+	api.Use(middleware.AuthJWT(cfg), middleware.RequireRoles(rbac, model.RoleAdmin, model.RoleOrgAdmin))
+	api.GET("/:id/attendance", h.List)
+	api.GET("/:id/attendance/qr", h.ActivityQR)
+	api.POST("/:id/attendance/scan", h.Scan)
+	api.GET("/:id/attendance/summary", h.Overview)
+	api.GET("/:id/attendance/export", h.Export)
+}
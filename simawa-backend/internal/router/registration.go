@@ -0,0 +1,30 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"simawa-backend/internal/config"
+	"simawa-backend/internal/handler"
+	"simawa-backend/internal/middleware"
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/service"
+)
+
+// RegisterRegistrationRoutes: pendaftaran peserta kegiatan publik. Pendaftar tanpa
+// akun memakai token dari email untuk mengonfirmasi, melihat atau membatalkan
+// pendaftarannya.
+func RegisterRegistrationRoutes(r *gin.Engine, cfg *config.Env, h *handler.RegistrationHandler, rbac *service.RBACService) {
+	pub := r.Group("/public")
+	pub.GET("/activities/:id/registration", h.Form)
+	pub.POST("/activities/:id/register", h.Register)
+	pub.GET("/registrations/:token", h.Status)
+	pub.POST("/registrations/:token/confirm", h.ConfirmByToken)
+	pub.POST("/registrations/:token/cancel", h.CancelByToken)
+
+	api := r.Group("/v1/activities")
+	api.Use(middleware.AuthJWT(cfg))
+	api.POST("/:id/register", h.RegisterMe)
+	api.PUT("/:id/registration", middleware.RequireRoles(rbac, model.RoleAdmin, model.RoleOrgAdmin), h.SaveForm)
+	api.GET("/:id/registrations", middleware.RequireRoles(rbac, model.RoleAdmin, model.RoleOrgAdmin), h.List)
+	api.GET("/:id/registrations/export", middleware.RequireRoles(rbac, model.RoleAdmin, model.RoleOrgAdmin), h.Export)
+	api.DELETE("/:id/registrations/:reg_id", middleware.RequireRoles(rbac, model.RoleAdmin, model.RoleOrgAdmin), h.Cancel)
+}
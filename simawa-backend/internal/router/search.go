@@ -0,0 +1,15 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"simawa-backend/internal/config"
+	"simawa-backend/internal/handler"
+	"simawa-backend/internal/middleware"
+)
+
+// RegisterSearchRoutes: scope hasil diatur SearchService sesuai role pemanggil.
+func RegisterSearchRoutes(r *gin.Engine, cfg *config.Env, h *handler.SearchHandler) {
+	api := r.Group("/v1/search")
+	api.Use(middleware.AuthJWT(cfg))
+	api.GET("", h.Search)
+}
@@ -8,9 +8,14 @@ import (
 	"simawa-backend/internal/middleware"
 	"simawa-backend/internal/model"
 	"simawa-backend/internal/service"
+	"simawa-backend/internal/util/suratpdf"
 )
 
 func RegisterSuratRoutes(r *gin.Engine, cfg *config.Env, sh *handler.SuratHandler, rbac *service.RBACService) {
+	pub := r.Group("/public/surat")
+	pub.GET("/verify/:code", sh.Verify)
+	pub.GET("/signing-key", sh.SigningKey)
+
 	api := r.Group("/v1/surat")
 	api.Use(middleware.AuthJWT(cfg))
 
@@ -25,12 +30,68 @@ func RegisterSuratRoutes(r *gin.Engine, cfg *config.Env, sh *handler.SuratHandle
 	api.POST("/upload", middleware.RequireRoles(rbac, manageRoles...), sh.Upload)
 	api.POST("/preview", middleware.RequireRoles(rbac, manageRoles...), sh.Generate)
 	api.POST("/:id/submit", middleware.RequireRoles(rbac, manageRoles...), sh.Submit)
+	api.POST("/:id/resubmit", middleware.RequireRoles(rbac, manageRoles...), sh.Resubmit)
 	api.POST("/:id/approve", middleware.RequireRoles(rbac, approveRoles...), sh.Approve) // BEM only
 	api.POST("/:id/revise", middleware.RequireRoles(rbac, approveRoles...), sh.Revise) // BEM only
+	api.POST("/:id/withdraw", middleware.RequireRoles(rbac, manageRoles...), sh.Withdraw)
+	api.POST("/:id/void", middleware.RequireRoles(rbac, manageRoles...), sh.Void)
 	api.GET("/outbox/:org_id", middleware.RequireRoles(rbac, viewRoles...), sh.ListOutbox)
 	api.GET("/inbox", middleware.RequireRoles(rbac, viewRoles...), sh.ListInbox)
 	api.GET("/archive", middleware.RequireRoles(rbac, viewRoles...), sh.ListArchive)
+	api.GET("/archive/:org_id/export", middleware.RequireRoles(rbac, manageRoles...), sh.ExportArchive)
+	// Aturan retensi arsip berlaku global, jadi hanya admin sistem.
+	api.GET("/retention", middleware.RequireRoles(rbac, model.RoleAdmin), sh.ListRetentionRules)
+	api.PUT("/retention/:variant", middleware.RequireRoles(rbac, model.RoleAdmin), sh.SaveRetentionRule)
+	api.DELETE("/retention/:variant", middleware.RequireRoles(rbac, model.RoleAdmin), sh.DeleteRetentionRule)
+	api.POST("/retention/run", middleware.RequireRoles(rbac, model.RoleAdmin), sh.RunRetention)
+	api.GET("/register/:org_id", middleware.RequireRoles(rbac, manageRoles...), sh.ListNumberRegister)
+	api.GET("/register/:org_id/export", middleware.RequireRoles(rbac, manageRoles...), sh.ExportNumberRegister)
+	api.POST("/batch", middleware.RequireRoles(rbac, manageRoles...), sh.CreateBatch)
+	api.GET("/batch/:bid", middleware.RequireRoles(rbac, manageRoles...), sh.GetBatch)
+	api.POST("/batch/:bid/submit", middleware.RequireRoles(rbac, manageRoles...), sh.SubmitBatch)
+	api.GET("/batch/:bid/download", middleware.RequireRoles(rbac, manageRoles...), sh.DownloadBatch)
+	api.POST("/masuk", middleware.RequireRoles(rbac, manageRoles...), middleware.MaxFileSizeMiddleware(15<<20), middleware.ValidateFileType(handler.SuratMasukTypes), sh.RegisterIncoming)
+	api.GET("/agenda/:org_id", middleware.RequireRoles(rbac, manageRoles...), sh.ListAgenda)
+	api.GET("/agenda/:org_id/export", middleware.RequireRoles(rbac, manageRoles...), sh.ExportAgenda)
+	// Spesimen TTD milik sendiri (setiap penanda tangan), cap milik organisasi yang dikelola.
+	api.GET("/specimen/signature", sh.GetSignatureSpecimen)
+	api.PUT("/specimen/signature", sh.UploadSignatureSpecimen)
+	api.DELETE("/specimen/signature", sh.DeleteSignatureSpecimen)
+	api.GET("/specimen/stamp/:org_id", middleware.RequireRoles(rbac, manageRoles...), sh.GetStampSpecimen)
+	api.PUT("/specimen/stamp/:org_id", middleware.RequireRoles(rbac, manageRoles...), sh.UploadStampSpecimen)
+	api.DELETE("/specimen/stamp/:org_id", middleware.RequireRoles(rbac, manageRoles...), sh.DeleteStampSpecimen)
+	api.GET("/fonts/:org_id", middleware.RequireRoles(rbac, manageRoles...), sh.ListFonts)
+	api.POST("/fonts/:org_id", middleware.RequireRoles(rbac, manageRoles...), sh.UploadFont)
+	api.DELETE("/fonts/:org_id/:font_id", middleware.RequireRoles(rbac, manageRoles...), sh.DeleteFont)
+	api.GET("/templates", middleware.RequireRoles(rbac, viewRoles...), sh.ListTemplates)
+	api.GET("/templates/:tid", middleware.RequireRoles(rbac, viewRoles...), sh.GetTemplate)
+	api.POST("/templates", middleware.RequireRoles(rbac, manageRoles...), sh.CreateTemplate)
+	api.PUT("/templates/:tid", middleware.RequireRoles(rbac, manageRoles...), sh.UpdateTemplate)
+	api.DELETE("/templates/:tid", middleware.RequireRoles(rbac, manageRoles...), sh.DeleteTemplate)
 	api.GET("", middleware.RequireRoles(rbac, viewRoles...), sh.List)
 	api.GET("/:id", middleware.RequireRoles(rbac, viewRoles...), sh.Get)
 	api.GET("/:id/download", middleware.RequireRoles(rbac, viewRoles...), sh.Download)
+	api.POST("/:id/validate", middleware.RequireRoles(rbac, viewRoles...), sh.ValidateSignature)
+	api.GET("/:id/versions", middleware.RequireRoles(rbac, viewRoles...), sh.ListVersions)
+	api.GET("/:id/versions/diff", middleware.RequireRoles(rbac, viewRoles...), sh.DiffVersions)
+	api.GET("/:id/versions/:version/download", middleware.RequireRoles(rbac, viewRoles...), sh.DownloadVersion)
+	api.GET("/:id/lampiran", middleware.RequireRoles(rbac, viewRoles...), sh.ListAttachments)
+	api.GET("/:id/lampiran/bundle", middleware.RequireRoles(rbac, viewRoles...), sh.DownloadBundle)
+	api.POST("/:id/lampiran", middleware.RequireRoles(rbac, manageRoles...), middleware.MaxFileSizeMiddleware(50<<20), middleware.ValidateFileType(suratpdf.AttachmentTypes), sh.AddAttachments)
+	api.DELETE("/:id/lampiran/:aid", middleware.RequireRoles(rbac, manageRoles...), sh.DeleteAttachment)
+	api.PUT("/:id/emails", middleware.RequireRoles(rbac, manageRoles...), sh.SetEmails)
+	api.GET("/:id/deliveries", middleware.RequireRoles(rbac, viewRoles...), sh.ListDeliveries)
+	api.POST("/:id/deliveries/retry", middleware.RequireRoles(rbac, manageRoles...), sh.RetryDelivery)
+	api.GET("/:id/tembusan", middleware.RequireRoles(rbac, viewRoles...), sh.ListTembusan)
+	api.PUT("/:id/tembusan", middleware.RequireRoles(rbac, manageRoles...), sh.SetTembusan)
+	api.GET("/:id/signatures", middleware.RequireRoles(rbac, viewRoles...), sh.SigningStatus)
+	api.POST("/:id/signatures/remind", middleware.RequireRoles(rbac, manageRoles...), sh.RemindSigner)
+	// Penanda tangan bisa anggota biasa; giliran dan identitasnya dicek di service.
+	api.POST("/:id/sign", sh.Sign)
+	api.GET("/signatures/mine", sh.ListMySignatures)
+	// Disposisi: penerima disposisi biasanya anggota biasa, jadi izinnya dicek di handler.
+	api.POST("/:id/disposisi", sh.Dispose)
+	api.GET("/:id/disposisi", sh.ListDispositions)
+	api.GET("/disposisi/mine", sh.ListMyDispositions)
+	api.POST("/disposisi/:did/done", sh.CompleteDisposition)
 }
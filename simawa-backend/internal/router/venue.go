@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"simawa-backend/internal/config"
+	"simawa-backend/internal/handler"
+	"simawa-backend/internal/middleware"
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/service"
+)
+
+// RegisterVenueRoutes: katalog ruangan dikelola admin; jadwal bisa dilihat semua pengguna.
+func RegisterVenueRoutes(r *gin.Engine, cfg *config.Env, h *handler.VenueHandler, rbac *service.RBACService) {
+	r.GET("/public/venues/:id/calendar.ics", h.ICS)
+
+	api := r.Group("/v1/venues")
+	api.Use(middleware.AuthJWT(cfg))
+	api.GET("", h.List)
+	api.GET("/:id", h.Get)
+	api.GET("/:id/availability", h.Availability)
+	api.POST("", middleware.RequireRoles(rbac, model.RoleAdmin), h.Create)
+	api.PUT("/:id", middleware.RequireRoles(rbac, model.RoleAdmin), h.Update)
+	api.DELETE("/:id", middleware.RequireRoles(rbac, model.RoleAdmin), h.Delete)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
@@ -21,6 +22,8 @@ import (
 	"simawa-backend/internal/repository"
 	"simawa-backend/internal/router"
 	"simawa-backend/internal/service"
+	"simawa-backend/internal/util/checkin"
+	"simawa-backend/internal/util/suratsign"
 )
 
 type Server struct {
@@ -28,61 +31,87 @@ type Server struct {
 	DB        *gorm.DB
 	Minio     *minio.Client
 	Redis     *redis.Client
+	Signer    *suratsign.Keystore
 	Engine    *gin.Engine
 	StartTime time.Time
 
 	Repositories struct {
-		User         repository.UserRepository
-		UserRole     repository.UserRoleRepository
-		RefreshToken repository.RefreshTokenRepository
-		OTP          repository.OTPRepository
-		Surat        repository.SuratRepository
-		Org          repository.OrganizationRepository
-		Activity     repository.ActivityRepository
-		LPJ          repository.LPJRepository
-		OrgMember    repository.OrgMemberRepository
-		OrgJoinReq   repository.OrgJoinRequestRepository
-		Notify       repository.NotificationRepository
-		ActHistory   repository.ActivityHistoryRepository
-		Audit        repository.AuditLogRepository
-		LPJHistory   repository.LPJHistoryRepository
-		Asset        repository.AssetRepository
-		AssetBorrow  repository.AssetBorrowingRepository
+		User             repository.UserRepository
+		UserRole         repository.UserRoleRepository
+		RefreshToken     repository.RefreshTokenRepository
+		OTP              repository.OTPRepository
+		Surat            repository.SuratRepository
+		SuratNumber      repository.SuratNumberRepository
+		SuratTemplate    repository.SuratTemplateRepository
+		SuratVersion     repository.SuratVersionRepository
+		SuratAttachment  repository.SuratAttachmentRepository
+		SuratDisposition repository.SuratDispositionRepository
+		SuratBatch       repository.SuratBatchRepository
+		SuratAgenda      repository.SuratAgendaRepository
+		SuratDelivery    repository.SuratDeliveryRepository
+		SuratTembusan    repository.SuratTembusanRepository
+		SuratSpecimen    repository.SuratSpecimenRepository
+		SuratSignature   repository.SuratSignatureRepository
+		SuratFont        repository.SuratFontRepository
+		SuratRetention   repository.SuratRetentionRepository
+		Org              repository.OrganizationRepository
+		Activity         repository.ActivityRepository
+		LPJ              repository.LPJRepository
+		OrgMember        repository.OrgMemberRepository
+		OrgJoinReq       repository.OrgJoinRequestRepository
+		Notify           repository.NotificationRepository
+		ActHistory       repository.ActivityHistoryRepository
+		Audit            repository.AuditLogRepository
+		LPJHistory       repository.LPJHistoryRepository
+		Asset            repository.AssetRepository
+		AssetBorrow      repository.AssetBorrowingRepository
+		Search           repository.SearchRepository
+		Venue            repository.VenueRepository
+		Registration     repository.ActivityRegistrationRepository
+		Attendance       repository.ActivityAttendanceRepository
 	}
 
 	Services struct {
-		User      *service.UserService
-		RBAC      *service.RBACService
-		Auth      *service.AuthService
-		Surat     service.SuratService
-		Org       *service.OrganizationService
-		Activity  *service.ActivityService
-		LPJ       *service.LPJService
-		Member    *service.OrgMemberService
-		JoinReq   *service.OrgJoinRequestService
-		Notify    *service.NotificationService
-		Dashboard *service.DashboardService
-		Audit     *service.AuditService
-		Captcha   *service.CaptchaService
-		Report    *service.ReportService
-		Asset     *service.AssetService
+		User         *service.UserService
+		RBAC         *service.RBACService
+		Auth         *service.AuthService
+		Surat        service.SuratService
+		Org          *service.OrganizationService
+		Activity     *service.ActivityService
+		LPJ          *service.LPJService
+		Member       *service.OrgMemberService
+		JoinReq      *service.OrgJoinRequestService
+		Notify       *service.NotificationService
+		Dashboard    *service.DashboardService
+		Audit        *service.AuditService
+		Captcha      *service.CaptchaService
+		Report       *service.ReportService
+		Asset        *service.AssetService
+		Search       *service.SearchService
+		Venue        *service.VenueService
+		Registration *service.RegistrationService
+		Attendance   *service.AttendanceService
 	}
 
 	Handlers struct {
-		User      *handler.UserHandler
-		Auth      *handler.AuthHandler
-		Surat     *handler.SuratHandler
-		Org       *handler.OrganizationHandler
-		Activity  *handler.ActivityHandler
-		LPJ       *handler.LPJHandler
-		Member    *handler.OrgMemberHandler
-		JoinReq   *handler.OrgJoinRequestHandler
-		Notify    *handler.NotificationHandler
-		Dashboard *handler.DashboardHandler
-		Health    *handler.HealthHandler
-		Audit     *handler.AuditLogHandler
-		Report    *handler.ReportHandler
-		Asset     *handler.AssetHandler
+		User         *handler.UserHandler
+		Auth         *handler.AuthHandler
+		Surat        *handler.SuratHandler
+		Org          *handler.OrganizationHandler
+		Activity     *handler.ActivityHandler
+		LPJ          *handler.LPJHandler
+		Member       *handler.OrgMemberHandler
+		JoinReq      *handler.OrgJoinRequestHandler
+		Notify       *handler.NotificationHandler
+		Dashboard    *handler.DashboardHandler
+		Health       *handler.HealthHandler
+		Audit        *handler.AuditLogHandler
+		Report       *handler.ReportHandler
+		Asset        *handler.AssetHandler
+		Search       *handler.SearchHandler
+		Venue        *handler.VenueHandler
+		Registration *handler.RegistrationHandler
+		Attendance   *handler.AttendanceHandler
 	}
 }
 
@@ -98,6 +127,9 @@ func NewServer(cfg *config.Env) (*Server, error) {
 	s.initMinio()
 	s.initRedis()
 	s.initRepositories()
+	if err := s.initSigner(); err != nil {
+		return nil, err
+	}
 	s.initServices()
 	s.initHandlers()
 	s.initRouter()
@@ -133,11 +165,37 @@ func (s *Server) autoMigrate() error {
 		&model.OTP{},
 		&model.Asset{},
 		&model.AssetBorrowing{},
+		&model.SuratNumberCounter{},
+		&model.SuratNumber{},
+		&model.SuratTemplate{},
+		&model.SuratVersion{},
+		&model.SuratAttachment{},
+		&model.SuratDisposition{},
+		&model.SuratBatch{},
+		&model.SuratAgenda{},
+		&model.SuratDelivery{},
+		&model.SuratTembusan{},
+		&model.SuratSpecimen{},
+		&model.SuratSignature{},
+		&model.SuratFont{},
+		&model.SuratRetentionRule{},
+		&model.Venue{},
+		&model.ActivityRegistrationForm{},
+		&model.ActivityRegistration{},
+		&model.ActivityAttendance{},
 	); err != nil {
 		return err
 	}
+	// Surat masuk lama tersimpan dengan org_id kosong; pakai organisasi penerima.
+	if err := s.DB.Exec("UPDATE surats SET org_id = target_org_id WHERE source = ? AND org_id = ? AND target_org_id IS NOT NULL",
+		model.SuratSourceExternal, uuid.Nil).Error; err != nil {
+		return err
+	}
 	// Create performance indexes
-	return database.CreateIndexes(s.DB)
+	if err := database.CreateIndexes(s.DB); err != nil {
+		return err
+	}
+	return database.CreateSearchIndexes(s.DB)
 }
 
 func (s *Server) initMinio() {
@@ -155,11 +213,37 @@ func (s *Server) initRedis() {
 	s.Redis = redisInfra.NewClient(&s.Config.Redis)
 }
 
+func (s *Server) initSigner() error {
+	// optional; empty key path disables surat signing
+	if s.Config.Surat.SignKeyPath == "" {
+		return nil
+	}
+	ks, err := suratsign.LoadOrCreate(s.Config.Surat.SignKeyPath, s.Config.Surat.SignKeyID)
+	if err != nil {
+		return fmt.Errorf("init surat signer: %w", err)
+	}
+	s.Signer = ks
+	return nil
+}
+
 func (s *Server) initRepositories() {
 	s.Repositories.User = repository.NewUserRepository(s.DB)
 	s.Repositories.UserRole = repository.NewUserRoleRepository(s.DB)
 	s.Repositories.RefreshToken = repository.NewRefreshTokenRepository(s.DB)
 	s.Repositories.Surat = repository.NewSuratRepository(s.DB)
+	s.Repositories.SuratNumber = repository.NewSuratNumberRepository(s.DB)
+	s.Repositories.SuratTemplate = repository.NewSuratTemplateRepository(s.DB)
+	s.Repositories.SuratVersion = repository.NewSuratVersionRepository(s.DB)
+	s.Repositories.SuratAttachment = repository.NewSuratAttachmentRepository(s.DB)
+	s.Repositories.SuratDisposition = repository.NewSuratDispositionRepository(s.DB)
+	s.Repositories.SuratBatch = repository.NewSuratBatchRepository(s.DB)
+	s.Repositories.SuratAgenda = repository.NewSuratAgendaRepository(s.DB)
+	s.Repositories.SuratDelivery = repository.NewSuratDeliveryRepository(s.DB)
+	s.Repositories.SuratTembusan = repository.NewSuratTembusanRepository(s.DB)
+	s.Repositories.SuratSpecimen = repository.NewSuratSpecimenRepository(s.DB)
+	s.Repositories.SuratSignature = repository.NewSuratSignatureRepository(s.DB)
+	s.Repositories.SuratFont = repository.NewSuratFontRepository(s.DB)
+	s.Repositories.SuratRetention = repository.NewSuratRetentionRepository(s.DB)
 	s.Repositories.Org = repository.NewOrganizationRepository(s.DB)
 	s.Repositories.Activity = repository.NewActivityRepository(s.DB)
 	s.Repositories.LPJ = repository.NewLPJRepository(s.DB)
@@ -172,6 +256,10 @@ func (s *Server) initRepositories() {
 	s.Repositories.OTP = repository.NewOTPRepository(s.DB)
 	s.Repositories.Asset = repository.NewAssetRepository(s.DB)
 	s.Repositories.AssetBorrow = repository.NewAssetBorrowingRepository(s.DB)
+	s.Repositories.Search = repository.NewSearchRepository(s.DB)
+	s.Repositories.Venue = repository.NewVenueRepository(s.DB)
+	s.Repositories.Registration = repository.NewActivityRegistrationRepository(s.DB)
+	s.Repositories.Attendance = repository.NewActivityAttendanceRepository(s.DB)
 }
 
 func (s *Server) initServices() {
@@ -182,20 +270,35 @@ func (s *Server) initServices() {
 	s.Services.Notify = service.NewNotificationService(s.Repositories.Notify)
 	emailSvc := service.NewEmailService(&s.Config.SMTP)
 	s.Services.Auth = service.NewAuthService(s.Config, s.Repositories.User, s.Repositories.UserRole, s.Repositories.RefreshToken, s.Repositories.OTP, s.Redis, emailSvc, s.Services.Audit)
-	s.Services.Surat = service.NewSuratServiceWithRepo(s.Repositories.Surat, s.Repositories.Org, s.Services.Audit, s.Services.Notify)
+	s.Services.Surat = service.NewSuratServiceWithRepo(s.Repositories.Surat, s.Repositories.SuratNumber, s.Repositories.SuratTemplate, s.Repositories.SuratVersion, s.Repositories.SuratAttachment, s.Repositories.SuratDisposition, s.Repositories.SuratBatch, s.Repositories.SuratAgenda, s.Repositories.SuratDelivery, s.Repositories.SuratTembusan, s.Repositories.SuratSpecimen, s.Repositories.SuratSignature, s.Repositories.SuratFont, s.Repositories.SuratRetention, s.Repositories.Org, s.Repositories.OrgMember, s.Repositories.User, s.Repositories.Activity, s.Services.Audit, s.Services.Notify, emailSvc, &s.Config.Surat, s.Signer)
 	s.Services.Org = service.NewOrganizationService(s.Repositories.Org, s.Services.RBAC, s.Services.Audit)
-	s.Services.Activity = service.NewActivityService(s.Repositories.Activity, s.Repositories.Org, s.Repositories.ActHistory, s.Services.RBAC, s.Services.Notify, s.Services.Audit)
-	s.Services.LPJ = service.NewLPJService(s.Repositories.LPJ, s.Repositories.Activity, s.Repositories.Org, s.Services.RBAC, s.Services.Notify, s.Repositories.LPJHistory, s.Services.Audit)
+	s.Services.Activity = service.NewActivityService(s.Repositories.Activity, s.Repositories.Org, s.Repositories.ActHistory, s.Services.RBAC, s.Services.Notify, s.Services.Audit, time.Duration(s.Config.Activity.LPJDueDays)*24*time.Hour, s.Repositories.Venue, s.Config.Activity.VenueConflict)
+	s.Services.Venue = service.NewVenueService(s.Repositories.Venue, s.Services.Activity, s.Services.Audit)
+	s.Services.Registration = service.NewRegistrationService(s.Repositories.Registration, s.Repositories.Activity, s.Repositories.Org, s.Repositories.User, s.Services.RBAC, emailSvc, s.Services.Audit, s.Config.Activity.RegistrationBaseURL)
+	checkinSigner := checkin.NewSigner(s.Config.Auth.JWTSecret, time.Duration(s.Config.Activity.CheckinRotateSeconds)*time.Second)
+	s.Services.Attendance = service.NewAttendanceService(s.Repositories.Attendance, s.Services.Activity, s.Repositories.Registration, s.Repositories.User, s.Services.Audit, checkinSigner, time.Duration(s.Config.Activity.CheckinGraceMinutes)*time.Minute)
+	s.Services.LPJ = service.NewLPJService(s.Repositories.LPJ, s.Repositories.Activity, s.Repositories.Org, s.Services.RBAC, s.Services.Notify, s.Repositories.LPJHistory, s.Services.Audit, s.Repositories.Attendance)
 	s.Services.Member = service.NewOrgMemberService(s.Repositories.OrgMember, s.Repositories.Org, s.Services.RBAC, s.Services.Audit)
 	s.Services.JoinReq = service.NewOrgJoinRequestService(s.Repositories.OrgJoinReq, s.Repositories.Org, s.Repositories.User, s.Repositories.OrgMember, s.Services.RBAC, s.Services.Audit)
 	s.Services.Dashboard = service.NewDashboardService(s.DB)
 	s.Services.Report = service.NewReportService(s.Repositories.Activity, s.Repositories.Surat, s.Repositories.LPJ)
 	s.Services.Asset = service.NewAssetService(s.Repositories.Asset, s.Repositories.AssetBorrow, s.Services.Audit)
+	s.Services.Search = service.NewSearchService(s.Repositories.Search, s.Services.RBAC)
 
 	// Ensure base roles exist
 	_ = s.Repositories.UserRole.EnsureBaseRoles(context.Background())
 
+	if n, err := s.Services.Surat.FailInterruptedBatches(context.Background()); err != nil {
+		fmt.Printf("[SURAT] tandai batch terhenti gagal: %v\n", err)
+	} else if n > 0 {
+		fmt.Printf("[SURAT] %d batch terhenti ditandai FAILED\n", n)
+	}
+
 	go s.reminderLoop()
+	go s.suratSignReminderLoop()
+	go s.suratRetentionLoop()
+	go s.activityCompleteLoop()
+	go s.registrationPurgeLoop()
 }
 
 func (s *Server) initHandlers() {
@@ -219,6 +322,10 @@ func (s *Server) initHandlers() {
 	s.Handlers.Notify = handler.NewNotificationHandler(s.Services.Notify)
 	s.Handlers.Dashboard = handler.NewDashboardHandler(s.Services.Dashboard)
 	s.Handlers.Report = handler.NewReportHandler(s.Services.Report)
+	s.Handlers.Search = handler.NewSearchHandler(s.Services.Search)
+	s.Handlers.Venue = handler.NewVenueHandler(s.Services.Venue)
+	s.Handlers.Registration = handler.NewRegistrationHandler(s.Services.Registration)
+	s.Handlers.Attendance = handler.NewAttendanceHandler(s.Services.Attendance)
 	s.Handlers.Audit = handler.NewAuditLogHandler(s.DB)
 	s.Handlers.Health = handler.NewHealthHandler(s.StartTime, s.DB, s.Redis, s.Minio, func() map[string]int64 {
 		counts := map[string]int64{}
@@ -249,6 +356,10 @@ func (s *Server) initRouter() {
 	router.RegisterReportRoutes(engine, s.Config, s.Handlers.Report, s.Services.RBAC)
 	router.RegisterAuditLogRoutes(engine, s.Config, s.Handlers.Audit, s.Services.RBAC)
 	router.RegisterAssetRoutes(engine, s.Config, s.Handlers.Asset, s.Services.RBAC)
+	router.RegisterSearchRoutes(engine, s.Config, s.Handlers.Search)
+	router.RegisterVenueRoutes(engine, s.Config, s.Handlers.Venue, s.Services.RBAC)
+	router.RegisterRegistrationRoutes(engine, s.Config, s.Handlers.Registration, s.Services.RBAC)
+	router.RegisterAttendanceRoutes(engine, s.Config, s.Handlers.Attendance, s.Services.RBAC)
 	router.RegisterHealthRoutes(engine, s.Handlers.Health)
 	s.Engine = engine
 }
@@ -258,6 +369,86 @@ func (s *Server) Start() error {
 	return s.Engine.Run(addr)
 }
 
+// suratSignReminderLoop mengingatkan penanda tangan surat yang belum bertindak.
+func (s *Server) suratSignReminderLoop() {
+	if s.Services.Surat == nil || s.Config.Surat.SignReminderHours <= 0 {
+		return
+	}
+	every := time.Duration(s.Config.Surat.SignReminderHours) * time.Hour
+	ticker := time.NewTicker(time.Hour)
+	for range ticker.C {
+		if n := s.Services.Surat.RemindPendingSigners(context.Background(), every); n > 0 {
+			fmt.Printf("[SURAT] %d pengingat tanda tangan dikirim\n", n)
+		}
+	}
+}
+
+// suratRetentionLoop menjalankan aturan retensi arsip surat secara berkala.
+func (s *Server) suratRetentionLoop() {
+	if s.Services.Surat == nil || s.Config.Surat.RetentionCheckHours <= 0 {
+		return
+	}
+	run := func() {
+		res, err := s.Services.Surat.ApplyRetention(context.Background(), s.Minio, s.Config.Minio.Bucket)
+		if err != nil {
+			fmt.Printf("[SURAT] job retensi gagal: %v\n", err)
+			return
+		}
+		if res.Flagged+res.Archived+res.Failed > 0 {
+			fmt.Printf("[SURAT] retensi: %d ditandai, %d diarsipkan, %d gagal\n", res.Flagged, res.Archived, res.Failed)
+		}
+	}
+	// jalan sekali saat start, lalu tiap interval
+	run()
+	ticker := time.NewTicker(time.Duration(s.Config.Surat.RetentionCheckHours) * time.Hour)
+	for range ticker.C {
+		run()
+	}
+}
+
+// activityCompleteLoop menutup kegiatan APPROVED yang sudah berakhir.
+func (s *Server) activityCompleteLoop() {
+	if s.Services.Activity == nil || s.Config.Activity.CompleteCheckMinutes <= 0 {
+		return
+	}
+	run := func() {
+		n, err := s.Services.Activity.CompleteOverdue(context.Background())
+		if err != nil {
+			fmt.Printf("[ACTIVITY] penutupan kegiatan otomatis gagal: %v\n", err)
+		}
+		if n > 0 {
+			fmt.Printf("[ACTIVITY] %d kegiatan ditandai COMPLETED\n", n)
+		}
+	}
+	// jalan sekali saat start, lalu tiap interval
+	run()
+	ticker := time.NewTicker(time.Duration(s.Config.Activity.CompleteCheckMinutes) * time.Minute)
+	for range ticker.C {
+		run()
+	}
+}
+
+// registrationPurgeLoop menghapus pendaftaran kegiatan yang tidak dikonfirmasi.
+func (s *Server) registrationPurgeLoop() {
+	if s.Services.Registration == nil {
+		return
+	}
+	run := func() {
+		n, err := s.Services.Registration.PurgeUnconfirmed(context.Background())
+		if err != nil {
+			fmt.Printf("[ACTIVITY] hapus pendaftaran belum dikonfirmasi gagal: %v\n", err)
+		}
+		if n > 0 {
+			fmt.Printf("[ACTIVITY] %d pendaftaran belum dikonfirmasi dihapus\n", n)
+		}
+	}
+	run()
+	ticker := time.NewTicker(time.Hour)
+	for range ticker.C {
+		run()
+	}
+}
+
 func (s *Server) reminderLoop() {
 	if s.Services.Activity == nil || s.Services.Notify == nil {
 		return
@@ -265,11 +456,12 @@ func (s *Server) reminderLoop() {
 	ticker := time.NewTicker(12 * time.Hour)
 	for range ticker.C {
 		ctx := context.Background()
-		acts, err := s.Services.Activity.ListPublic(ctx, time.Now().Add(-24*time.Hour))
+		now := time.Now()
+		// kegiatan berulang diuraikan agar tiap kemunculan mendapat pengingat
+		acts, err := s.Services.Activity.ListPublicOccurrences(ctx, now.Add(-24*time.Hour), now.Add(72*time.Hour))
 		if err != nil {
 			continue
 		}
-		now := time.Now()
 		for _, a := range acts {
 			diff := a.StartAt.Sub(now)
 			if diff.Hours() <= 72 && diff.Hours() >= 0 {
@@ -278,7 +470,7 @@ func (s *Server) reminderLoop() {
 			if diff.Hours() <= 24 && diff.Hours() >= 0 {
 				_ = s.Services.Notify.Push(ctx, a.CreatedBy, "Pengingat H-1", a.Title, map[string]any{"activity_id": a.ID})
 			}
-			if now.After(a.EndAt) && now.Sub(a.EndAt) <= 24*time.Hour {
+			if a.RecurrenceID == nil && now.After(a.EndAt) && now.Sub(a.EndAt) <= 24*time.Hour {
 				_ = s.Services.Notify.Push(ctx, a.CreatedBy, "LPJ diperlukan", a.Title, map[string]any{"activity_id": a.ID})
 			}
 		}
@@ -0,0 +1,673 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/repository"
+	"simawa-backend/internal/util/rrule"
+)
+
+// maxOccurrences membatasi jumlah kemunculan yang diuraikan per permintaan.
+const maxOccurrences = 2000
+
+// errTooManyOccurrences dikembalikan pengecekan bentrok ruangan bila jadwal yang
+// dicek melebihi maxOccurrences, agar bentrok tidak terlewat karena terpotong.
+var errTooManyOccurrences = errors.New("terlalu banyak jadwal untuk dicek bentroknya, perpendek rentang seri")
+
+const (
+	OccurrenceScopeThis      = "this"      // hanya kemunculan ini
+	OccurrenceScopeFollowing = "following" // kemunculan ini dan seterusnya
+)
+
+// UpdateOccurrenceInput berisi perubahan kemunculan kegiatan berulang; field
+// kosong/nil tidak diubah. RRule hanya berlaku untuk scope following.
+type UpdateOccurrenceInput struct {
+	Title       string
+	Description string
+	Location    string
+	Type        string
+	StartAt     *time.Time
+	EndAt       *time.Time
+	RRule       string
+}
+
+// seriesRoot mengembalikan ID kegiatan induk seri.
+func seriesRoot(a *model.Activity) uuid.UUID {
+	if a.SeriesID != nil {
+		return *a.SeriesID
+	}
+	return a.ID
+}
+
+// applyRecurrence memvalidasi dan menormalkan RRULE/EXDATE kegiatan lalu menghitung
+// RecurUntil (akhir kemunculan terakhir). Aturan kosong menjadikan kegiatan tunggal.
+func applyRecurrence(a *model.Activity, rule string, exdates []time.Time) error {
+	rule = strings.TrimSpace(rule)
+	if rule == "" {
+		a.RRule = ""
+		a.ExDates = nil
+		a.RecurUntil = nil
+		return nil
+	}
+	r, err := rrule.Parse(rule, time.Local)
+	if err != nil {
+		return err
+	}
+	start := a.StartAt.In(time.Local)
+	ex := map[int64]bool{}
+	kept := make([]time.Time, 0, len(exdates))
+	for _, t := range exdates {
+		t = t.Truncate(time.Second)
+		if ex[t.Unix()] || t.Before(start) {
+			continue
+		}
+		ex[t.Unix()] = true
+		kept = append(kept, t)
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].Before(kept[j]) })
+
+	a.RRule = r.String()
+	a.RecurUntil = nil
+	if len(kept) > 0 {
+		a.ExDates, _ = json.Marshal(kept)
+	} else {
+		a.ExDates = nil
+	}
+	if !r.Finite() {
+		return nil
+	}
+	var last *time.Time
+	for _, occ := range r.All(start) {
+		if !ex[occ.Unix()] {
+			occ := occ
+			last = &occ
+		}
+	}
+	if last == nil {
+		return errors.New("seri tidak memiliki kemunculan")
+	}
+	until := last.Add(a.EndAt.Sub(a.StartAt))
+	a.RecurUntil = &until
+	return nil
+}
+
+func decodeExDates(a *model.Activity) []time.Time {
+	var out []time.Time
+	if len(a.ExDates) > 0 {
+		_ = json.Unmarshal(a.ExDates, &out)
+	}
+	return out
+}
+
+// isOccurrence true bila occ adalah kemunculan (yang tidak dibatalkan) dari induk seri m.
+func isOccurrence(m *model.Activity, r *rrule.Rule, occ time.Time) bool {
+	for _, t := range decodeExDates(m) {
+		if t.Equal(occ) {
+			return false
+		}
+	}
+	return len(r.Between(m.StartAt.In(time.Local), occ, occ, 1)) == 1
+}
+
+// Occurrences mengembalikan kegiatan yang berlangsung di jendela [From, To]. Seri
+// berulang diuraikan per kemunculan: salinan induk dengan StartAt/EndAt kemunculan
+// dan RecurrenceID terisi, atau baris kemunculan yang sudah diubah.
+func (s *ActivityService) Occurrences(ctx context.Context, q repository.ActivityWindowQuery) ([]model.Activity, error) {
+	if !q.To.After(q.From) {
+		return nil, errors.New("rentang waktu tidak valid")
+	}
+	rows, err := s.repo.ListWindow(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	var roots []uuid.UUID
+	seen := map[uuid.UUID]bool{}
+	for i := range rows {
+		if root := seriesRoot(&rows[i]); rows[i].RRule != "" && !seen[root] {
+			seen[root] = true
+			roots = append(roots, root)
+		}
+	}
+	overrides, err := s.repo.ListOverrides(ctx, roots)
+	if err != nil {
+		return nil, err
+	}
+	bySeries := map[uuid.UUID][]model.Activity{}
+	for _, ov := range overrides {
+		bySeries[*ov.SeriesID] = append(bySeries[*ov.SeriesID], ov)
+	}
+
+	out := make([]model.Activity, 0, len(rows))
+	for i := range rows {
+		if rows[i].RRule == "" {
+			out = append(out, rows[i])
+			continue
+		}
+		for _, occ := range expandSeries(&rows[i], bySeries[seriesRoot(&rows[i])], q.From, q.To) {
+			// kemunculan yang diubah bisa menunggu persetujuan ulang sementara induknya disetujui
+			if statusMatches(q, occ.Status) {
+				out = append(out, occ)
+			}
+		}
+		if len(out) >= maxOccurrences {
+			break
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartAt.Before(out[j].StartAt) })
+	if len(out) > maxOccurrences {
+		out = out[:maxOccurrences]
+	}
+	return out, nil
+}
+
+func statusMatches(q repository.ActivityWindowQuery, status string) bool {
+	if q.Status != "" && status != q.Status {
+		return false
+	}
+	if len(q.Statuses) > 0 && !slices.Contains(q.Statuses, status) {
+		return false
+	}
+	return true
+}
+
+// ListPublicOccurrences adalah kalender publik: kegiatan publik yang disetujui di [from, to].
+func (s *ActivityService) ListPublicOccurrences(ctx context.Context, from, to time.Time) ([]model.Activity, error) {
+	return s.Occurrences(ctx, repository.ActivityWindowQuery{
+		Status:     model.ActivityStatusApproved,
+		PublicOnly: true,
+		From:       from,
+		To:         to,
+	})
+}
+
+func expandSeries(m *model.Activity, overrides []model.Activity, from, to time.Time) []model.Activity {
+	r, err := rrule.Parse(m.RRule, time.Local)
+	if err != nil {
+		return []model.Activity{*m}
+	}
+	dur := m.EndAt.Sub(m.StartAt)
+	start := m.StartAt.In(time.Local)
+	ex := map[int64]bool{}
+	for _, t := range decodeExDates(m) {
+		ex[t.Unix()] = true
+	}
+	inWindow := func(a *model.Activity) bool { return !a.StartAt.After(to) && !a.EndAt.Before(from) }
+	// pengecualian milik induk ini (seri yang dipecah berbagi root yang sama)
+	mine := map[int64]*model.Activity{}
+	for i := range overrides {
+		rid := overrides[i].RecurrenceID.In(time.Local)
+		if rid.Before(start) || (m.RecurUntil != nil && rid.After(*m.RecurUntil)) || ex[rid.Unix()] {
+			continue
+		}
+		mine[rid.Unix()] = &overrides[i]
+	}
+
+	var out []model.Activity
+	for _, occ := range r.Between(start, from.Add(-dur), to, maxOccurrences) {
+		if ex[occ.Unix()] {
+			continue
+		}
+		if _, ok := mine[occ.Unix()]; ok {
+			continue
+		}
+		inst := *m
+		rid := occ
+		inst.StartAt = occ
+		inst.EndAt = occ.Add(dur)
+		inst.RecurrenceID = &rid
+		out = append(out, inst)
+	}
+	// kemunculan yang diubah bisa dipindah ke luar/dalam jendela, jadi dicek tersendiri
+	for _, ov := range mine {
+		if inWindow(ov) && isOccurrence(m, r, ov.RecurrenceID.In(time.Local)) {
+			out = append(out, *ov)
+		}
+	}
+	return out
+}
+
+// occurrenceMaster mencari induk seri (termasuk pecahan seri) yang memiliki kemunculan occ.
+// id boleh berupa induk, pecahan seri, atau kemunculan yang sudah diubah.
+func (s *ActivityService) occurrenceMaster(ctx context.Context, id uuid.UUID, occ time.Time) (*model.Activity, *rrule.Rule, error) {
+	a, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if a.RRule == "" && a.RecurrenceID == nil {
+		return nil, nil, errors.New("kegiatan ini tidak berulang")
+	}
+	series, err := s.repo.ListSeries(ctx, seriesRoot(a))
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range series {
+		m := &series[i]
+		if m.RecurrenceID != nil || m.RRule == "" {
+			continue
+		}
+		r, err := rrule.Parse(m.RRule, time.Local)
+		if err != nil {
+			continue
+		}
+		if isOccurrence(m, r, occ) {
+			return m, r, nil
+		}
+	}
+	return nil, nil, errors.New("kemunculan tidak ditemukan pada seri ini")
+}
+
+// canManage memastikan userID boleh mengelola organisasi pemilik kegiatan.
+func (s *ActivityService) canManage(ctx context.Context, userID uuid.UUID, a *model.Activity) error {
+	org, err := s.org.GetByID(ctx, a.OrgID)
+	if err != nil {
+		return errors.New("organization not found")
+	}
+	ok, err := s.rbac.CanManageOrg(ctx, userID, org)
+	if err != nil || !ok {
+		return errors.New("forbidden: you don't have permission to manage this activity")
+	}
+	return nil
+}
+
+func (s *ActivityService) canManageActivity(ctx context.Context, userID uuid.UUID, a *model.Activity) error {
+	if err := s.canManage(ctx, userID, a); err != nil {
+		return err
+	}
+	switch a.Status {
+	case model.ActivityStatusCompleted, model.ActivityStatusRejected:
+		return errors.New("kegiatan yang sudah selesai atau ditolak tidak bisa diubah")
+	}
+	return nil
+}
+
+func applyOccurrenceEdits(a *model.Activity, in *UpdateOccurrenceInput) error {
+	if in.Title != "" {
+		a.Title = in.Title
+	}
+	if in.Description != "" {
+		a.Description = in.Description
+	}
+	if in.Location != "" {
+		a.Location = in.Location
+	}
+	if in.Type != "" {
+		a.Type = in.Type
+	}
+	dur := a.EndAt.Sub(a.StartAt)
+	if in.StartAt != nil {
+		a.StartAt = *in.StartAt
+		a.EndAt = a.StartAt.Add(dur)
+	}
+	if in.EndAt != nil {
+		a.EndAt = *in.EndAt
+	}
+	if !a.EndAt.After(a.StartAt) {
+		return errors.New("end_at harus setelah start_at")
+	}
+	return nil
+}
+
+// UpdateOccurrence mengubah kemunculan occ (awal aslinya) dari seri kegiatan.
+// Scope "this" menyimpan baris kemunculan tersendiri (RECURRENCE-ID); scope
+// "following" memecah seri: induk lama berakhir sebelum occ dan seri baru dimulai
+// dari occ dengan perubahan tersebut. Status persetujuan ikut seri; perubahan
+// jadwal/aturan pada seri yang sudah disetujui mengembalikan seri ke PENDING.
+func (s *ActivityService) UpdateOccurrence(ctx context.Context, userID, id uuid.UUID, occ time.Time, scope string, in *UpdateOccurrenceInput) (*model.Activity, error) {
+	if in == nil {
+		return nil, errors.New("input nil")
+	}
+	occ = occ.In(time.Local)
+	m, r, err := s.occurrenceMaster(ctx, id, occ)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canManageActivity(ctx, userID, m); err != nil {
+		return nil, err
+	}
+	var out *model.Activity
+	err = s.withVenueLock(ctx, m.VenueID, func() error {
+		var err error
+		out, err = s.updateOccurrence(ctx, userID, m, r, occ, scope, in)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.refreshVenueFlag(ctx, seriesRoot(m))
+	return out, nil
+}
+
+func (s *ActivityService) updateOccurrence(ctx context.Context, userID uuid.UUID, m *model.Activity, r *rrule.Rule, occ time.Time, scope string, in *UpdateOccurrenceInput) (*model.Activity, error) {
+	root := seriesRoot(m)
+	reschedule := in.StartAt != nil || in.EndAt != nil || strings.TrimSpace(in.RRule) != ""
+	approved := m.Status == model.ActivityStatusApproved
+	// approvedUntil adalah akhir rentang yang sudah disetujui untuk bagian seri ini.
+	var approvedUntil *time.Time
+	if m.RecurUntil != nil {
+		u := *m.RecurUntil
+		approvedUntil = &u
+	}
+
+	switch scope {
+	case OccurrenceScopeThis, "":
+		ov, err := s.repo.GetOverride(ctx, root, occ)
+		isNew := err != nil
+		if isNew {
+			copied := *m
+			ov = &copied
+			ov.ID = uuid.New()
+			ov.StartAt = occ
+			ov.EndAt = occ.Add(m.EndAt.Sub(m.StartAt))
+			ov.SeriesID = &root
+			ov.RecurrenceID = &occ
+			ov.RRule, ov.ExDates, ov.RecurUntil = "", nil, nil
+			ov.CreatedAt, ov.UpdatedAt = time.Time{}, time.Time{}
+		}
+		if err := applyOccurrenceEdits(ov, in); err != nil {
+			return nil, err
+		}
+		if reschedule {
+			if err := s.checkVenueEdit(ctx, m, ov); err != nil {
+				return nil, err
+			}
+		}
+		if approved && reschedule {
+			ov.Status = model.ActivityStatusPending
+		}
+		ov.UpdatedBy = userID
+		if isNew {
+			err = s.repo.Create(ctx, ov)
+		} else {
+			err = s.repo.Update(ctx, ov)
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.appendHistory(ctx, m, userID, "EDIT_OCCURRENCE", occ.Format(time.RFC3339))
+		s.audit.Log(ctx, userID, "activity_occurrence_update", map[string]any{"activity_id": m.ID, "occurrence": occ, "scope": OccurrenceScopeThis})
+		if approved && reschedule {
+			s.requestReapproval(ctx, userID, ov, "jadwal kemunculan "+occ.Format(time.RFC3339)+" diubah")
+		}
+		return ov, nil
+
+	case OccurrenceScopeFollowing:
+		rule := in.RRule
+		if occ.Equal(m.StartAt.In(time.Local)) {
+			// dari kemunculan pertama: seluruh seri induk ini yang berubah
+			if rule == "" {
+				rule = m.RRule
+			}
+			if err := applyOccurrenceEdits(m, in); err != nil {
+				return nil, err
+			}
+			if err := applyRecurrence(m, rule, decodeExDates(m)); err != nil {
+				return nil, err
+			}
+			if approved && in.RRule != "" && extendsPast(m, approvedUntil) {
+				return nil, errApprovedRange
+			}
+			if reschedule {
+				if err := s.checkVenueEdit(ctx, m, m); err != nil {
+					return nil, err
+				}
+			}
+			if approved && reschedule {
+				m.Status = model.ActivityStatusPending
+			}
+			m.UpdatedBy = userID
+			if err := s.repo.Update(ctx, m); err != nil {
+				return nil, err
+			}
+			s.pruneOverrides(ctx, m, occ)
+			s.appendHistory(ctx, m, userID, "EDIT_SERIES", "")
+			s.audit.Log(ctx, userID, "activity_occurrence_update", map[string]any{"activity_id": m.ID, "occurrence": occ, "scope": OccurrenceScopeFollowing})
+			if approved && reschedule {
+				s.requestReapproval(ctx, userID, m, "jadwal seri diubah")
+			}
+			return m, nil
+		}
+
+		oldUntil := m.RecurUntil
+		before := r.Between(m.StartAt.In(time.Local), m.StartAt.In(time.Local), occ.Add(-time.Second), 0)
+		if rule == "" {
+			next := *r
+			if next.Count > 0 {
+				next.Count -= len(before)
+			}
+			rule = next.String()
+		}
+		var oldEx, newEx []time.Time
+		for _, t := range decodeExDates(m) {
+			if t.Before(occ) {
+				oldEx = append(oldEx, t)
+			} else {
+				newEx = append(newEx, t)
+			}
+		}
+
+		next := *m
+		next.ID = uuid.New()
+		next.StartAt = occ
+		next.EndAt = occ.Add(m.EndAt.Sub(m.StartAt))
+		next.SeriesID = &root
+		next.CreatedAt, next.UpdatedAt = time.Time{}, time.Time{}
+		next.CreatedBy, next.UpdatedBy = userID, userID
+		if err := applyOccurrenceEdits(&next, in); err != nil {
+			return nil, err
+		}
+		if err := applyRecurrence(&next, rule, newEx); err != nil {
+			return nil, err
+		}
+		if approved && in.RRule != "" && extendsPast(&next, approvedUntil) {
+			return nil, errApprovedRange
+		}
+		if reschedule {
+			if err := s.checkVenueEdit(ctx, m, &next); err != nil {
+				return nil, err
+			}
+		}
+
+		if approved && reschedule {
+			next.Status = model.ActivityStatusPending
+		}
+
+		head := *r
+		head.Count = 0
+		head.Until = occ.Add(-time.Second)
+		if err := applyRecurrence(m, head.String(), oldEx); err != nil {
+			return nil, err
+		}
+		m.UpdatedBy = userID
+		prune, err := s.staleOverrides(ctx, root, &next, occ, oldUntil)
+		if err != nil {
+			return nil, err
+		}
+		// induk dipotong, pecahan dibuat, dan kemunculan usang dihapus sekaligus agar
+		// kegagalan di tengah tidak menghilangkan kemunculan sejak occ
+		if err := s.repo.SplitSeries(ctx, m, &next, prune); err != nil {
+			return nil, err
+		}
+		s.appendHistory(ctx, m, userID, "SPLIT_SERIES", fmt.Sprintf("%s -> %s", occ.Format(time.RFC3339), next.ID))
+		s.appendHistory(ctx, &next, userID, "CREATE", fmt.Sprintf("pecahan seri %s", m.ID))
+		s.audit.Log(ctx, userID, "activity_occurrence_update", map[string]any{"activity_id": m.ID, "occurrence": occ, "scope": OccurrenceScopeFollowing, "new_activity_id": next.ID})
+		if approved && reschedule {
+			s.requestReapproval(ctx, userID, &next, "seri dipecah mulai "+occ.Format(time.RFC3339))
+		}
+		return &next, nil
+	}
+	return nil, errors.New("scope harus this atau following")
+}
+
+var errApprovedRange = errors.New("aturan baru melewati rentang seri yang sudah disetujui; ajukan kegiatan baru untuk jadwal tambahan")
+
+// extendsPast true bila seri a berakhir setelah until (atau tanpa akhir). until nil
+// berarti rentang yang disetujui memang tanpa akhir.
+func extendsPast(a *model.Activity, until *time.Time) bool {
+	if until == nil {
+		return false
+	}
+	return a.RecurUntil == nil || a.RecurUntil.After(*until)
+}
+
+// requestReapproval mencatat dan memberitahukan bahwa bagian seri a (kemunculan,
+// pecahan seri, atau induk yang diedit) kembali PENDING karena jadwalnya diubah.
+// Bagian seri lain tetap disetujui sehingga RSVP dan presensinya tetap berjalan.
+func (s *ActivityService) requestReapproval(ctx context.Context, userID uuid.UUID, a *model.Activity, note string) {
+	s.appendHistory(ctx, a, userID, "RESUBMIT", note)
+	_ = s.notify.Push(ctx, a.CreatedBy, "Jadwal kegiatan diubah, perlu persetujuan ulang", a.Title, map[string]any{"activity_id": a.ID})
+}
+
+// CancelOccurrence membatalkan kemunculan occ (scope this, menjadi EXDATE) atau
+// kemunculan occ dan seterusnya (scope following, seri diakhiri sebelum occ).
+func (s *ActivityService) CancelOccurrence(ctx context.Context, userID, id uuid.UUID, occ time.Time, scope string) (*model.Activity, error) {
+	occ = occ.In(time.Local)
+	m, r, err := s.occurrenceMaster(ctx, id, occ)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canManageActivity(ctx, userID, m); err != nil {
+		return nil, err
+	}
+	root := seriesRoot(m)
+	oldUntil := m.RecurUntil
+
+	switch scope {
+	case OccurrenceScopeThis, "":
+		if err := applyRecurrence(m, m.RRule, append(decodeExDates(m), occ)); err != nil {
+			return nil, errors.New("kemunculan terakhir seri tidak bisa dibatalkan sendiri")
+		}
+		if ov, err := s.repo.GetOverride(ctx, root, occ); err == nil {
+			_ = s.repo.Delete(ctx, ov.ID)
+		}
+	case OccurrenceScopeFollowing:
+		if occ.Equal(m.StartAt.In(time.Local)) {
+			return nil, errors.New("tidak bisa membatalkan seluruh seri dari kemunculan pertamanya")
+		}
+		head := *r
+		head.Count = 0
+		head.Until = occ.Add(-time.Second)
+		var oldEx []time.Time
+		for _, t := range decodeExDates(m) {
+			if t.Before(occ) {
+				oldEx = append(oldEx, t)
+			}
+		}
+		if err := applyRecurrence(m, head.String(), oldEx); err != nil {
+			return nil, err
+		}
+		s.pruneOverridesBetween(ctx, root, nil, occ, oldUntil)
+	default:
+		return nil, errors.New("scope harus this atau following")
+	}
+	m.UpdatedBy = userID
+	if err := s.repo.Update(ctx, m); err != nil {
+		return nil, err
+	}
+	s.appendHistory(ctx, m, userID, "CANCEL_OCCURRENCE", fmt.Sprintf("%s (%s)", occ.Format(time.RFC3339), scope))
+	s.audit.Log(ctx, userID, "activity_occurrence_cancel", map[string]any{"activity_id": m.ID, "occurrence": occ, "scope": scope})
+	s.refreshVenueFlag(ctx, root)
+	return m, nil
+}
+
+// pruneOverrides menghapus kemunculan diubah milik m (sejak from) yang tidak lagi
+// cocok dengan aturannya.
+func (s *ActivityService) pruneOverrides(ctx context.Context, m *model.Activity, from time.Time) {
+	s.pruneOverridesBetween(ctx, seriesRoot(m), m, from, m.RecurUntil)
+}
+
+// pruneOverridesBetween menghapus kemunculan diubah dengan RecurrenceID di [from, until]
+// yang bukan kemunculan keep (nil: hapus semuanya).
+func (s *ActivityService) pruneOverridesBetween(ctx context.Context, root uuid.UUID, keep *model.Activity, from time.Time, until *time.Time) {
+	ids, err := s.staleOverrides(ctx, root, keep, from, until)
+	if err != nil {
+		return
+	}
+	for _, id := range ids {
+		_ = s.repo.Delete(ctx, id)
+	}
+}
+
+// staleOverrides mengembalikan ID kemunculan diubah yang akan dihapus pruneOverridesBetween.
+func (s *ActivityService) staleOverrides(ctx context.Context, root uuid.UUID, keep *model.Activity, from time.Time, until *time.Time) ([]uuid.UUID, error) {
+	overrides, err := s.repo.ListOverrides(ctx, []uuid.UUID{root})
+	if err != nil {
+		return nil, err
+	}
+	var r *rrule.Rule
+	if keep != nil {
+		r, _ = rrule.Parse(keep.RRule, time.Local)
+	}
+	var out []uuid.UUID
+	for _, ov := range overrides {
+		rid := ov.RecurrenceID.In(time.Local)
+		if rid.Before(from) || (until != nil && rid.After(*until)) {
+			continue
+		}
+		if r != nil && isOccurrence(keep, r, rid) {
+			continue
+		}
+		out = append(out, ov.ID)
+	}
+	return out, nil
+}
+
+// OccurrenceAt mengembalikan kemunculan kegiatan a yang sedang berlangsung pada now,
+// dengan toleransi grace sebelum mulai dan sesudah selesai. Bila beberapa cocok,
+// dipilih yang mulainya paling dekat dengan now.
+func (s *ActivityService) OccurrenceAt(ctx context.Context, a *model.Activity, now time.Time, grace time.Duration) (*model.Activity, error) {
+	var candidates []model.Activity
+	if a.RRule == "" && a.SeriesID == nil {
+		candidates = []model.Activity{*a}
+	} else {
+		series, err := s.repo.ListSeries(ctx, seriesRoot(a))
+		if err != nil {
+			return nil, err
+		}
+		var masters, overrides []model.Activity
+		for _, row := range series {
+			if row.RecurrenceID != nil {
+				overrides = append(overrides, row)
+			} else {
+				masters = append(masters, row)
+			}
+		}
+		for i := range masters {
+			if masters[i].RRule == "" {
+				candidates = append(candidates, masters[i])
+				continue
+			}
+			candidates = append(candidates, expandSeries(&masters[i], overrides, now.Add(-grace), now.Add(grace))...)
+		}
+	}
+	var best *model.Activity
+	for i := range candidates {
+		c := &candidates[i]
+		if now.Before(c.StartAt.Add(-grace)) || now.After(c.EndAt.Add(grace)) {
+			continue
+		}
+		if best == nil || absDuration(c.StartAt.Sub(now)) < absDuration(best.StartAt.Sub(now)) {
+			best = c
+		}
+	}
+	if best == nil {
+		return nil, errors.New("tidak ada sesi kegiatan yang sedang berlangsung")
+	}
+	return best, nil
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
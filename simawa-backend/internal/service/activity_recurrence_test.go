@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"simawa-backend/internal/model"
+)
+
+// EXDATE tetap dihitung dalam COUNT (RFC 5545): kemunculan yang dibatalkan tidak
+// digantikan kemunculan baru di akhir seri.
+func TestApplyRecurrenceCountWithExDates(t *testing.T) {
+	at := func(d int) time.Time { return time.Date(2026, 1, d, 9, 0, 0, 0, time.Local) }
+	tests := []struct {
+		name    string
+		rule    string
+		exdates []time.Time
+		until   time.Time // akhir kemunculan terakhir; nol berarti tanpa akhir
+		wantErr bool
+	}{
+		{"tanpa exdate", "FREQ=DAILY;COUNT=5", nil, at(9).Add(2 * time.Hour), false},
+		{"exdate di tengah", "FREQ=DAILY;COUNT=5", []time.Time{at(6), at(7)}, at(9).Add(2 * time.Hour), false},
+		{"exdate kemunculan terakhir", "FREQ=DAILY;COUNT=5", []time.Time{at(9)}, at(8).Add(2 * time.Hour), false},
+		{"exdate sebelum dtstart diabaikan", "FREQ=DAILY;COUNT=2", []time.Time{at(1)}, at(6).Add(2 * time.Hour), false},
+		{"semua dibatalkan", "FREQ=DAILY;COUNT=2", []time.Time{at(5), at(6)}, time.Time{}, true},
+		{"tanpa akhir", "FREQ=WEEKLY", []time.Time{at(12)}, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &model.Activity{StartAt: at(5), EndAt: at(5).Add(2 * time.Hour)}
+			err := applyRecurrence(a, tt.rule, tt.exdates)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyRecurrence: %v", err)
+			}
+			switch {
+			case tt.until.IsZero() && a.RecurUntil != nil:
+				t.Fatalf("RecurUntil = %v, want kosong", *a.RecurUntil)
+			case !tt.until.IsZero() && (a.RecurUntil == nil || !a.RecurUntil.Equal(tt.until)):
+				t.Fatalf("RecurUntil = %v, want %v", a.RecurUntil, tt.until)
+			}
+		})
+	}
+}
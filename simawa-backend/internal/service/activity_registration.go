@@ -0,0 +1,530 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/repository"
+)
+
+// maxRegistrationQuestions membatasi jumlah pertanyaan tambahan per formulir.
+const maxRegistrationQuestions = 20
+
+// registrationConfirmWindow adalah masa berlaku tautan konfirmasi email pendaftaran.
+const registrationConfirmWindow = 24 * time.Hour
+
+type RegistrationService struct {
+	repo     repository.ActivityRegistrationRepository
+	activity repository.ActivityRepository
+	org      repository.OrganizationRepository
+	users    repository.UserRepository
+	rbac     *RBACService
+	email    *EmailService
+	audit    *AuditService
+	baseURL  string // URL halaman status pendaftaran; token ditambahkan di belakangnya
+}
+
+func NewRegistrationService(repo repository.ActivityRegistrationRepository, activity repository.ActivityRepository, org repository.OrganizationRepository, users repository.UserRepository, rbac *RBACService, email *EmailService, audit *AuditService, baseURL string) *RegistrationService {
+	return &RegistrationService{repo: repo, activity: activity, org: org, users: users, rbac: rbac, email: email, audit: audit, baseURL: baseURL}
+}
+
+// RegistrationFormInput adalah pengaturan pendaftaran yang disimpan penyelenggara.
+type RegistrationFormInput struct {
+	Enabled       bool
+	Quota         int
+	WaitlistLimit int
+	OpensAt       *time.Time
+	ClosesAt      *time.Time
+	Questions     []model.RegistrationQuestion
+}
+
+// RegistrationFormView adalah formulir beserta keterisian kuotanya.
+type RegistrationFormView struct {
+	Form       *model.ActivityRegistrationForm `json:"form"`
+	Registered int64                           `json:"registered"`
+	Waitlisted int64                           `json:"waitlisted"`
+	Open       bool                            `json:"open"`
+	Full       bool                            `json:"full"` // kuota penuh, pendaftar baru masuk daftar tunggu
+}
+
+// RegisterInput adalah data pendaftar. UserID terisi bila mendaftar dengan akun.
+type RegisterInput struct {
+	UserID  *uuid.UUID
+	Name    string
+	Email   string
+	Phone   string
+	Answers map[string]any
+}
+
+func (s *RegistrationService) canManage(ctx context.Context, userID uuid.UUID, activityID uuid.UUID) (*model.Activity, error) {
+	a, err := s.activity.Get(ctx, activityID)
+	if err != nil {
+		return nil, errors.New("kegiatan tidak ditemukan")
+	}
+	org, err := s.org.GetByID(ctx, a.OrgID)
+	if err != nil {
+		return nil, errors.New("organization not found")
+	}
+	ok, err := s.rbac.CanManageOrg(ctx, userID, org)
+	if err != nil || !ok {
+		return nil, errors.New("forbidden: you don't have permission to manage this activity")
+	}
+	return a, nil
+}
+
+func validateQuestions(qs []model.RegistrationQuestion) ([]model.RegistrationQuestion, error) {
+	if len(qs) > maxRegistrationQuestions {
+		return nil, fmt.Errorf("maksimal %d pertanyaan", maxRegistrationQuestions)
+	}
+	seen := map[string]bool{}
+	out := make([]model.RegistrationQuestion, 0, len(qs))
+	for i, q := range qs {
+		q.Key = strings.TrimSpace(q.Key)
+		q.Label = strings.TrimSpace(q.Label)
+		if q.Key == "" {
+			q.Key = "q" + strconv.Itoa(i+1)
+		}
+		if q.Label == "" {
+			return nil, fmt.Errorf("pertanyaan %s belum punya label", q.Key)
+		}
+		if seen[q.Key] {
+			return nil, fmt.Errorf("key pertanyaan %s ganda", q.Key)
+		}
+		seen[q.Key] = true
+		switch q.Type {
+		case "":
+			q.Type = "text"
+		case "text", "number":
+		case "choice":
+			if len(q.Options) == 0 {
+				return nil, fmt.Errorf("pertanyaan %s wajib punya pilihan", q.Key)
+			}
+		default:
+			return nil, fmt.Errorf("tipe pertanyaan %s harus text, number atau choice", q.Key)
+		}
+		out = append(out, q)
+	}
+	return out, nil
+}
+
+// SaveForm membuat atau mengubah formulir pendaftaran. Bila kuota dinaikkan,
+// daftar tunggu langsung dinaikkan sampai kuota terisi.
+func (s *RegistrationService) SaveForm(ctx context.Context, userID, activityID uuid.UUID, in *RegistrationFormInput) (*RegistrationFormView, error) {
+	a, err := s.canManage(ctx, userID, activityID)
+	if err != nil {
+		return nil, err
+	}
+	if in.Enabled && !a.Public {
+		return nil, errors.New("pendaftaran hanya untuk kegiatan publik")
+	}
+	if in.Quota < 0 || in.WaitlistLimit < 0 {
+		return nil, errors.New("kuota tidak boleh negatif")
+	}
+	if in.OpensAt != nil && in.ClosesAt != nil && !in.ClosesAt.After(*in.OpensAt) {
+		return nil, errors.New("waktu tutup harus setelah waktu buka")
+	}
+	qs, err := validateQuestions(in.Questions)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.repo.GetForm(ctx, activityID)
+	if err != nil {
+		f = &model.ActivityRegistrationForm{ActivityID: activityID}
+	}
+	f.Enabled = in.Enabled
+	f.Quota = in.Quota
+	f.WaitlistLimit = in.WaitlistLimit
+	f.OpensAt = in.OpensAt
+	f.ClosesAt = in.ClosesAt
+	f.Questions, _ = json.Marshal(qs)
+	f.UpdatedBy = &userID
+	if err := s.repo.SaveForm(ctx, f); err != nil {
+		return nil, err
+	}
+	if promoted, err := s.repo.Promote(ctx, activityID); err == nil {
+		for i := range promoted {
+			s.sendConfirmation(a, &promoted[i], true)
+		}
+	}
+	s.audit.Log(ctx, userID, "activity_registration_form_save", map[string]any{"activity_id": activityID, "quota": f.Quota, "enabled": f.Enabled})
+	return s.view(ctx, f)
+}
+
+// Form mengembalikan formulir pendaftaran kegiatan beserta keterisian kuotanya.
+func (s *RegistrationService) Form(ctx context.Context, activityID uuid.UUID) (*RegistrationFormView, error) {
+	f, err := s.repo.GetForm(ctx, activityID)
+	if err != nil {
+		return nil, errors.New("kegiatan ini tidak membuka pendaftaran")
+	}
+	return s.view(ctx, f)
+}
+
+func (s *RegistrationService) view(ctx context.Context, f *model.ActivityRegistrationForm) (*RegistrationFormView, error) {
+	counts, err := s.repo.CountByStatus(ctx, f.ActivityID)
+	if err != nil {
+		return nil, err
+	}
+	v := &RegistrationFormView{
+		Form:       f,
+		Registered: counts[model.RegistrationStatusRegistered],
+		Waitlisted: counts[model.RegistrationStatusWaitlisted],
+	}
+	v.Full = f.Quota > 0 && v.Registered >= int64(f.Quota)
+	v.Open = s.openErr(f, nil, time.Now()) == nil
+	return v, nil
+}
+
+// openErr menjelaskan kenapa pendaftaran belum/tidak lagi dibuka (nil = dibuka).
+func (s *RegistrationService) openErr(f *model.ActivityRegistrationForm, a *model.Activity, now time.Time) error {
+	if !f.Enabled {
+		return errors.New("pendaftaran kegiatan ini ditutup")
+	}
+	if f.OpensAt != nil && now.Before(*f.OpensAt) {
+		return fmt.Errorf("pendaftaran dibuka %s", f.OpensAt.Format("02-01-2006 15:04"))
+	}
+	if f.ClosesAt != nil && now.After(*f.ClosesAt) {
+		return errors.New("pendaftaran sudah ditutup")
+	}
+	if a != nil {
+		if !a.Public || a.Status != model.ActivityStatusApproved {
+			return errors.New("kegiatan ini tidak membuka pendaftaran")
+		}
+		end := a.EndAt
+		if a.RecurUntil != nil {
+			end = *a.RecurUntil
+		}
+		if now.After(end) {
+			return errors.New("kegiatan sudah berakhir")
+		}
+	}
+	return nil
+}
+
+func validateAnswers(qs []model.RegistrationQuestion, answers map[string]any) (map[string]any, error) {
+	out := map[string]any{}
+	for _, q := range qs {
+		raw, ok := answers[q.Key]
+		val := strings.TrimSpace(fmt.Sprint(raw))
+		if !ok || raw == nil || val == "" {
+			if q.Required {
+				return nil, fmt.Errorf("%s wajib diisi", q.Label)
+			}
+			continue
+		}
+		switch q.Type {
+		case "number":
+			n, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s harus berupa angka", q.Label)
+			}
+			out[q.Key] = n
+		case "choice":
+			valid := false
+			for _, opt := range q.Options {
+				if opt == val {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				return nil, fmt.Errorf("pilihan %s tidak valid", q.Label)
+			}
+			out[q.Key] = val
+		default:
+			if len(val) > 2000 {
+				return nil, fmt.Errorf("%s terlalu panjang", q.Label)
+			}
+			out[q.Key] = val
+		}
+	}
+	return out, nil
+}
+
+func newRegistrationToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Register mendaftarkan peserta. Bila kuota penuh pendaftar masuk daftar tunggu
+// (status WAITLISTED) dan naik otomatis saat ada yang membatalkan.
+func (s *RegistrationService) Register(ctx context.Context, activityID uuid.UUID, in *RegisterInput) (*model.ActivityRegistration, error) {
+	a, err := s.activity.Get(ctx, activityID)
+	if err != nil {
+		return nil, errors.New("kegiatan tidak ditemukan")
+	}
+	f, err := s.repo.GetForm(ctx, activityID)
+	if err != nil {
+		return nil, errors.New("kegiatan ini tidak membuka pendaftaran")
+	}
+	if err := s.openErr(f, a, time.Now()); err != nil {
+		return nil, err
+	}
+	accountEmail := ""
+	if in.UserID != nil && s.users != nil {
+		// data akun dipakai bila pendaftar tidak mengisi sendiri
+		if u, err := s.users.GetByUUID(ctx, *in.UserID); err == nil {
+			accountEmail = u.Email
+			if strings.TrimSpace(in.Name) == "" {
+				in.Name = strings.TrimSpace(u.FirstName + " " + u.SecondName)
+			}
+			if strings.TrimSpace(in.Email) == "" {
+				in.Email = u.Email
+			}
+		}
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return nil, errors.New("nama wajib diisi")
+	}
+	addr, err := mail.ParseAddress(strings.TrimSpace(in.Email))
+	if err != nil {
+		return nil, errors.New("email tidak valid")
+	}
+	var qs []model.RegistrationQuestion
+	if len(f.Questions) > 0 {
+		_ = json.Unmarshal(f.Questions, &qs)
+	}
+	answers, err := validateAnswers(qs, in.Answers)
+	if err != nil {
+		return nil, err
+	}
+	token, err := newRegistrationToken()
+	if err != nil {
+		return nil, err
+	}
+	reg := &model.ActivityRegistration{
+		ActivityID: activityID,
+		UserID:     in.UserID,
+		Name:       name,
+		Email:      strings.ToLower(addr.Address),
+		Phone:      strings.TrimSpace(in.Phone),
+		Answers:    answers,
+		Token:      token,
+	}
+	// email akun sudah terverifikasi; email lain harus dikonfirmasi dulu lewat
+	// tautan yang dikirim sebelum mendapat kursi dan hak membatalkan
+	if accountEmail != "" && strings.EqualFold(accountEmail, reg.Email) {
+		if err := s.repo.Register(ctx, reg); err != nil {
+			return nil, err
+		}
+		s.sendConfirmation(a, reg, false)
+		return reg, nil
+	}
+	if err := s.repo.CreateUnconfirmed(ctx, reg); err != nil {
+		return nil, err
+	}
+	s.sendEmail(reg.Email, "Konfirmasi pendaftaran: "+a.Title, "Konfirmasi email Anda",
+		fmt.Sprintf("Halo %s, buka tautan berikut dalam %d jam untuk mengonfirmasi pendaftaran Anda pada kegiatan %s. Abaikan email ini bila Anda tidak merasa mendaftar.",
+			reg.Name, int(registrationConfirmWindow.Hours()), a.Title), s.statusLink(reg))
+	return reg, nil
+}
+
+// PurgeUnconfirmed menghapus pendaftaran yang tautan konfirmasinya sudah kedaluwarsa.
+func (s *RegistrationService) PurgeUnconfirmed(ctx context.Context) (int64, error) {
+	return s.repo.PurgeUnconfirmed(ctx, time.Now().Add(-registrationConfirmWindow))
+}
+
+// ConfirmByToken mengonfirmasi email pendaftar tanpa akun; pendaftar baru mendapat
+// kursi (atau daftar tunggu) setelah langkah ini.
+func (s *RegistrationService) ConfirmByToken(ctx context.Context, token string) (*model.ActivityRegistration, error) {
+	reg, a, err := s.GetByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if reg.Status != model.RegistrationStatusUnconfirmed {
+		return nil, errors.New("pendaftaran sudah dikonfirmasi")
+	}
+	if time.Since(reg.CreatedAt) > registrationConfirmWindow {
+		return nil, errors.New("tautan konfirmasi sudah kedaluwarsa, silakan daftar ulang")
+	}
+	f, err := s.repo.GetForm(ctx, reg.ActivityID)
+	if err != nil {
+		return nil, errors.New("kegiatan ini tidak membuka pendaftaran")
+	}
+	if err := s.openErr(f, a, time.Now()); err != nil {
+		return nil, err
+	}
+	reg, err = s.repo.Confirm(ctx, reg.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.sendConfirmation(a, reg, false)
+	return reg, nil
+}
+
+// GetByToken mengembalikan pendaftaran milik pemegang token beserta kegiatannya.
+func (s *RegistrationService) GetByToken(ctx context.Context, token string) (*model.ActivityRegistration, *model.Activity, error) {
+	reg, err := s.repo.GetByToken(ctx, strings.TrimSpace(token))
+	if err != nil {
+		return nil, nil, errors.New("pendaftaran tidak ditemukan")
+	}
+	a, err := s.activity.Get(ctx, reg.ActivityID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reg, a, nil
+}
+
+// CancelByToken membatalkan pendaftaran oleh pendaftar sendiri.
+func (s *RegistrationService) CancelByToken(ctx context.Context, token string) (*model.ActivityRegistration, error) {
+	reg, _, err := s.GetByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if reg.Status == model.RegistrationStatusUnconfirmed {
+		return nil, errors.New("konfirmasi email pendaftaran terlebih dahulu")
+	}
+	return s.cancel(ctx, reg.ID)
+}
+
+// Cancel membatalkan pendaftaran oleh penyelenggara.
+func (s *RegistrationService) Cancel(ctx context.Context, userID, activityID uuid.UUID, id uint) (*model.ActivityRegistration, error) {
+	if _, err := s.canManage(ctx, userID, activityID); err != nil {
+		return nil, err
+	}
+	reg, err := s.repo.Get(ctx, id)
+	if err != nil || reg.ActivityID != activityID {
+		return nil, errors.New("pendaftaran tidak ditemukan")
+	}
+	out, err := s.cancel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Log(ctx, userID, "activity_registration_cancel", map[string]any{"activity_id": activityID, "registration_id": id})
+	return out, nil
+}
+
+func (s *RegistrationService) cancel(ctx context.Context, id uint) (*model.ActivityRegistration, error) {
+	reg, promoted, err := s.repo.Cancel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.activity.Get(ctx, reg.ActivityID)
+	if err != nil {
+		return reg, nil
+	}
+	s.sendEmail(reg.Email, "Pendaftaran dibatalkan: "+a.Title, "Pendaftaran dibatalkan",
+		fmt.Sprintf("Halo %s, pendaftaran Anda pada kegiatan %s sudah dibatalkan.", reg.Name, a.Title), "")
+	for i := range promoted {
+		s.sendConfirmation(a, &promoted[i], true)
+	}
+	return reg, nil
+}
+
+// List mengembalikan pendaftar kegiatan untuk penyelenggara.
+func (s *RegistrationService) List(ctx context.Context, userID, activityID uuid.UUID, status string) ([]model.ActivityRegistration, error) {
+	if _, err := s.canManage(ctx, userID, activityID); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, activityID, strings.ToUpper(strings.TrimSpace(status)))
+}
+
+// Export menghasilkan CSV pendaftar; jawaban pertanyaan tambahan menjadi kolom sendiri.
+func (s *RegistrationService) Export(ctx context.Context, userID, activityID uuid.UUID) ([]byte, string, error) {
+	if _, err := s.canManage(ctx, userID, activityID); err != nil {
+		return nil, "", err
+	}
+	rows, err := s.repo.List(ctx, activityID, "")
+	if err != nil {
+		return nil, "", err
+	}
+	var qs []model.RegistrationQuestion
+	if f, err := s.repo.GetForm(ctx, activityID); err == nil && len(f.Questions) > 0 {
+		_ = json.Unmarshal(f.Questions, &qs)
+	}
+
+	b := &bytes.Buffer{}
+	w := csv.NewWriter(b)
+	header := []string{"No", "Nama", "Email", "Telepon", "Status", "Terdaftar", "Naik dari Daftar Tunggu", "Dibatalkan"}
+	for _, q := range qs {
+		header = append(header, q.Label)
+	}
+	_ = w.Write(header)
+	for i, r := range rows {
+		line := []string{
+			strconv.Itoa(i + 1),
+			r.Name,
+			r.Email,
+			r.Phone,
+			r.Status,
+			r.CreatedAt.Format("2006-01-02 15:04"),
+			formatDateTimePtr(r.PromotedAt),
+			formatDateTimePtr(r.CancelledAt),
+		}
+		for _, q := range qs {
+			v := ""
+			if r.Answers != nil && r.Answers[q.Key] != nil {
+				v = fmt.Sprint(r.Answers[q.Key])
+			}
+			line = append(line, v)
+		}
+		_ = w.Write(line)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, "", err
+	}
+	filename := fmt.Sprintf("pendaftar_%s_%s.csv", activityID.String()[:8], time.Now().Format("20060102"))
+	return b.Bytes(), filename, nil
+}
+
+func formatDateTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+func (s *RegistrationService) statusLink(reg *model.ActivityRegistration) string {
+	if strings.TrimSpace(s.baseURL) == "" {
+		return ""
+	}
+	return strings.TrimRight(s.baseURL, "/") + "/" + reg.Token
+}
+
+// sendConfirmation mengirim email status pendaftaran (terdaftar, daftar tunggu,
+// atau naik dari daftar tunggu bila promoted).
+func (s *RegistrationService) sendConfirmation(a *model.Activity, reg *model.ActivityRegistration, promoted bool) {
+	when := a.StartAt.Format("02-01-2006 15:04")
+	var subject, title, msg string
+	switch {
+	case promoted:
+		subject = "Anda mendapat kursi: " + a.Title
+		title = "Naik dari daftar tunggu"
+		msg = fmt.Sprintf("Halo %s, ada kursi yang kosong sehingga Anda kini terdaftar pada kegiatan %s (%s, %s).", reg.Name, a.Title, when, a.Location)
+	case reg.Status == model.RegistrationStatusWaitlisted:
+		subject = "Daftar tunggu: " + a.Title
+		title = "Anda masuk daftar tunggu"
+		msg = fmt.Sprintf("Halo %s, kuota kegiatan %s sudah penuh. Anda masuk daftar tunggu dan akan dikabari otomatis bila ada kursi kosong.", reg.Name, a.Title)
+	default:
+		subject = "Pendaftaran berhasil: " + a.Title
+		title = "Pendaftaran berhasil"
+		msg = fmt.Sprintf("Halo %s, Anda terdaftar pada kegiatan %s (%s, %s).", reg.Name, a.Title, when, a.Location)
+	}
+	s.sendEmail(reg.Email, subject, title, msg, s.statusLink(reg))
+}
+
+func (s *RegistrationService) sendEmail(to, subject, title, msg, link string) {
+	if s.email == nil {
+		return
+	}
+	if err := s.email.SendNotice(to, subject+" - SIMAWA", title, msg, link); err != nil {
+		fmt.Printf("[ACTIVITY] email pendaftaran ke %s gagal: %v\n", to, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,10 +20,14 @@ type ActivityService struct {
 	rbac    *RBACService
 	notify  *NotificationService
 	audit   *AuditService
+	lpjDue  time.Duration // batas kirim LPJ sejak kegiatan berakhir
+	venues  repository.VenueRepository
+	// venueConflict: VenueConflictReject atau VenueConflictFlag
+	venueConflict string
 }
 
-func NewActivityService(repo repository.ActivityRepository, org repository.OrganizationRepository, history repository.ActivityHistoryRepository, rbac *RBACService, notify *NotificationService, audit *AuditService) *ActivityService {
-	return &ActivityService{repo: repo, org: org, history: history, rbac: rbac, notify: notify, audit: audit}
+func NewActivityService(repo repository.ActivityRepository, org repository.OrganizationRepository, history repository.ActivityHistoryRepository, rbac *RBACService, notify *NotificationService, audit *AuditService, lpjDue time.Duration, venues repository.VenueRepository, venueConflict string) *ActivityService {
+	return &ActivityService{repo: repo, org: org, history: history, rbac: rbac, notify: notify, audit: audit, lpjDue: lpjDue, venues: venues, venueConflict: venueConflict}
 }
 
 type CreateActivityInput struct {
@@ -30,6 +35,7 @@ type CreateActivityInput struct {
 	Title              string
 	Description        string
 	Location           string
+	VenueID            *uint
 	Type               string
 	CollabType         string
 	CollaboratorOrgIDs []string
@@ -39,6 +45,8 @@ type CreateActivityInput struct {
 	CoverKey           string
 	Metadata           map[string]any
 	CreatedBy          uuid.UUID
+	RRule              string      // RFC 5545 RRULE, kosong untuk kegiatan tunggal
+	ExDates            []time.Time // kemunculan yang dikecualikan
 }
 
 func (s *ActivityService) Create(ctx context.Context, in *CreateActivityInput) (*model.Activity, error) {
@@ -75,6 +83,7 @@ func (s *ActivityService) Create(ctx context.Context, in *CreateActivityInput) (
 		Title:              in.Title,
 		Description:        in.Description,
 		Location:           in.Location,
+		VenueID:            in.VenueID,
 		Type:               in.Type,
 		CollabType:         collabType,
 		CollaboratorOrgIDs: collabJSON,
@@ -87,6 +96,12 @@ func (s *ActivityService) Create(ctx context.Context, in *CreateActivityInput) (
 		CreatedBy:          in.CreatedBy,
 		UpdatedBy:          in.CreatedBy,
 	}
+	if err := applyRecurrence(a, in.RRule, in.ExDates); err != nil {
+		return nil, err
+	}
+	if err := s.applyVenue(ctx, a); err != nil {
+		return nil, err
+	}
 	if err := s.repo.Create(ctx, a); err != nil {
 		return nil, err
 	}
@@ -102,12 +117,27 @@ func (s *ActivityService) Submit(ctx context.Context, userID uuid.UUID, id uuid.
 	if a.Status != model.ActivityStatusDraft {
 		return nil, errors.New("only draft can be submitted")
 	}
-	a.Status = model.ActivityStatusPending
-	a.UpdatedBy = userID
-	if err := s.repo.Update(ctx, a); err != nil {
+	if a.SeriesID != nil {
+		return nil, errors.New("kegiatan bagian dari seri, lakukan pada kegiatan induk seri")
+	}
+	// cek bentrok dan simpan di bawah kunci ruangan agar dua pengajuan tidak lolos bersamaan
+	err = s.withVenueLock(ctx, a.VenueID, func() error {
+		if err := s.checkVenue(ctx, a, false); err != nil {
+			return err
+		}
+		a.Status = model.ActivityStatusPending
+		a.UpdatedBy = userID
+		return s.repo.Update(ctx, a)
+	})
+	if err != nil {
 		return nil, err
 	}
-	s.appendHistory(ctx, a, userID, "SUBMIT", "")
+	s.syncSeriesStatus(ctx, a, model.ActivityStatusDraft)
+	note := ""
+	if a.VenueConflict {
+		note = "jadwal ruangan bentrok"
+	}
+	s.appendHistory(ctx, a, userID, "SUBMIT", note)
 	s.audit.Log(ctx, userID, "activity_submit", map[string]any{"activity_id": a.ID})
 	_ = s.notify.Push(ctx, userID, "Proposal diajukan", a.Title, map[string]any{"activity_id": a.ID})
 	return a, nil
@@ -127,17 +157,32 @@ func (s *ActivityService) Approve(ctx context.Context, approver uuid.UUID, id uu
 	if a.Status != model.ActivityStatusPending {
 		return nil, errors.New("not pending")
 	}
-	if approve {
-		a.Status = model.ActivityStatusApproved
-		// cover approval manual; default false, set true via explicit endpoint
-	} else {
-		a.Status = model.ActivityStatusRejected
+	if a.SeriesID != nil {
+		// bagian seri hanya diputuskan sendiri saat jadwalnya diubah setelah seri disetujui
+		root, err := s.repo.Get(ctx, *a.SeriesID)
+		if err != nil || root.Status != model.ActivityStatusApproved {
+			return nil, errors.New("kegiatan bagian dari seri, lakukan pada kegiatan induk seri")
+		}
 	}
-	a.ApprovalNote = note
-	a.UpdatedBy = approver
-	if err := s.repo.Update(ctx, a); err != nil {
+	err = s.withVenueLock(ctx, a.VenueID, func() error {
+		if approve {
+			// bentrok dengan kegiatan yang sudah disetujui lebih dulu tidak bisa di-approve
+			if err := s.checkVenue(ctx, a, true); err != nil {
+				return err
+			}
+			a.Status = model.ActivityStatusApproved
+			// cover approval manual; default false, set true via explicit endpoint
+		} else {
+			a.Status = model.ActivityStatusRejected
+		}
+		a.ApprovalNote = note
+		a.UpdatedBy = approver
+		return s.repo.Update(ctx, a)
+	})
+	if err != nil {
 		return nil, err
 	}
+	s.syncSeriesStatus(ctx, a, model.ActivityStatusPending)
 	_ = s.notify.Push(ctx, a.CreatedBy, "Proposal diperbarui", a.Status, map[string]any{"activity_id": a.ID})
 	s.appendHistory(ctx, a, approver, map[bool]string{true: "APPROVE", false: "REJECT"}[approve], note)
 	s.audit.Log(ctx, approver, "activity_approve", map[string]any{"activity_id": a.ID, "approve": approve})
@@ -149,15 +194,78 @@ func (s *ActivityService) MarkCompleted(ctx context.Context, id uuid.UUID, userI
 	if err != nil {
 		return nil, err
 	}
+	now := time.Now()
+	due := a.EndAt.Add(s.lpjDue)
+	if a.EndAt.After(now) {
+		due = now.Add(s.lpjDue)
+	}
 	a.Status = model.ActivityStatusCompleted
+	// menandai ulang kegiatan yang sudah selesai tidak menggeser tenggat LPJ
+	if a.CompletedAt == nil {
+		a.CompletedAt = &now
+	}
+	if a.LPJDueAt == nil {
+		a.LPJDueAt = &due
+	}
 	a.UpdatedBy = userID
 	if err := s.repo.Update(ctx, a); err != nil {
 		return nil, err
 	}
 	s.appendHistory(ctx, a, userID, "COMPLETE", "")
+	s.notifyLPJDue(ctx, a)
 	return a, nil
 }
 
+// completeOverdueBatch membatasi jumlah kegiatan yang ditutup per putaran job.
+const completeOverdueBatch = 100
+
+// CompleteOverdue menutup kegiatan APPROVED yang EndAt-nya sudah lewat, mencatat
+// riwayat dengan aktor sistem, lalu membuka kewajiban LPJ. Dipanggil berkala oleh
+// server dan aman dijalankan beberapa replika sekaligus (lihat repository).
+func (s *ActivityService) CompleteOverdue(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		rows, err := s.repo.CompleteOverdue(ctx, time.Now(), s.lpjDue, completeOverdueBatch)
+		if err != nil {
+			return total, err
+		}
+		for i := range rows {
+			a := &rows[i]
+			s.appendHistory(ctx, a, model.SystemActorID, "AUTO_COMPLETE", "kegiatan berakhir")
+			s.audit.Log(ctx, model.SystemActorID, "activity_auto_complete", map[string]any{"activity_id": a.ID, "org_id": a.OrgID})
+			s.notifyLPJDue(ctx, a)
+		}
+		total += len(rows)
+		if len(rows) < completeOverdueBatch {
+			return total, nil
+		}
+	}
+}
+
+// syncSeriesStatus meneruskan status induk seri berulang ke pecahan seri dan
+// kemunculan yang diubah yang masih berstatus from, sehingga persetujuan cukup
+// sekali per seri.
+func (s *ActivityService) syncSeriesStatus(ctx context.Context, a *model.Activity, from string) {
+	if a.RRule == "" || a.SeriesID != nil {
+		return
+	}
+	if err := s.repo.UpdateSeriesStatus(ctx, a.ID, from, a.Status); err != nil {
+		fmt.Printf("[ACTIVITY] sinkron status seri %s gagal: %v\n", a.ID, err)
+	}
+}
+
+// notifyLPJDue memberi tahu pembuat kegiatan bahwa LPJ wajib dikirim.
+func (s *ActivityService) notifyLPJDue(ctx context.Context, a *model.Activity) {
+	if s.notify == nil {
+		return
+	}
+	body := a.Title
+	if a.LPJDueAt != nil {
+		body = fmt.Sprintf("%s: kirim LPJ sebelum %s", a.Title, a.LPJDueAt.Format("02-01-2006"))
+	}
+	_ = s.notify.Push(ctx, a.CreatedBy, "Kegiatan selesai, LPJ dibuka", body, map[string]any{"activity_id": a.ID})
+}
+
 func (s *ActivityService) ListByOrg(ctx context.Context, orgID uuid.UUID, status, actType string, publicOnly bool, page, size int, start, end time.Time) ([]model.Activity, error) {
 	return s.repo.List(ctx, orgID, status, actType, publicOnly, page, size, start, end)
 }
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/repository"
+)
+
+const (
+	VenueConflictReject = "REJECT" // pengajuan yang bentrok ditolak
+	VenueConflictFlag   = "FLAG"   // pengajuan diterima, kegiatan ditandai VenueConflict
+)
+
+// venueConflictHorizon membatasi pengecekan bentrok seri berulang tanpa akhir.
+const venueConflictHorizon = 365 * 24 * time.Hour
+
+// VenueConflict adalah kemunculan kegiatan lain yang jadwal ruangannya bertumpuk
+// dengan kemunculan OccurrenceStart milik kegiatan yang dicek.
+type VenueConflict struct {
+	ActivityID      uuid.UUID `json:"activity_id"`
+	OrgID           uuid.UUID `json:"org_id"`
+	Title           string    `json:"title"`
+	Status          string    `json:"status"`
+	StartAt         time.Time `json:"start_at"`
+	EndAt           time.Time `json:"end_at"`
+	OccurrenceStart time.Time `json:"occurrence_start"`
+}
+
+// VenueBooking adalah satu slot terpakai pada jadwal ruangan.
+type VenueBooking struct {
+	ActivityID uuid.UUID `json:"activity_id"`
+	OrgID      uuid.UUID `json:"org_id"`
+	Title      string    `json:"title"`
+	Status     string    `json:"status"`
+	Public     bool      `json:"public"`
+	StartAt    time.Time `json:"start_at"`
+	EndAt      time.Time `json:"end_at"`
+}
+
+// applyVenue memvalidasi ruangan kegiatan dan mengisi Location dengan nama ruangan.
+func (s *ActivityService) applyVenue(ctx context.Context, a *model.Activity) error {
+	if a.VenueID == nil {
+		return nil
+	}
+	if s.venues == nil {
+		return errors.New("katalog ruangan belum dikonfigurasi")
+	}
+	v, err := s.venues.Get(ctx, *a.VenueID)
+	if err != nil {
+		return errors.New("ruangan tidak ditemukan")
+	}
+	if !v.Active {
+		return fmt.Errorf("ruangan %s sedang tidak bisa dipesan", v.Name)
+	}
+	if strings.TrimSpace(a.Location) == "" {
+		a.Location = v.Name
+	}
+	return nil
+}
+
+// checkVenue mengecek bentrok jadwal ruangan kegiatan a. Pada mode REJECT bentrok
+// menjadi error; pada mode FLAG hanya mengisi a.VenueConflict. approvedOnly
+// membatasi pembanding ke kegiatan yang sudah disetujui (dipakai saat approval).
+func (s *ActivityService) checkVenue(ctx context.Context, a *model.Activity, approvedOnly bool) error {
+	a.VenueConflict = false
+	if a.VenueID == nil {
+		return nil
+	}
+	conflicts, err := s.venueConflicts(ctx, a, approvedOnly)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	if s.flagVenueConflicts() {
+		a.VenueConflict = true
+		return nil
+	}
+	return venueConflictError(conflicts)
+}
+
+func (s *ActivityService) flagVenueConflicts() bool {
+	return strings.EqualFold(s.venueConflict, VenueConflictFlag)
+}
+
+func venueConflictError(conflicts []VenueConflict) error {
+	c := conflicts[0]
+	msg := fmt.Sprintf("jadwal ruangan bentrok dengan %q (%s - %s)", c.Title, c.StartAt.Format("02-01-2006 15:04"), c.EndAt.Format("15:04"))
+	if len(conflicts) > 1 {
+		msg += fmt.Sprintf(" dan %d jadwal lain", len(conflicts)-1)
+	}
+	return errors.New(msg)
+}
+
+// withVenueLock menjalankan fn selama memegang kunci jadwal ruangan venueID agar
+// dua pengajuan/edit pada ruangan yang sama tidak lolos cek bentrok bersamaan.
+func (s *ActivityService) withVenueLock(ctx context.Context, venueID *uint, fn func() error) error {
+	if venueID == nil || s.venues == nil {
+		return fn()
+	}
+	return s.venues.WithLock(ctx, *venueID, fn)
+}
+
+// checkVenueEdit mengecek jadwal hasil edit kemunculan/seri (belum disimpan) milik
+// seri a yang sudah diajukan. Hanya berlaku pada mode REJECT; mode FLAG memperbarui
+// tanda bentrok lewat refreshVenueFlag setelah edit disimpan.
+func (s *ActivityService) checkVenueEdit(ctx context.Context, a, edited *model.Activity) error {
+	if a.VenueID == nil || s.flagVenueConflicts() {
+		return nil
+	}
+	if a.Status != model.ActivityStatusPending && a.Status != model.ActivityStatusApproved {
+		return nil
+	}
+	own := []model.Activity{*edited}
+	from, to := edited.StartAt, edited.EndAt
+	if edited.RRule != "" {
+		to = from.Add(venueConflictHorizon)
+		if edited.RecurUntil != nil && edited.RecurUntil.Before(to) {
+			to = *edited.RecurUntil
+		}
+		own = expandSeries(edited, nil, from, to)
+	}
+	conflicts, err := s.slotConflicts(ctx, *a.VenueID, seriesRoot(a), own, from, to, false)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return venueConflictError(conflicts)
+	}
+	return nil
+}
+
+// refreshVenueFlag menghitung ulang tanda VenueConflict induk seri rootID setelah
+// kemunculannya diubah atau dibatalkan (mode FLAG).
+func (s *ActivityService) refreshVenueFlag(ctx context.Context, rootID uuid.UUID) {
+	if !s.flagVenueConflicts() {
+		return
+	}
+	root, err := s.repo.Get(ctx, rootID)
+	if err != nil || root.VenueID == nil {
+		return
+	}
+	if root.Status != model.ActivityStatusPending && root.Status != model.ActivityStatusApproved {
+		return
+	}
+	was := root.VenueConflict
+	if err := s.checkVenue(ctx, root, false); err != nil {
+		fmt.Printf("[ACTIVITY] cek bentrok ruangan seri %s gagal: %v\n", root.ID, err)
+		return
+	}
+	if root.VenueConflict != was {
+		if err := s.repo.Update(ctx, root); err != nil {
+			fmt.Printf("[ACTIVITY] simpan tanda bentrok seri %s gagal: %v\n", root.ID, err)
+		}
+	}
+}
+
+// VenueConflicts mengembalikan jadwal kegiatan lain (PENDING/APPROVED) yang bentrok
+// dengan kegiatan id, untuk ditampilkan di layar approval.
+// Hanya pengurus org pemilik kegiatan atau approver kegiatan yang boleh melihatnya.
+func (s *ActivityService) VenueConflicts(ctx context.Context, userID, id uuid.UUID) ([]VenueConflict, error) {
+	a, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if canApprove, err := s.rbac.CanApproveActivity(ctx, userID); err != nil || !canApprove {
+		if err := s.canManage(ctx, userID, a); err != nil {
+			return nil, err
+		}
+	}
+	if a.SeriesID != nil {
+		if root, err := s.repo.Get(ctx, *a.SeriesID); err == nil {
+			a = root
+		}
+	}
+	if a.VenueID == nil {
+		return []VenueConflict{}, nil
+	}
+	return s.venueConflicts(ctx, a, false)
+}
+
+func (s *ActivityService) venueConflicts(ctx context.Context, a *model.Activity, approvedOnly bool) ([]VenueConflict, error) {
+	own, from, to, err := s.seriesOccurrences(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	return s.slotConflicts(ctx, *a.VenueID, seriesRoot(a), own, from, to, approvedOnly)
+}
+
+// slotConflicts mencocokkan kemunculan own (terurut) dengan jadwal kegiatan lain di
+// ruangan venueID pada [from, to], selain seri root sendiri.
+func (s *ActivityService) slotConflicts(ctx context.Context, venueID uint, root uuid.UUID, own []model.Activity, from, to time.Time, approvedOnly bool) ([]VenueConflict, error) {
+	statuses := []string{model.ActivityStatusPending, model.ActivityStatusApproved}
+	if approvedOnly {
+		statuses = []string{model.ActivityStatusApproved}
+	}
+	others, err := s.Occurrences(ctx, repository.ActivityWindowQuery{VenueID: &venueID, Statuses: statuses, From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+	if len(others) >= maxOccurrences {
+		return nil, errTooManyOccurrences
+	}
+	out := []VenueConflict{}
+	for _, o := range own {
+		for _, x := range others {
+			if !x.StartAt.Before(o.EndAt) {
+				break // others terurut StartAt
+			}
+			if seriesRoot(&x) == root || !o.StartAt.Before(x.EndAt) {
+				continue
+			}
+			out = append(out, VenueConflict{
+				ActivityID:      seriesRoot(&x),
+				OrgID:           x.OrgID,
+				Title:           x.Title,
+				Status:          x.Status,
+				StartAt:         x.StartAt,
+				EndAt:           x.EndAt,
+				OccurrenceStart: o.StartAt,
+			})
+		}
+	}
+	return out, nil
+}
+
+// seriesOccurrences menguraikan semua kemunculan kegiatan a (beserta pecahan seri
+// dan kemunculan yang diubah) dan rentang waktu yang dicakupnya.
+func (s *ActivityService) seriesOccurrences(ctx context.Context, a *model.Activity) ([]model.Activity, time.Time, time.Time, error) {
+	if a.RRule == "" && a.SeriesID == nil {
+		return []model.Activity{*a}, a.StartAt, a.EndAt, nil
+	}
+	series, err := s.repo.ListSeries(ctx, seriesRoot(a))
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
+	var masters, overrides []model.Activity
+	for _, row := range series {
+		if row.RecurrenceID != nil {
+			overrides = append(overrides, row)
+		} else {
+			masters = append(masters, row)
+		}
+	}
+	if len(masters) == 0 {
+		return []model.Activity{*a}, a.StartAt, a.EndAt, nil
+	}
+	from, to := masters[0].StartAt, masters[0].EndAt
+	infinite := false
+	for _, m := range append(masters, overrides...) {
+		if m.StartAt.Before(from) {
+			from = m.StartAt
+		}
+		end := m.EndAt
+		if m.RecurUntil != nil {
+			end = *m.RecurUntil
+		} else if m.RRule != "" {
+			infinite = true
+		}
+		if end.After(to) {
+			to = end
+		}
+	}
+	if infinite || to.Sub(from) > venueConflictHorizon {
+		to = from.Add(venueConflictHorizon)
+	}
+	var out []model.Activity
+	for i := range masters {
+		if masters[i].RRule == "" {
+			out = append(out, masters[i])
+			continue
+		}
+		out = append(out, expandSeries(&masters[i], overrides, from, to)...)
+		if len(out) >= maxOccurrences {
+			return nil, time.Time{}, time.Time{}, errTooManyOccurrences
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartAt.Before(out[j].StartAt) })
+	return out, from, to, nil
+}
+
+// VenueBookings mengembalikan slot ruangan yang terpakai di [from, to]: kegiatan
+// APPROVED, ditambah PENDING bila includePending.
+func (s *ActivityService) VenueBookings(ctx context.Context, venueID uint, from, to time.Time, includePending bool) ([]VenueBooking, error) {
+	statuses := []string{model.ActivityStatusApproved}
+	if includePending {
+		statuses = append(statuses, model.ActivityStatusPending)
+	}
+	rows, err := s.Occurrences(ctx, repository.ActivityWindowQuery{VenueID: &venueID, Statuses: statuses, From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]VenueBooking, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, VenueBooking{
+			ActivityID: seriesRoot(&r),
+			OrgID:      r.OrgID,
+			Title:      r.Title,
+			Status:     r.Status,
+			Public:     r.Public,
+			StartAt:    r.StartAt,
+			EndAt:      r.EndAt,
+		})
+	}
+	return out, nil
+}
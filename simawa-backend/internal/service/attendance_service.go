@@ -0,0 +1,274 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/repository"
+	"simawa-backend/internal/util/checkin"
+)
+
+type AttendanceService struct {
+	repo         repository.ActivityAttendanceRepository
+	activity     *ActivityService
+	registration repository.ActivityRegistrationRepository
+	users        repository.UserRepository
+	audit        *AuditService
+	signer       *checkin.Signer
+	grace        time.Duration // presensi dibuka sejak grace sebelum mulai sampai grace sesudah selesai
+}
+
+func NewAttendanceService(repo repository.ActivityAttendanceRepository, activity *ActivityService, registration repository.ActivityRegistrationRepository, users repository.UserRepository, audit *AuditService, signer *checkin.Signer, grace time.Duration) *AttendanceService {
+	return &AttendanceService{repo: repo, activity: activity, registration: registration, users: users, audit: audit, signer: signer, grace: grace}
+}
+
+// CheckInToken adalah token QR yang sedang berlaku.
+type CheckInToken struct {
+	Token           string     `json:"token"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	RotateSeconds   int        `json:"rotate_seconds"`
+	OccurrenceStart *time.Time `json:"occurrence_start,omitempty"`
+}
+
+// CheckInResult adalah hasil satu presensi; Duplicate bila peserta sudah tercatat.
+type CheckInResult struct {
+	Attendance *model.ActivityAttendance `json:"attendance"`
+	Activity   *model.Activity           `json:"activity"`
+	Duplicate  bool                      `json:"duplicate"`
+}
+
+// AttendanceOverview adalah rekap presensi untuk penyelenggara.
+type AttendanceOverview struct {
+	*repository.AttendanceSummary
+	Registered int64 `json:"registered"` // pendaftar RSVP berstatus REGISTERED
+}
+
+// manageable memuat induk seri kegiatan dan memastikan userID boleh mengelolanya.
+func (s *AttendanceService) manageable(ctx context.Context, userID, activityID uuid.UUID) (*model.Activity, error) {
+	a, err := s.activity.Get(ctx, activityID)
+	if err != nil {
+		return nil, errors.New("kegiatan tidak ditemukan")
+	}
+	if a.SeriesID != nil {
+		if root, err := s.activity.Get(ctx, *a.SeriesID); err == nil {
+			a = root
+		}
+	}
+	if err := s.activity.canManage(ctx, userID, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *AttendanceService) session(ctx context.Context, a *model.Activity, now time.Time) (*model.Activity, time.Time, error) {
+	if a.Status != model.ActivityStatusApproved && a.Status != model.ActivityStatusCompleted {
+		return nil, time.Time{}, errors.New("presensi hanya untuk kegiatan yang sudah disetujui")
+	}
+	occ, err := s.activity.OccurrenceAt(ctx, a, now, s.grace)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if occ.Status != model.ActivityStatusApproved && occ.Status != model.ActivityStatusCompleted {
+		return nil, time.Time{}, errors.New("jadwal kemunculan ini menunggu persetujuan ulang")
+	}
+	start := occ.StartAt
+	if occ.RecurrenceID != nil {
+		start = *occ.RecurrenceID
+	}
+	return occ, start, nil
+}
+
+// ActivityToken menerbitkan QR presensi kegiatan yang berganti tiap jendela rotasi.
+// Hanya diterbitkan selama ada sesi yang sedang berlangsung.
+func (s *AttendanceService) ActivityToken(ctx context.Context, userID, activityID uuid.UUID) (*CheckInToken, error) {
+	a, err := s.manageable(ctx, userID, activityID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	_, start, err := s.session(ctx, a, now)
+	if err != nil {
+		return nil, err
+	}
+	token, exp := s.signer.Issue(checkin.KindActivity, a.ID, now)
+	return &CheckInToken{Token: token, ExpiresAt: exp, RotateSeconds: int(s.signer.Rotate / time.Second), OccurrenceStart: &start}, nil
+}
+
+// PersonalToken menerbitkan QR pribadi peserta untuk dipindai penyelenggara.
+func (s *AttendanceService) PersonalToken(userID uuid.UUID) *CheckInToken {
+	token, exp := s.signer.Issue(checkin.KindUser, userID, time.Now())
+	return &CheckInToken{Token: token, ExpiresAt: exp, RotateSeconds: int(s.signer.Rotate / time.Second)}
+}
+
+// CheckIn mencatat presensi peserta yang memindai QR kegiatan.
+func (s *AttendanceService) CheckIn(ctx context.Context, userID uuid.UUID, token string) (*CheckInResult, error) {
+	now := time.Now()
+	kind, activityID, err := s.signer.Verify(strings.TrimSpace(token), now)
+	if err != nil {
+		return nil, err
+	}
+	if kind != checkin.KindActivity {
+		return nil, errors.New("QR ini bukan QR presensi kegiatan")
+	}
+	a, err := s.activity.Get(ctx, activityID)
+	if err != nil {
+		return nil, errors.New("kegiatan tidak ditemukan")
+	}
+	return s.record(ctx, a, userID, userID, model.AttendanceMethodSelf, now)
+}
+
+// Scan mencatat presensi dari QR pribadi peserta yang dipindai penyelenggara.
+func (s *AttendanceService) Scan(ctx context.Context, organizerID, activityID uuid.UUID, token string) (*CheckInResult, error) {
+	a, err := s.manageable(ctx, organizerID, activityID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	kind, participant, err := s.signer.Verify(strings.TrimSpace(token), now)
+	if err != nil {
+		return nil, err
+	}
+	if kind != checkin.KindUser {
+		return nil, errors.New("QR ini bukan QR pribadi peserta")
+	}
+	if s.users != nil {
+		if _, err := s.users.GetByUUID(ctx, participant); err != nil {
+			return nil, errors.New("peserta tidak ditemukan")
+		}
+	}
+	return s.record(ctx, a, participant, organizerID, model.AttendanceMethodScan, now)
+}
+
+func (s *AttendanceService) record(ctx context.Context, a *model.Activity, userID, recordedBy uuid.UUID, method string, now time.Time) (*CheckInResult, error) {
+	occ, start, err := s.session(ctx, a, now)
+	if err != nil {
+		return nil, err
+	}
+	row := &model.ActivityAttendance{
+		ActivityID:      seriesRoot(a),
+		OccurrenceStart: start,
+		UserID:          userID,
+		Method:          method,
+		RecordedBy:      recordedBy,
+		CheckedInAt:     now,
+	}
+	created, err := s.repo.Record(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		existing, err := s.repo.Get(ctx, row.ActivityID, start, userID)
+		if err != nil {
+			return nil, err
+		}
+		return &CheckInResult{Attendance: existing, Activity: occ, Duplicate: true}, nil
+	}
+	s.audit.Log(ctx, recordedBy, "activity_check_in", map[string]any{"activity_id": row.ActivityID, "user_id": userID, "method": method, "occurrence": start})
+	return &CheckInResult{Attendance: row, Activity: occ}, nil
+}
+
+// Overview mengembalikan jumlah presensi terkini (dipakai layar live penyelenggara).
+func (s *AttendanceService) Overview(ctx context.Context, userID, activityID uuid.UUID) (*AttendanceOverview, error) {
+	a, err := s.manageable(ctx, userID, activityID)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := s.repo.Summary(ctx, a.ID)
+	if err != nil {
+		return nil, err
+	}
+	out := &AttendanceOverview{AttendanceSummary: sum}
+	if s.registration != nil {
+		if counts, err := s.registration.CountByStatus(ctx, a.ID); err == nil {
+			out.Registered = counts[model.RegistrationStatusRegistered]
+		}
+	}
+	return out, nil
+}
+
+func (s *AttendanceService) List(ctx context.Context, userID, activityID uuid.UUID) ([]model.ActivityAttendance, error) {
+	a, err := s.manageable(ctx, userID, activityID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, a.ID)
+}
+
+// Export menghasilkan CSV daftar hadir kegiatan.
+func (s *AttendanceService) Export(ctx context.Context, userID, activityID uuid.UUID) ([]byte, string, error) {
+	a, err := s.manageable(ctx, userID, activityID)
+	if err != nil {
+		return nil, "", err
+	}
+	return attendanceCSV(ctx, s.repo, a)
+}
+
+// attendanceCSV dipakai juga oleh LPJ sebagai bukti kehadiran.
+func attendanceCSV(ctx context.Context, repo repository.ActivityAttendanceRepository, a *model.Activity) ([]byte, string, error) {
+	rows, err := attendanceOf(ctx, repo, a)
+	if err != nil {
+		return nil, "", err
+	}
+	b := &bytes.Buffer{}
+	w := csv.NewWriter(b)
+	_ = w.Write([]string{"No", "Nama", "NIM", "Email", "Sesi", "Waktu Hadir", "Metode"})
+	for i, r := range rows {
+		name, nim, email := "", "", ""
+		if r.User != nil {
+			name = strings.TrimSpace(r.User.FirstName + " " + r.User.SecondName)
+			nim, email = r.User.NIM, r.User.Email
+		}
+		_ = w.Write([]string{
+			strconv.Itoa(i + 1),
+			name,
+			nim,
+			email,
+			r.OccurrenceStart.Format("2006-01-02 15:04"),
+			r.CheckedInAt.Format("2006-01-02 15:04:05"),
+			r.Method,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, "", err
+	}
+	filename := fmt.Sprintf("presensi_%s_%s.csv", a.ID.String()[:8], time.Now().Format("20060102"))
+	return b.Bytes(), filename, nil
+}
+
+// attendanceOf mengembalikan presensi kemunculan milik a saja. Presensi seri dicatat
+// pada induk seri, jadi pecahan seri dan kemunculan yang diubah disaring dari sana.
+func attendanceOf(ctx context.Context, repo repository.ActivityAttendanceRepository, a *model.Activity) ([]model.ActivityAttendance, error) {
+	rows, err := repo.List(ctx, seriesRoot(a))
+	if err != nil {
+		return nil, err
+	}
+	out := rows[:0]
+	for _, r := range rows {
+		if ownsOccurrence(a, r.OccurrenceStart) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// ownsOccurrence melaporkan apakah kemunculan yang dimulai pada start termasuk a.
+func ownsOccurrence(a *model.Activity, start time.Time) bool {
+	if a.RecurrenceID != nil {
+		return start.Equal(*a.RecurrenceID)
+	}
+	end := a.EndAt
+	if a.RecurUntil != nil {
+		end = *a.RecurUntil
+	}
+	return !start.Before(a.StartAt) && !start.After(end)
+}
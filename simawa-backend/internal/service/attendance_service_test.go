@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"simawa-backend/internal/model"
+)
+
+func TestOwnsOccurrence(t *testing.T) {
+	at := func(d int) time.Time { return time.Date(2026, 1, d, 9, 0, 0, 0, time.Local) }
+	until := at(12).Add(2 * time.Hour)
+	head := &model.Activity{StartAt: at(5), EndAt: at(5).Add(2 * time.Hour), RRule: "FREQ=DAILY", RecurUntil: &until}
+	rid := at(8)
+	override := &model.Activity{StartAt: at(8).Add(time.Hour), EndAt: at(8).Add(3 * time.Hour), RecurrenceID: &rid}
+	single := &model.Activity{StartAt: at(5), EndAt: at(5).Add(2 * time.Hour)}
+	tests := []struct {
+		name  string
+		a     *model.Activity
+		start time.Time
+		want  bool
+	}{
+		{"awal seri", head, at(5), true},
+		{"akhir seri", head, at(12), true},
+		{"sebelum seri", head, at(4), false},
+		{"pecahan sesudahnya", head, at(13), false},
+		{"kemunculan diubah", override, at(8), true},
+		{"kemunculan lain", override, at(9), false},
+		{"kegiatan tunggal", single, at(5), true},
+		{"kegiatan tunggal sesi lain", single, at(6), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ownsOccurrence(tt.a, tt.start); got != tt.want {
+				t.Fatalf("ownsOccurrence(%v) = %v, want %v", tt.start, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,9 +2,13 @@ package service
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"html/template"
+	"mime"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 
 	"simawa-backend/internal/config"
 )
@@ -18,15 +22,34 @@ func NewEmailService(cfg *config.SMTPEnv) *EmailService {
 }
 
 type EmailData struct {
-	To      string
-	Subject string
-	Body    string
+	To          string
+	Subject     string
+	Body        string
+	Attachments []EmailAttachment
+}
+
+// EmailAttachment adalah berkas yang dilampirkan ke email (mis. PDF surat).
+type EmailAttachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Configured reports whether Send will actually deliver instead of printing the email.
+func (s *EmailService) Configured() bool {
+	if s == nil || s.cfg == nil {
+		return false
+	}
+	if s.cfg.NoAuth {
+		return s.cfg.Host != ""
+	}
+	return s.cfg.User != "" && s.cfg.Password != ""
 }
 
 func (s *EmailService) Send(data EmailData) error {
 	fmt.Printf("[EMAIL] SMTP Config: Host=%s, Port=%d, User=%s, From=%s\n", s.cfg.Host, s.cfg.Port, s.cfg.User, s.cfg.From)
 	
-	if s.cfg.User == "" || s.cfg.Password == "" {
+	if !s.Configured() {
 		// Skip sending if SMTP not configured (dev mode)
 		fmt.Printf("[EMAIL] SMTP not configured (User or Password empty) - printing email instead\n")
 		fmt.Printf("[EMAIL] Would send to %s: %s\n%s\n", data.To, data.Subject, data.Body)
@@ -35,13 +58,13 @@ func (s *EmailService) Send(data EmailData) error {
 
 	fmt.Printf("[EMAIL] Sending email to %s via %s:%d\n", data.To, s.cfg.Host, s.cfg.Port)
 	
-	auth := smtp.PlainAuth("", s.cfg.User, s.cfg.Password, s.cfg.Host)
+	var auth smtp.Auth
+	if !s.cfg.NoAuth {
+		auth = smtp.PlainAuth("", s.cfg.User, s.cfg.Password, s.cfg.Host)
+	}
 
 	from := fmt.Sprintf("%s <%s>", s.cfg.FromName, s.cfg.From)
-	msg := []byte(fmt.Sprintf(
-		"From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/html; charset=\"UTF-8\"\r\n\r\n%s",
-		from, data.To, data.Subject, data.Body,
-	))
+	msg := buildMessage(from, data)
 
 	addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
 	err := smtp.SendMail(addr, auth, s.cfg.From, []string{data.To}, msg)
@@ -53,6 +76,113 @@ func (s *EmailService) Send(data EmailData) error {
 	return nil
 }
 
+// buildMessage menyusun pesan HTML; bila ada lampiran dipakai multipart/mixed.
+func buildMessage(from string, data EmailData) []byte {
+	subject := mime.QEncoding.Encode("utf-8", data.Subject)
+	if len(data.Attachments) == 0 {
+		return []byte(fmt.Sprintf(
+			"From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/html; charset=\"UTF-8\"\r\n\r\n%s",
+			from, data.To, subject, data.Body,
+		))
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	html, _ := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {`text/html; charset="UTF-8"`}})
+	_, _ = html.Write([]byte(data.Body))
+	for _, a := range data.Attachments {
+		name := mime.QEncoding.Encode("utf-8", a.Name)
+		part, _ := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {fmt.Sprintf("%s; name=\"%s\"", a.ContentType, name)},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=\"%s\"", name)},
+		})
+		enc := base64.StdEncoding.EncodeToString(a.Data)
+		for len(enc) > 76 {
+			_, _ = part.Write([]byte(enc[:76] + "\r\n"))
+			enc = enc[76:]
+		}
+		_, _ = part.Write([]byte(enc + "\r\n"))
+	}
+	_ = mw.Close()
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\n", from, data.To, subject)
+	fmt.Fprintf(&b, "Content-Type: multipart/mixed; boundary=\"%s\"\r\n\r\n", mw.Boundary())
+	b.Write(body.Bytes())
+	return b.Bytes()
+}
+
+// SendSurat mengirim PDF surat yang sudah disetujui ke penerima atau tembusannya.
+func (s *EmailService) SendSurat(to, subject, intro, fileName string, pdf []byte) error {
+	body, err := s.renderSuratTemplate(subject, intro)
+	if err != nil {
+		return err
+	}
+	return s.Send(EmailData{
+		To:          to,
+		Subject:     subject,
+		Body:        body,
+		Attachments: []EmailAttachment{{Name: fileName, ContentType: "application/pdf", Data: pdf}},
+	})
+}
+
+func (s *EmailService) renderSuratTemplate(title, message string) (string, error) {
+	tmpl := `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; background-color: #f4f7fa;">
+    <table role="presentation" style="width: 100%; border-collapse: collapse;">
+        <tr>
+            <td align="center" style="padding: 40px 0;">
+                <table role="presentation" style="width: 100%; max-width: 600px; border-collapse: collapse; background-color: #ffffff; border-radius: 16px;">
+                    <tr>
+                        <td style="padding: 32px 40px 16px; background: linear-gradient(135deg, #1e40af 0%, #3b82f6 100%); border-radius: 16px 16px 0 0;">
+                            <h1 style="margin: 0; color: #ffffff; font-size: 22px; font-weight: 700;">SIMAWA</h1>
+                        </td>
+                    </tr>
+                    <tr>
+                        <td style="padding: 32px 40px;">
+                            <h2 style="margin: 0 0 16px; color: #1e293b; font-size: 20px;">{{.Title}}</h2>
+                            <p style="margin: 0 0 16px; color: #475569; font-size: 15px; line-height: 1.6;">{{.Message}}</p>
+                            <p style="margin: 0; color: #94a3b8; font-size: 13px;">Surat terlampir dalam format PDF dan dapat diverifikasi melalui QR code pada surat.</p>
+                        </td>
+                    </tr>
+                    <tr>
+                        <td style="padding: 20px 40px; background-color: #f8fafc; border-radius: 0 0 16px 16px; border-top: 1px solid #e2e8f0;">
+                            <p style="margin: 0; color: #94a3b8; font-size: 12px; text-align: center;">
+                                © 2024 SIMAWA - Universitas Raharja<br>
+                                Email ini dikirim secara otomatis, mohon tidak membalas email ini.
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</body>
+</html>`
+
+	t, err := template.New("surat").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = t.Execute(&buf, map[string]string{
+		"Title":   title,
+		"Message": message,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
 // SendOTP sends OTP code for login verification
 func (s *EmailService) SendOTP(to, otp, purpose string) error {
 	var subject, title, message string
@@ -256,3 +386,74 @@ func (s *EmailService) renderWelcomeTemplate(name string) (string, error) {
 
 	return buf.String(), nil
 }
+
+// SendNotice mengirim pemberitahuan singkat (mis. status pendaftaran kegiatan);
+// link opsional ditampilkan sebagai tombol.
+func (s *EmailService) SendNotice(to, subject, title, message, link string) error {
+	body, err := s.renderNoticeTemplate(title, message, link)
+	if err != nil {
+		return err
+	}
+	return s.Send(EmailData{
+		To:      to,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+func (s *EmailService) renderNoticeTemplate(title, message, link string) (string, error) {
+	tmpl := `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+</head>
+<body style="margin: 0; padding: 0; font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; background-color: #f4f7fa;">
+    <table role="presentation" style="width: 100%; border-collapse: collapse;">
+        <tr>
+            <td align="center" style="padding: 40px 0;">
+                <table role="presentation" style="width: 100%; max-width: 600px; border-collapse: collapse; background-color: #ffffff; border-radius: 16px;">
+                    <tr>
+                        <td style="padding: 32px 40px 16px; background: linear-gradient(135deg, #1e40af 0%, #3b82f6 100%); border-radius: 16px 16px 0 0;">
+                            <h1 style="margin: 0; color: #ffffff; font-size: 22px; font-weight: 700;">SIMAWA</h1>
+                        </td>
+                    </tr>
+                    <tr>
+                        <td style="padding: 32px 40px;">
+                            <h2 style="margin: 0 0 16px; color: #1e293b; font-size: 20px;">{{.Title}}</h2>
+                            <p style="margin: 0 0 24px; color: #475569; font-size: 15px; line-height: 1.6;">{{.Message}}</p>
+                            {{if .Link}}<a href="{{.Link}}" style="display: inline-block; padding: 12px 24px; background-color: #1e40af; color: #ffffff; text-decoration: none; border-radius: 8px; font-size: 14px;">Lihat atau batalkan pendaftaran</a>{{end}}
+                        </td>
+                    </tr>
+                    <tr>
+                        <td style="padding: 20px 40px; background-color: #f8fafc; border-radius: 0 0 16px 16px; border-top: 1px solid #e2e8f0;">
+                            <p style="margin: 0; color: #94a3b8; font-size: 12px; text-align: center;">
+                                © 2024 SIMAWA - Universitas Raharja<br>
+                                Email ini dikirim secara otomatis, mohon tidak membalas email ini.
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</body>
+</html>`
+
+	t, err := template.New("notice").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = t.Execute(&buf, map[string]string{
+		"Title":   title,
+		"Message": message,
+		"Link":    link,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
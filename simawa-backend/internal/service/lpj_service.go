@@ -14,17 +14,18 @@ import (
 )
 
 type LPJService struct {
-	repo    repository.LPJRepository
-	act     repository.ActivityRepository
-	org     repository.OrganizationRepository
-	rbac    *RBACService
-	notify  *NotificationService
-	history repository.LPJHistoryRepository
-	audit   *AuditService
+	repo       repository.LPJRepository
+	act        repository.ActivityRepository
+	org        repository.OrganizationRepository
+	rbac       *RBACService
+	notify     *NotificationService
+	history    repository.LPJHistoryRepository
+	audit      *AuditService
+	attendance repository.ActivityAttendanceRepository
 }
 
-func NewLPJService(repo repository.LPJRepository, act repository.ActivityRepository, org repository.OrganizationRepository, rbac *RBACService, notify *NotificationService, history repository.LPJHistoryRepository, audit *AuditService) *LPJService {
-	return &LPJService{repo: repo, act: act, org: org, rbac: rbac, notify: notify, history: history, audit: audit}
+func NewLPJService(repo repository.LPJRepository, act repository.ActivityRepository, org repository.OrganizationRepository, rbac *RBACService, notify *NotificationService, history repository.LPJHistoryRepository, audit *AuditService, attendance repository.ActivityAttendanceRepository) *LPJService {
+	return &LPJService{repo: repo, act: act, org: org, rbac: rbac, notify: notify, history: history, audit: audit, attendance: attendance}
 }
 
 type SubmitLPJInput struct {
@@ -92,6 +93,7 @@ func (s *LPJService) Submit(ctx context.Context, in *SubmitLPJInput) (*model.LPJ
 		existing.RevisionNo++
 		existing.ReviewedBy = nil
 		existing.ReviewedAt = nil
+		s.snapshotAttendance(ctx, existing)
 		if err := s.repo.Update(ctx, existing); err != nil {
 			return nil, err
 		}
@@ -118,6 +120,7 @@ func (s *LPJService) Submit(ctx context.Context, in *SubmitLPJInput) (*model.LPJ
 		ReviewedAt:  nil,
 		ReviewedBy:  nil,
 	}
+	s.snapshotAttendance(ctx, l)
 	if err := s.repo.Create(ctx, l); err != nil {
 		return nil, err
 	}
@@ -263,3 +266,52 @@ func (s *LPJService) appendHistory(ctx context.Context, l *model.LPJ, userID uui
 		Note:       note,
 	})
 }
+
+// snapshotAttendance mencatat jumlah peserta hadir kegiatan LPJ saat dikirim.
+func (s *LPJService) snapshotAttendance(ctx context.Context, l *model.LPJ) {
+	if s.attendance == nil || l.ActivityID == nil {
+		return
+	}
+	act, err := s.act.Get(ctx, *l.ActivityID)
+	if err != nil {
+		return
+	}
+	// hanya kemunculan kegiatan LPJ ini, sama seperti AttendanceCSV
+	rows, err := attendanceOf(ctx, s.attendance, act)
+	if err != nil {
+		return
+	}
+	users := map[uuid.UUID]bool{}
+	for _, r := range rows {
+		users[r.UserID] = true
+	}
+	now := time.Now()
+	l.AttendanceCount = len(users)
+	l.AttendanceAt = &now
+}
+
+// AttendanceCSV mengembalikan daftar hadir kegiatan LPJ sebagai bukti kehadiran.
+// Hanya pengurus org pemilik LPJ atau approver LPJ yang boleh mengunduhnya.
+func (s *LPJService) AttendanceCSV(ctx context.Context, userID, lpjID uuid.UUID) ([]byte, string, error) {
+	l, err := s.repo.Get(ctx, lpjID)
+	if err != nil {
+		return nil, "", err
+	}
+	if l.ActivityID == nil || s.attendance == nil {
+		return nil, "", errors.New("LPJ ini tidak terkait kegiatan")
+	}
+	act, err := s.act.Get(ctx, *l.ActivityID)
+	if err != nil {
+		return nil, "", err
+	}
+	if canApprove, err := s.rbac.CanApproveLPJ(ctx, userID); err != nil || !canApprove {
+		org, err := s.org.GetByID(ctx, act.OrgID)
+		if err != nil {
+			return nil, "", errors.New("organization not found")
+		}
+		if ok, err := s.rbac.CanManageOrg(ctx, userID, org); err != nil || !ok {
+			return nil, "", errors.New("forbidden")
+		}
+	}
+	return attendanceCSV(ctx, s.attendance, act)
+}
@@ -120,6 +120,22 @@ func (s *OrganizationService) Update(ctx context.Context, userID uuid.UUID, org
 	if patch.Links != nil {
 		org.Links = patch.Links
 	}
+	if patch.SuratNumberPattern != nil {
+		org.SuratNumberPattern = strings.TrimSpace(*patch.SuratNumberPattern)
+	}
+	if patch.Letterhead != nil {
+		kop := *patch.Letterhead
+		kop.LeftLogoKey = strings.TrimSpace(kop.LeftLogoKey)
+		kop.RightLogoKey = strings.TrimSpace(kop.RightLogoKey)
+		kop.Institution = strings.TrimSpace(kop.Institution)
+		kop.Unit = strings.TrimSpace(kop.Unit)
+		kop.Address = strings.TrimSpace(kop.Address)
+		kop.Phone = strings.TrimSpace(kop.Phone)
+		kop.LineStyle = strings.ToLower(strings.TrimSpace(kop.LineStyle))
+		kop.TextColor = strings.ToUpper(strings.TrimSpace(kop.TextColor))
+		kop.LineColor = strings.ToUpper(strings.TrimSpace(kop.LineColor))
+		org.Letterhead = kop
+	}
 	if patch.GalleryURLs != nil {
 		b, _ := json.Marshal(patch.GalleryURLs)
 		org.GalleryURLs = datatypes.JSON(b)
@@ -157,4 +173,7 @@ type UpdateOrgInput struct {
 	LinkedinURL  *string
 	GalleryURLs  []string
 	Links        map[string]any
+
+	SuratNumberPattern *string
+	Letterhead         *model.OrgLetterhead
 }
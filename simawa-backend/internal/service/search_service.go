@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/repository"
+)
+
+type SearchService struct {
+	repo repository.SearchRepository
+	rbac *RBACService
+}
+
+func NewSearchService(repo repository.SearchRepository, rbac *RBACService) *SearchService {
+	return &SearchService{repo: repo, rbac: rbac}
+}
+
+type SearchResult struct {
+	Items  []repository.SearchHit `json:"items"`
+	Total  int64                  `json:"total"`
+	Facets map[string]int64       `json:"facets"`
+	Page   int                    `json:"page"`
+	Size   int                    `json:"size"`
+}
+
+// Search mencari surat, kegiatan, dan LPJ dengan full-text search Postgres.
+// Hasil dibatasi scope RBAC pemanggil (lihat searchScope).
+func (s *SearchService) Search(ctx context.Context, userID uuid.UUID, text string, types []string, page, size int) (*SearchResult, error) {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) < 2 {
+		return nil, errors.New("kata kunci minimal 2 karakter")
+	}
+	if utf8.RuneCountInString(text) > 200 {
+		return nil, errors.New("kata kunci maksimal 200 karakter")
+	}
+	var picked []string
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		known := false
+		for _, k := range repository.SearchTypes {
+			if t == k {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, errors.New("type harus surat, activity, atau lpj")
+		}
+		picked = append(picked, t)
+	}
+	scope, err := s.searchScope(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > 50 {
+		size = 20
+	}
+	items, total, facets, err := s.repo.Search(ctx, repository.SearchQuery{
+		Text:  text,
+		Types: picked,
+		Scope: scope,
+		Page:  page,
+		Size:  size,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SearchResult{Items: items, Total: total, Facets: facets, Page: page, Size: size}, nil
+}
+
+// searchScope mengikuti aturan akses surat: ADMIN/BEM_ADMIN/DEMA_ADMIN melihat semua,
+// user lain hanya data organisasi pada role-nya.
+func (s *SearchService) searchScope(ctx context.Context, userID uuid.UUID) (repository.SearchScope, error) {
+	scope := repository.SearchScope{UserID: userID}
+	if s.rbac == nil {
+		scope.All = true
+		return scope, nil
+	}
+	assignments, err := s.rbac.ListAssignments(ctx, userID)
+	if err != nil {
+		return scope, err
+	}
+	seen := map[uuid.UUID]struct{}{}
+	for _, a := range assignments {
+		switch a.RoleCode {
+		case model.RoleAdmin, model.RoleBEMAdmin, model.RoleDEMAAdmin:
+			scope.All = true
+		}
+		scope.Roles = append(scope.Roles, strings.ToUpper(a.RoleCode))
+		if a.OrgID != nil {
+			if _, ok := seen[*a.OrgID]; !ok {
+				seen[*a.OrgID] = struct{}{}
+				scope.OrgIDs = append(scope.OrgIDs, *a.OrgID)
+			}
+		}
+	}
+	return scope, nil
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/repository"
+	"simawa-backend/internal/util/storage"
+	"simawa-backend/internal/util/suratpdf"
+)
+
+const agendaNumberPattern = "{seq}/AGD/{org_slug}/{roman_month}/{year}"
+
+// RegisterIncomingInput adalah surat masuk dari luar sistem beserta scan-nya.
+type RegisterIncomingInput struct {
+	OrgID          uuid.UUID // organisasi penerima
+	Sender         string
+	SenderNumber   string
+	Subject        string
+	LetterDate     *time.Time
+	ReceivedAt     time.Time
+	Classification string
+	Note           string
+	File           io.Reader
+	FileName       string
+	ContentType    string
+	RegisteredBy   uuid.UUID
+}
+
+// NormalizeSuratSifat menerima sifat surat (biasa/penting/segera/rahasia); kosong berarti BIASA.
+func NormalizeSuratSifat(v string) string {
+	v = strings.ToUpper(strings.TrimSpace(v))
+	switch v {
+	case "":
+		return model.SuratSifatBiasa
+	case model.SuratSifatBiasa, model.SuratSifatPenting, model.SuratSifatSegera, model.SuratSifatRahasia:
+		return v
+	}
+	return ""
+}
+
+// RegisterIncoming menyimpan scan surat masuk, memberi nomor agenda, dan
+// memasukkannya ke inbox organisasi penerima sebagai surat EXTERNAL.
+func (s *suratService) RegisterIncoming(ctx context.Context, in *RegisterIncomingInput, mc *minio.Client, bucket string) (*model.Surat, error) {
+	if in == nil {
+		return nil, errors.New("input nil")
+	}
+	if s.agendaRepo == nil {
+		return nil, fmt.Errorf("surat agenda repository not wired")
+	}
+	if in.OrgID == uuid.Nil {
+		return nil, errors.New("org_id required")
+	}
+	if strings.TrimSpace(in.Sender) == "" {
+		return nil, errors.New("sender required")
+	}
+	if strings.TrimSpace(in.Subject) == "" {
+		return nil, errors.New("subject required")
+	}
+	if in.File == nil {
+		return nil, errors.New("file required")
+	}
+	sifat := NormalizeSuratSifat(in.Classification)
+	if sifat == "" {
+		return nil, errors.New("sifat surat harus biasa, penting, segera, atau rahasia")
+	}
+	if in.ReceivedAt.IsZero() {
+		in.ReceivedAt = time.Now()
+	}
+
+	data, err := io.ReadAll(in.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	ext := strings.ToLower(filepath.Ext(in.FileName))
+	if ext == "" {
+		ext = attachmentExt[in.ContentType]
+	}
+	key := fmt.Sprintf("surat/masuk/%s/%s%s", in.OrgID, uuid.New().String(), ext)
+	if _, err := storage.UploadToMinio(ctx, mc, bucket, key, bytes.NewReader(data), int64(len(data)), in.ContentType); err != nil {
+		return nil, err
+	}
+
+	var registeredBy *uuid.UUID
+	if in.RegisteredBy != uuid.Nil {
+		registeredBy = &in.RegisteredBy
+	}
+	// Organisasi penerima sekaligus pemilik arsip (org_id), agar query dan RBAC
+	// berbasis org tetap berlaku; pengirim luar dicatat di ToName dan agenda.
+	orgID := in.OrgID
+	row := &model.Surat{
+		OrgID:       in.OrgID,
+		TargetOrgID: &orgID,
+		Source:      model.SuratSourceExternal,
+		Status:      model.SuratStatusReceived,
+		Number:      in.SenderNumber,
+		Subject:     in.Subject,
+		ToName:      in.Sender,
+		FileKey:     key,
+		CreatedBy:   registeredBy,
+	}
+	agenda := &model.SuratAgenda{
+		OrgID:          in.OrgID,
+		Year:           in.ReceivedAt.Year(),
+		Sender:         in.Sender,
+		SenderNumber:   in.SenderNumber,
+		LetterDate:     in.LetterDate,
+		ReceivedAt:     in.ReceivedAt,
+		Subject:        in.Subject,
+		Classification: sifat,
+		Note:           in.Note,
+		RegisteredBy:   registeredBy,
+	}
+
+	slug := in.OrgID.String()[:8]
+	if s.orgRepo != nil {
+		if org, err := s.orgRepo.GetByID(ctx, in.OrgID); err == nil && org != nil && org.Slug != "" {
+			slug = org.Slug
+		}
+	}
+	digits := 3
+	if s.cfg != nil {
+		digits = s.cfg.SeqDigits
+	}
+	err = s.agendaRepo.Register(ctx, row, agenda, func(seq int) string {
+		return FormatSuratNumber(agendaNumberPattern, digits, seq, slug, "", in.ReceivedAt)
+	})
+	if err != nil {
+		_ = storage.DeleteFromMinio(ctx, mc, bucket, key)
+		return nil, err
+	}
+	row.Agenda = agenda
+	s.recordVersion(ctx, row, registeredBy, "surat masuk dicatat")
+
+	if s.audit != nil && in.RegisteredBy != uuid.Nil {
+		s.audit.Log(ctx, in.RegisteredBy, "surat_masuk_register", map[string]any{"surat_id": row.ID, "org_id": in.OrgID, "agenda_number": agenda.AgendaNumber})
+	}
+	return row, nil
+}
+
+func (s *suratService) GetAgenda(ctx context.Context, suratID uint) (*model.SuratAgenda, error) {
+	if s.agendaRepo == nil {
+		return nil, fmt.Errorf("surat agenda repository not wired")
+	}
+	return s.agendaRepo.GetBySurat(ctx, suratID)
+}
+
+func (s *suratService) ListAgenda(ctx context.Context, q repository.ListSuratAgendaQuery) ([]model.SuratAgenda, int64, error) {
+	if s.agendaRepo == nil {
+		return nil, 0, fmt.Errorf("surat agenda repository not wired")
+	}
+	return s.agendaRepo.List(ctx, q)
+}
+
+// ExportAgenda mencetak buku agenda surat masuk satu periode sebagai CSV atau PDF.
+// Mengembalikan isi file, nama file, dan content type.
+func (s *suratService) ExportAgenda(ctx context.Context, q repository.ListSuratAgendaQuery, format string) ([]byte, string, string, error) {
+	q.Page, q.Size = 0, 0
+	rows, _, err := s.ListAgenda(ctx, q)
+	if err != nil {
+		return nil, "", "", err
+	}
+	dateOf := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format("02-01-2006")
+	}
+	base := fmt.Sprintf("buku_agenda_%s", time.Now().Format("20060102"))
+
+	if strings.EqualFold(format, "pdf") {
+		items := make([]suratpdf.AgendaRow, 0, len(rows))
+		for _, r := range rows {
+			received := r.ReceivedAt
+			items = append(items, suratpdf.AgendaRow{
+				AgendaNumber:   r.AgendaNumber,
+				ReceivedAt:     dateOf(&received),
+				Sender:         r.Sender,
+				SenderNumber:   r.SenderNumber,
+				LetterDate:     dateOf(r.LetterDate),
+				Subject:        r.Subject,
+				Classification: r.Classification,
+			})
+		}
+		title := "BUKU AGENDA SURAT MASUK"
+		if s.orgRepo != nil {
+			if org, err := s.orgRepo.GetByID(ctx, q.OrgID); err == nil && org != nil && org.Name != "" {
+				title += " " + strings.ToUpper(org.Name)
+			}
+		}
+		data, err := suratpdf.RenderAgendaBook(title, agendaPeriod(q.From, q.To), items)
+		if err != nil {
+			return nil, "", "", err
+		}
+		return data, base + ".pdf", "application/pdf", nil
+	}
+
+	b := &bytes.Buffer{}
+	w := csv.NewWriter(b)
+	_ = w.Write([]string{"No", "No Agenda", "Tanggal Terima", "Pengirim", "No Surat Asal", "Tanggal Surat", "Perihal", "Sifat", "Catatan", "Surat ID"})
+	for i, r := range rows {
+		received := r.ReceivedAt
+		_ = w.Write([]string{
+			strconv.Itoa(i + 1),
+			r.AgendaNumber,
+			dateOf(&received),
+			r.Sender,
+			r.SenderNumber,
+			dateOf(r.LetterDate),
+			r.Subject,
+			r.Classification,
+			r.Note,
+			strconv.FormatUint(uint64(r.SuratID), 10),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, "", "", err
+	}
+	return b.Bytes(), base + ".csv", "text/csv", nil
+}
+
+// agendaPeriod menuliskan rentang periode; to bersifat eksklusif sehingga dikurangi sehari.
+func agendaPeriod(from, to *time.Time) string {
+	switch {
+	case from != nil && to != nil:
+		return fmt.Sprintf("Periode %s s.d. %s", TanggalIndonesia(*from), TanggalIndonesia(to.AddDate(0, 0, -1)))
+	case from != nil:
+		return "Sejak " + TanggalIndonesia(*from)
+	case to != nil:
+		return "Sampai " + TanggalIndonesia(to.AddDate(0, 0, -1))
+	}
+	return ""
+}
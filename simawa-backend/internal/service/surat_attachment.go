@@ -0,0 +1,262 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/util/storage"
+	"simawa-backend/internal/util/suratpdf"
+)
+
+// SuratAttachmentUpload adalah satu berkas lampiran yang sudah dibaca handler.
+type SuratAttachmentUpload struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+var attachmentExt = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+}
+
+// AddAttachments menyimpan lampiran baru lalu merender ulang PDF surat agar
+// halaman lampiran dan jumlah "Lampiran" ikut diperbarui.
+func (s *suratService) AddAttachments(ctx context.Context, userID uuid.UUID, id uint, files []SuratAttachmentUpload, mc *minio.Client, bucket string) ([]model.SuratAttachment, error) {
+	if s.attachmentRepo == nil {
+		return nil, fmt.Errorf("surat attachment repository not wired")
+	}
+	if len(files) == 0 {
+		return nil, errors.New("file required")
+	}
+	row, err := s.editableSurat(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if !suratpdf.AttachmentTypes[f.ContentType] {
+			return nil, fmt.Errorf("lampiran %s: tipe %s tidak didukung", f.Name, f.ContentType)
+		}
+		if err := suratpdf.CheckAttachment(suratpdf.Attachment{Name: f.Name, ContentType: f.ContentType, Data: f.Data}); err != nil {
+			return nil, err
+		}
+	}
+
+	var uploadedBy *uuid.UUID
+	if userID != uuid.Nil {
+		uploadedBy = &userID
+	}
+	out := make([]model.SuratAttachment, 0, len(files))
+	for _, f := range files {
+		key := fmt.Sprintf("surat/lampiran/%d/%s%s", row.ID, uuid.New().String(), attachmentExt[f.ContentType])
+		if _, err := storage.UploadToMinio(ctx, mc, bucket, key, bytes.NewReader(f.Data), int64(len(f.Data)), f.ContentType); err != nil {
+			return nil, err
+		}
+		a := model.SuratAttachment{
+			SuratID:     row.ID,
+			FileKey:     key,
+			FileName:    f.Name,
+			ContentType: f.ContentType,
+			Size:        int64(len(f.Data)),
+			UploadedBy:  uploadedBy,
+		}
+		if err := s.attachmentRepo.Create(ctx, &a); err != nil {
+			_ = storage.DeleteFromMinio(ctx, mc, bucket, key)
+			return nil, err
+		}
+		out = append(out, a)
+	}
+
+	if err := s.rerender(ctx, row, uploadedBy, "lampiran ditambahkan", mc, bucket); err != nil {
+		return nil, err
+	}
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_attachment_add", map[string]any{"surat_id": row.ID, "count": len(out)})
+	}
+	return out, nil
+}
+
+// DeleteAttachment menghapus satu lampiran dan merender ulang PDF surat.
+func (s *suratService) DeleteAttachment(ctx context.Context, userID uuid.UUID, id, attachmentID uint, mc *minio.Client, bucket string) error {
+	if s.attachmentRepo == nil {
+		return fmt.Errorf("surat attachment repository not wired")
+	}
+	row, err := s.editableSurat(ctx, id)
+	if err != nil {
+		return err
+	}
+	a, err := s.attachmentRepo.Get(ctx, attachmentID)
+	if err != nil || a.SuratID != row.ID {
+		return errors.New("lampiran tidak ditemukan")
+	}
+	if err := s.attachmentRepo.Delete(ctx, a.ID); err != nil {
+		return err
+	}
+	_ = storage.DeleteFromMinio(ctx, mc, bucket, a.FileKey)
+
+	var by *uuid.UUID
+	if userID != uuid.Nil {
+		by = &userID
+	}
+	if err := s.rerender(ctx, row, by, "lampiran dihapus", mc, bucket); err != nil {
+		return err
+	}
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_attachment_delete", map[string]any{"surat_id": row.ID, "attachment_id": a.ID})
+	}
+	return nil
+}
+
+func (s *suratService) ListAttachments(ctx context.Context, id uint) ([]model.SuratAttachment, error) {
+	if s.attachmentRepo == nil {
+		return nil, fmt.Errorf("surat attachment repository not wired")
+	}
+	return s.attachmentRepo.ListBySurat(ctx, id)
+}
+
+// AttachmentBundle membuat ZIP berisi PDF surat dan semua lampiran aslinya.
+func (s *suratService) AttachmentBundle(ctx context.Context, id uint, mc *minio.Client, bucket string) ([]byte, string, error) {
+	if s.suratRepo == nil || s.attachmentRepo == nil {
+		return nil, "", fmt.Errorf("surat repository not wired")
+	}
+	if mc == nil || bucket == "" {
+		return nil, "", fmt.Errorf("minio disabled or bucket missing")
+	}
+	row, err := s.suratRepo.Get(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	atts, err := s.attachmentRepo.ListBySurat(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	add := func(name, key string) error {
+		obj, err := mc.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+		if err != nil {
+			return err
+		}
+		defer obj.Close()
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, obj)
+		return err
+	}
+	if row.FileKey != "" {
+		if err := add(fmt.Sprintf("surat_%d.pdf", row.ID), row.FileKey); err != nil {
+			return nil, "", err
+		}
+	}
+	for i, a := range atts {
+		if err := add(fmt.Sprintf("lampiran/%02d_%s", i+1, path.Base(a.FileName)), a.FileKey); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), fmt.Sprintf("surat_%d_lampiran.zip", row.ID), nil
+}
+
+// editableSurat memuat surat yang masih boleh diubah lampirannya (DRAFT/REVISION).
+func (s *suratService) editableSurat(ctx context.Context, id uint) (*model.Surat, error) {
+	if s.suratRepo == nil {
+		return nil, fmt.Errorf("surat repository not wired")
+	}
+	row, err := s.suratRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != model.SuratStatusDraft && row.Status != model.SuratStatusRevision {
+		return nil, errors.New("lampiran hanya bisa diubah pada surat draft atau revisi")
+	}
+	return row, nil
+}
+
+// rerender membangun ulang PDF surat dari payload tersimpan. Surat hasil upload
+// tidak punya payload sehingga lampirannya hanya tersedia lewat bundle.
+func (s *suratService) rerender(ctx context.Context, row *model.Surat, author *uuid.UUID, reason string, mc *minio.Client, bucket string) error {
+	payload, theme := decodeSuratPayload(row.MetaJSON)
+	if payload == nil {
+		return nil
+	}
+	payload.Meta.Number = row.Number
+	key, err := s.renderAndUpload(ctx, row, payload, theme, mc, bucket)
+	if err != nil {
+		return err
+	}
+	row.FileKey = key
+	row.MetaJSON = withSuratPayload(row.MetaJSON, payload)
+	if err := s.suratRepo.Update(ctx, row); err != nil {
+		return err
+	}
+	s.recordVersion(ctx, row, author, reason)
+	return nil
+}
+
+// renderAndUpload merender payload beserta lampiran surat lalu mengunggahnya ke MinIO.
+// Meta.Lampiran diisi otomatis dari jumlah lampiran; TTD/cap diambil dari spesimen
+// dan logo kop dari object storage.
+func (s *suratService) renderAndUpload(ctx context.Context, row *model.Surat, payload *suratpdf.Payload, theme *suratpdf.Theme, mc *minio.Client, bucket string) (string, error) {
+	if mc == nil || bucket == "" {
+		return "", fmt.Errorf("minio disabled or bucket missing")
+	}
+	atts, err := s.loadAttachments(ctx, row.ID, mc, bucket)
+	if err != nil {
+		return "", err
+	}
+	if len(atts) > 0 {
+		payload.Meta.Lampiran = fmt.Sprintf("%d berkas", len(atts))
+	} else if strings.HasSuffix(payload.Meta.Lampiran, " berkas") {
+		// label otomatis dari lampiran yang sudah dihapus semua
+		payload.Meta.Lampiran = ""
+	}
+	stripSignImages(payload)
+	render := s.withLetterheadLogos(ctx, row.OrgID, s.withSpecimens(ctx, row, *payload, mc, bucket), mc, bucket)
+	pdfBytes, err := suratpdf.RenderWithAttachments(render, s.withFonts(ctx, row.OrgID, theme, mc, bucket), atts)
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf("surat/%s.pdf", uuid.New().String())
+	return storage.UploadToMinio(ctx, mc, bucket, key, bytes.NewReader(pdfBytes), int64(len(pdfBytes)), "application/pdf")
+}
+
+func (s *suratService) loadAttachments(ctx context.Context, id uint, mc *minio.Client, bucket string) ([]suratpdf.Attachment, error) {
+	if s.attachmentRepo == nil || id == 0 {
+		return nil, nil
+	}
+	rows, err := s.attachmentRepo.ListBySurat(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]suratpdf.Attachment, 0, len(rows))
+	for _, a := range rows {
+		obj, err := mc.GetObject(ctx, bucket, a.FileKey, minio.GetObjectOptions{})
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(obj)
+		obj.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read lampiran %s: %w", a.FileName, err)
+		}
+		out = append(out, suratpdf.Attachment{Name: a.FileName, ContentType: a.ContentType, Data: data})
+	}
+	return out, nil
+}
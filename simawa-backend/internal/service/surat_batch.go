@@ -0,0 +1,410 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/util/suratpdf"
+)
+
+const maxBatchRecipients = 500
+
+// SuratRecipient adalah satu baris daftar penerima mail-merge.
+type SuratRecipient struct {
+	ToRole      string     `json:"to_role"`
+	ToName      string     `json:"to_name"`
+	ToPlace     string     `json:"to_place"`
+	ToCity      string     `json:"to_city"`
+	TargetOrgID *uuid.UUID `json:"target_org_id,omitempty"`
+	Email       string     `json:"email,omitempty"`
+}
+
+// CreateSuratBatchInput adalah payload dasar (atau template) yang dirender sekali
+// untuk setiap penerima. Field tujuan di payload ditimpa data penerima.
+type CreateSuratBatchInput struct {
+	OrgID      uuid.UUID
+	Payload    suratpdf.Payload
+	Theme      *suratpdf.Theme
+	TemplateID *uint
+	Variables  map[string]string
+	ActivityID *uuid.UUID
+	Recipients []SuratRecipient
+	EmailCc    []string // tembusan email untuk semua surat dalam batch
+	CreatedBy  uuid.UUID
+}
+
+// SuratBatchError mencatat penerima yang gagal diproses.
+type SuratBatchError struct {
+	Index  int    `json:"index"`
+	ToName string `json:"to_name"`
+	Error  string `json:"error"`
+}
+
+// SuratBatchSubmitResult adalah ringkasan pengajuan semua draft dalam satu batch.
+type SuratBatchSubmitResult struct {
+	Submitted int               `json:"submitted"`
+	Skipped   int               `json:"skipped"`
+	Failed    int               `json:"failed"`
+	Errors    []SuratBatchError `json:"errors,omitempty"`
+}
+
+// ParseSuratRecipientsCSV membaca daftar penerima dari CSV berheader
+// to_role,to_name,to_place,to_city,target_org_id,email. Pemisah ";" (ekspor Excel
+// berlocale Indonesia) dikenali otomatis.
+func ParseSuratRecipientsCSV(r io.Reader) ([]SuratRecipient, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	raw = bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf"))
+	cr := csv.NewReader(bytes.NewReader(raw))
+	firstLine := string(raw)
+	if i := strings.IndexByte(firstLine, '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		cr.Comma = ';'
+	}
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, errors.New("csv penerima kosong")
+	}
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	if _, ok := cols["to_name"]; !ok {
+		if _, ok := cols["to_role"]; !ok {
+			return nil, errors.New("csv penerima harus punya kolom to_name atau to_role")
+		}
+	}
+	get := func(rec []string, name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	var out []SuratRecipient
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv baris %d: %w", line, err)
+		}
+		rcp := SuratRecipient{
+			ToRole:  get(rec, "to_role"),
+			ToName:  get(rec, "to_name"),
+			ToPlace: get(rec, "to_place"),
+			ToCity:  get(rec, "to_city"),
+			Email:   get(rec, "email"),
+		}
+		if rcp.ToRole == "" && rcp.ToName == "" {
+			continue
+		}
+		if v := get(rec, "target_org_id"); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return nil, fmt.Errorf("csv baris %d: target_org_id tidak valid", line)
+			}
+			rcp.TargetOrgID = &id
+		}
+		out = append(out, rcp)
+	}
+	return out, nil
+}
+
+// CreateBatch mencatat batch lalu membuat draft surat per penerima di background.
+// Progress bisa dipantau lewat GetBatch.
+func (s *suratService) CreateBatch(ctx context.Context, in *CreateSuratBatchInput, mc *minio.Client, bucket string) (*model.SuratBatch, error) {
+	if in == nil {
+		return nil, errors.New("input nil")
+	}
+	if s.suratRepo == nil || s.batchRepo == nil {
+		return nil, fmt.Errorf("surat batch repository not wired")
+	}
+	if in.OrgID == uuid.Nil {
+		return nil, errors.New("org_id required")
+	}
+	if len(in.Recipients) == 0 {
+		return nil, errors.New("recipients required")
+	}
+	if len(in.Recipients) > maxBatchRecipients {
+		return nil, fmt.Errorf("maksimal %d penerima per batch", maxBatchRecipients)
+	}
+	for i, r := range in.Recipients {
+		if strings.TrimSpace(r.ToName) == "" && strings.TrimSpace(r.ToRole) == "" {
+			return nil, fmt.Errorf("penerima #%d: to_name atau to_role wajib diisi", i+1)
+		}
+		if _, err := normalizeEmails([]string{r.Email}); err != nil {
+			return nil, fmt.Errorf("penerima #%d: %w", i+1, err)
+		}
+	}
+	if _, err := normalizeEmails(in.EmailCc); err != nil {
+		return nil, err
+	}
+	in.Payload.Verification = nil
+
+	var createdBy *uuid.UUID
+	if in.CreatedBy != uuid.Nil {
+		createdBy = &in.CreatedBy
+	}
+	b := &model.SuratBatch{
+		OrgID:     in.OrgID,
+		Variant:   NormalizeSuratVariant(string(in.Payload.Variant)),
+		Subject:   in.Payload.Meta.Subject,
+		Status:    model.SuratBatchStatusQueued,
+		Total:     len(in.Recipients),
+		CreatedBy: createdBy,
+	}
+	if err := s.batchRepo.Create(ctx, b); err != nil {
+		return nil, err
+	}
+	if s.audit != nil && in.CreatedBy != uuid.Nil {
+		s.audit.Log(ctx, in.CreatedBy, "surat_batch_create", map[string]any{"batch_id": b.ID, "org_id": b.OrgID, "total": b.Total})
+	}
+
+	job := *b
+	go s.runBatch(&job, in, mc, bucket)
+	return b, nil
+}
+
+// runBatch membuat satu draft surat per penerima. Kegagalan satu penerima
+// dicatat di batch dan tidak menghentikan penerima berikutnya.
+func (s *suratService) runBatch(b *model.SuratBatch, in *CreateSuratBatchInput, mc *minio.Client, bucket string) {
+	ctx := context.Background()
+	var errs []SuratBatchError
+	save := func() {
+		b.Errors, _ = json.Marshal(errs)
+		if err := s.batchRepo.Update(ctx, b); err != nil {
+			fmt.Printf("[SURAT] batch %d: gagal menyimpan progress: %v\n", b.ID, err)
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			errs = append(errs, SuratBatchError{Index: b.Processed, Error: fmt.Sprint(r)})
+			b.Status = model.SuratBatchStatusFailed
+			now := time.Now()
+			b.FinishedAt = &now
+			save()
+		}
+	}()
+
+	b.Status = model.SuratBatchStatusRunning
+	save()
+
+	base, _ := json.Marshal(in.Payload)
+	for i, rcp := range in.Recipients {
+		// payload disalin per penerima karena Create mengubah header/meta-nya
+		var p suratpdf.Payload
+		_ = json.Unmarshal(base, &p)
+		p.Meta.ToRole = rcp.ToRole
+		p.Meta.ToName = rcp.ToName
+		p.Meta.ToPlace = rcp.ToPlace
+		p.Meta.ToCity = rcp.ToCity
+
+		_, err := s.Create(ctx, &CreateSuratInput{
+			OrgID:       in.OrgID,
+			TargetOrgID: rcp.TargetOrgID,
+			Payload:     p,
+			Theme:       in.Theme,
+			CreatedBy:   in.CreatedBy,
+			Status:      model.SuratStatusDraft,
+			TemplateID:  in.TemplateID,
+			Variables:   in.Variables,
+			ActivityID:  in.ActivityID,
+			BatchID:     &b.ID,
+			EmailTo:     []string{rcp.Email},
+			EmailCc:     in.EmailCc,
+		}, mc, bucket)
+		b.Processed++
+		if err != nil {
+			b.Failed++
+			errs = append(errs, SuratBatchError{Index: i, ToName: rcp.ToName, Error: err.Error()})
+		}
+		if b.Processed%5 == 0 && b.Processed < b.Total {
+			save()
+		}
+	}
+
+	now := time.Now()
+	b.FinishedAt = &now
+	b.Status = model.SuratBatchStatusDone
+	if b.Failed == b.Total {
+		b.Status = model.SuratBatchStatusFailed
+	}
+	save()
+
+	if s.notify != nil && b.CreatedBy != nil {
+		body := fmt.Sprintf("%d dari %d surat berhasil dibuat", b.Total-b.Failed, b.Total)
+		_ = s.notify.Push(ctx, *b.CreatedBy, "Batch surat selesai", body, map[string]any{"batch_id": b.ID})
+	}
+}
+
+func (s *suratService) GetBatch(ctx context.Context, id uint) (*model.SuratBatch, error) {
+	if s.batchRepo == nil {
+		return nil, fmt.Errorf("surat batch repository not wired")
+	}
+	return s.batchRepo.Get(ctx, id)
+}
+
+// FailInterruptedBatches menandai FAILED batch yang masih QUEUED/RUNNING saat server
+// dimulai: goroutine runBatch-nya sudah hilang dan payload dasar tidak disimpan, jadi
+// batch tidak bisa dilanjutkan. Draft yang sudah terbuat tetap bisa diajukan lewat
+// SubmitBatch; penerima sisanya perlu dibuat ulang dengan batch baru.
+func (s *suratService) FailInterruptedBatches(ctx context.Context) (int, error) {
+	if s.suratRepo == nil || s.batchRepo == nil {
+		return 0, fmt.Errorf("surat batch repository not wired")
+	}
+	rows, err := s.batchRepo.ListUnfinished(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i := range rows {
+		b := &rows[i]
+		var errs []SuratBatchError
+		if len(b.Errors) > 0 {
+			_ = json.Unmarshal(b.Errors, &errs)
+		}
+		// Processed hanya disimpan tiap beberapa penerima, jadi dihitung ulang dari draft yang ada.
+		if created, err := s.suratRepo.ListByBatch(ctx, b.ID); err == nil {
+			b.Processed = len(created) + b.Failed
+		}
+		errs = append(errs, SuratBatchError{
+			Index: b.Processed,
+			Error: fmt.Sprintf("proses terhenti karena server dimulai ulang; %d penerima belum dibuat", b.Total-b.Processed),
+		})
+		b.Errors, _ = json.Marshal(errs)
+		b.Status = model.SuratBatchStatusFailed
+		now := time.Now()
+		b.FinishedAt = &now
+		if err := s.batchRepo.Update(ctx, b); err != nil {
+			return i, err
+		}
+		if s.notify != nil && b.CreatedBy != nil {
+			body := fmt.Sprintf("Batch terhenti: %d dari %d surat sempat dibuat", b.Processed-b.Failed, b.Total)
+			_ = s.notify.Push(ctx, *b.CreatedBy, "Batch surat gagal", body, map[string]any{"batch_id": b.ID})
+		}
+	}
+	return len(rows), nil
+}
+
+func (s *suratService) ListBatchSurat(ctx context.Context, id uint) ([]model.Surat, error) {
+	if s.suratRepo == nil {
+		return nil, fmt.Errorf("surat repository not wired")
+	}
+	return s.suratRepo.ListByBatch(ctx, id)
+}
+
+// SubmitBatch mengajukan semua draft dalam batch; setiap surat mendapat nomor sendiri.
+func (s *suratService) SubmitBatch(ctx context.Context, userID uuid.UUID, id uint, mc *minio.Client, bucket string) (*SuratBatchSubmitResult, error) {
+	b, err := s.GetBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b.Status == model.SuratBatchStatusQueued || b.Status == model.SuratBatchStatusRunning {
+		return nil, errors.New("batch masih diproses")
+	}
+	rows, err := s.suratRepo.ListByBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	res := &SuratBatchSubmitResult{}
+	for i, row := range rows {
+		if row.Status != model.SuratStatusDraft {
+			res.Skipped++
+			continue
+		}
+		if _, err := s.Submit(ctx, userID, row.ID, mc, bucket); err != nil {
+			res.Failed++
+			res.Errors = append(res.Errors, SuratBatchError{Index: i, ToName: row.ToName, Error: err.Error()})
+			continue
+		}
+		res.Submitted++
+	}
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_batch_submit", map[string]any{"batch_id": id, "submitted": res.Submitted, "failed": res.Failed})
+	}
+	return res, nil
+}
+
+// BatchArchive mengemas PDF semua surat dalam batch ke satu ZIP.
+func (s *suratService) BatchArchive(ctx context.Context, id uint, mc *minio.Client, bucket string) ([]byte, string, error) {
+	if mc == nil || bucket == "" {
+		return nil, "", fmt.Errorf("minio disabled or bucket missing")
+	}
+	rows, err := s.ListBatchSurat(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(rows) == 0 {
+		return nil, "", errors.New("batch belum berisi surat")
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i, row := range rows {
+		if row.FileKey == "" {
+			continue
+		}
+		label := row.ToName
+		if label == "" {
+			label = row.ToRole
+		}
+		obj, err := mc.GetObject(ctx, bucket, row.FileKey, minio.GetObjectOptions{})
+		if err != nil {
+			return nil, "", err
+		}
+		w, err := zw.Create(fmt.Sprintf("%03d_%s.pdf", i+1, zipSafeName(label)))
+		if err == nil {
+			_, err = io.Copy(w, obj)
+		}
+		obj.Close()
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), fmt.Sprintf("surat_batch_%d.zip", id), nil
+}
+
+// zipSafeName menyisakan huruf, angka, '-' dan '_' agar aman dipakai sebagai nama berkas.
+func zipSafeName(v string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(v) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ' || r == '.' || r == '/':
+			b.WriteRune('_')
+		}
+	}
+	out := b.String()
+	if len(out) > 60 {
+		out = out[:60]
+	}
+	if out == "" {
+		out = "surat"
+	}
+	return out
+}
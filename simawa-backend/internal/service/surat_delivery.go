@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+	"gorm.io/datatypes"
+
+	"simawa-backend/internal/model"
+)
+
+type deliveryTarget struct {
+	Email string
+	Kind  string
+}
+
+// normalizeEmails memvalidasi alamat email, menurunkan hurufnya, dan membuang duplikat.
+func normalizeEmails(list []string) ([]string, error) {
+	seen := map[string]struct{}{}
+	out := make([]string, 0, len(list))
+	for _, v := range list {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		addr, err := mail.ParseAddress(v)
+		if err != nil {
+			return nil, fmt.Errorf("email %q tidak valid", v)
+		}
+		e := strings.ToLower(addr.Address)
+		if _, ok := seen[e]; ok {
+			continue
+		}
+		seen[e] = struct{}{}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func encodeEmails(list []string) datatypes.JSON {
+	if len(list) == 0 {
+		return nil
+	}
+	b, _ := json.Marshal(list)
+	return b
+}
+
+func decodeEmails(raw datatypes.JSON) []string {
+	var out []string
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &out)
+	}
+	return out
+}
+
+// SetEmailRecipients mengganti alamat email penerima dan tembusan surat.
+// Surat yang sudah disetujui tidak dikirim otomatis; pakai RetryDelivery.
+func (s *suratService) SetEmailRecipients(ctx context.Context, userID uuid.UUID, id uint, to, cc []string) (*model.Surat, error) {
+	if s.suratRepo == nil {
+		return nil, fmt.Errorf("surat repository not wired")
+	}
+	row, err := s.suratRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if to, err = normalizeEmails(to); err != nil {
+		return nil, err
+	}
+	if cc, err = normalizeEmails(cc); err != nil {
+		return nil, err
+	}
+	row.EmailTo = encodeEmails(to)
+	row.EmailCc = encodeEmails(cc)
+	if err := s.suratRepo.Update(ctx, row); err != nil {
+		return nil, err
+	}
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_email_update", map[string]any{"surat_id": row.ID, "to": to, "cc": cc})
+	}
+	return row, nil
+}
+
+func (s *suratService) ListDeliveries(ctx context.Context, id uint) ([]model.SuratDelivery, error) {
+	if s.deliveryRepo == nil {
+		return nil, fmt.Errorf("surat delivery repository not wired")
+	}
+	return s.deliveryRepo.ListBySurat(ctx, id)
+}
+
+// RetryDelivery mengirim ulang (di background) ke alamat yang belum pernah
+// berhasil, termasuk alamat yang baru ditambahkan setelah surat disetujui.
+func (s *suratService) RetryDelivery(ctx context.Context, userID uuid.UUID, id uint, mc *minio.Client, bucket string) (int, error) {
+	if s.suratRepo == nil || s.deliveryRepo == nil {
+		return 0, fmt.Errorf("surat delivery repository not wired")
+	}
+	row, err := s.suratRepo.Get(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if row.Status != model.SuratStatusApproved {
+		return 0, errors.New("hanya surat yang sudah disetujui yang dapat dikirim")
+	}
+	logs, err := s.deliveryRepo.ListBySurat(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	sent := map[string]bool{}
+	for _, l := range logs {
+		if l.Status == model.SuratDeliverySent {
+			sent[l.Email+"|"+l.Kind] = true
+		}
+	}
+	var pending []deliveryTarget
+	for _, t := range deliveryTargets(row) {
+		if !sent[t.Email+"|"+t.Kind] {
+			pending = append(pending, t)
+		}
+	}
+	if len(pending) == 0 {
+		return 0, errors.New("semua email surat sudah terkirim")
+	}
+	// Alamat yang masih dikirim oleh proses sebelumnya (persetujuan atau retry lain) dilewati.
+	pending = s.claimDelivery(row.ID, pending)
+	if len(pending) == 0 {
+		return 0, errors.New("pengiriman email surat masih berjalan")
+	}
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_email_retry", map[string]any{"surat_id": row.ID, "count": len(pending)})
+	}
+	go s.deliverSurat(row, pending, mc, bucket)
+	return len(pending), nil
+}
+
+func deliveryKey(suratID uint, t deliveryTarget) string {
+	return fmt.Sprintf("%d|%s|%s", suratID, t.Email, t.Kind)
+}
+
+// claimDelivery menandai target sebagai sedang dikirim dan mengembalikan target yang
+// belum diklaim proses lain. Klaim dilepas deliverSurat setelah selesai.
+func (s *suratService) claimDelivery(suratID uint, targets []deliveryTarget) []deliveryTarget {
+	out := make([]deliveryTarget, 0, len(targets))
+	for _, t := range targets {
+		if _, busy := s.sending.LoadOrStore(deliveryKey(suratID, t), struct{}{}); !busy {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func deliveryTargets(row *model.Surat) []deliveryTarget {
+	var out []deliveryTarget
+	for _, e := range decodeEmails(row.EmailTo) {
+		out = append(out, deliveryTarget{Email: e, Kind: model.SuratDeliveryTo})
+	}
+	for _, e := range decodeEmails(row.EmailCc) {
+		out = append(out, deliveryTarget{Email: e, Kind: model.SuratDeliveryTembusan})
+	}
+	return out
+}
+
+// queueDelivery dipanggil setelah surat disetujui; pengiriman berjalan di background
+// agar persetujuan tidak menunggu SMTP.
+func (s *suratService) queueDelivery(row *model.Surat, mc *minio.Client, bucket string) {
+	if s.email == nil || s.deliveryRepo == nil {
+		return
+	}
+	targets := s.claimDelivery(row.ID, deliveryTargets(row))
+	if len(targets) == 0 {
+		return
+	}
+	snapshot := *row
+	go s.deliverSurat(&snapshot, targets, mc, bucket)
+}
+
+// deliverSurat mengirim PDF final ke setiap alamat (yang sudah diklaim lewat
+// claimDelivery) dengan beberapa percobaan; setiap percobaan dicatat di log pengiriman.
+func (s *suratService) deliverSurat(row *model.Surat, targets []deliveryTarget, mc *minio.Client, bucket string) {
+	defer func() {
+		for _, t := range targets {
+			s.sending.Delete(deliveryKey(row.ID, t))
+		}
+	}()
+	ctx := context.Background()
+	maxAttempts := 3
+	if s.cfg != nil && s.cfg.EmailMaxAttempts > 0 {
+		maxAttempts = s.cfg.EmailMaxAttempts
+	}
+	attempts := map[string]int{}
+	sent := map[string]bool{}
+	if logs, err := s.deliveryRepo.ListBySurat(ctx, row.ID); err == nil {
+		for _, l := range logs {
+			attempts[l.Email+"|"+l.Kind]++
+			if l.Status == model.SuratDeliverySent {
+				sent[l.Email+"|"+l.Kind] = true
+			}
+		}
+	}
+	// proses lain bisa saja selesai mengirim di antara pengecekan RetryDelivery dan klaim
+	var pending []deliveryTarget
+	for _, t := range targets {
+		if !sent[t.Email+"|"+t.Kind] {
+			pending = append(pending, t)
+		}
+	}
+	record := func(t deliveryTarget, status string, err error) {
+		key := t.Email + "|" + t.Kind
+		attempts[key]++
+		d := &model.SuratDelivery{SuratID: row.ID, Email: t.Email, Kind: t.Kind, Attempt: attempts[key], Status: status}
+		if err != nil {
+			d.Error = err.Error()
+		}
+		if err := s.deliveryRepo.Create(ctx, d); err != nil {
+			fmt.Printf("[SURAT] gagal mencatat pengiriman email surat %d: %v\n", row.ID, err)
+		}
+	}
+
+	if len(pending) == 0 {
+		return
+	}
+	pdf, err := s.readObject(ctx, mc, bucket, row.FileKey)
+	if err != nil {
+		for _, t := range pending {
+			record(t, model.SuratDeliveryFailed, err)
+		}
+		s.notifyDeliveryFailed(ctx, row, len(pending))
+		return
+	}
+
+	subject := row.Subject
+	if row.Number != "" {
+		subject = fmt.Sprintf("%s - %s", row.Subject, row.Number)
+	}
+	fileName := "surat_" + zipSafeName(row.Number) + ".pdf"
+	failed := 0
+	for _, t := range pending {
+		intro := fmt.Sprintf("Bersama email ini kami sampaikan surat nomor %s perihal %s.", row.Number, row.Subject)
+		if t.Kind == model.SuratDeliveryTo && row.ToName != "" {
+			intro = fmt.Sprintf("Yth. %s, %s", row.ToName, strings.ToLower(intro[:1])+intro[1:])
+		}
+		if t.Kind == model.SuratDeliveryTembusan {
+			intro = "Sebagai tembusan, " + strings.ToLower(intro[:1]) + intro[1:]
+		}
+		if !s.email.Configured() {
+			record(t, model.SuratDeliverySkipped, errors.New("smtp belum dikonfigurasi"))
+			continue
+		}
+		for i := 1; i <= maxAttempts; i++ {
+			err := s.email.SendSurat(t.Email, subject, intro, fileName, pdf)
+			if err == nil {
+				record(t, model.SuratDeliverySent, nil)
+				break
+			}
+			record(t, model.SuratDeliveryFailed, err)
+			if i == maxAttempts {
+				failed++
+				break
+			}
+			time.Sleep(time.Duration(i) * 5 * time.Second)
+		}
+	}
+	if failed > 0 {
+		s.notifyDeliveryFailed(ctx, row, failed)
+	}
+}
+
+func (s *suratService) notifyDeliveryFailed(ctx context.Context, row *model.Surat, n int) {
+	if s.notify == nil || row.CreatedBy == nil {
+		return
+	}
+	_ = s.notify.Push(ctx, *row.CreatedBy, "Email surat gagal dikirim", fmt.Sprintf("%d alamat gagal menerima surat %s", n, row.Number), map[string]any{"surat_id": row.ID})
+}
+
+func (s *suratService) readObject(ctx context.Context, mc *minio.Client, bucket, key string) ([]byte, error) {
+	if mc == nil || bucket == "" {
+		return nil, fmt.Errorf("minio disabled or bucket missing")
+	}
+	if key == "" {
+		return nil, errors.New("surat belum memiliki file")
+	}
+	obj, err := mc.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	return io.ReadAll(obj)
+}
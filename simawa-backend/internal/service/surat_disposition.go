@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+)
+
+// DisposeSuratInput adalah disposisi surat masuk ke satu atau beberapa anggota
+// organisasi penerima. ParentID diisi bila penerima disposisi meneruskannya lagi;
+// OrgID lalu diambil dari disposisi induk.
+type DisposeSuratInput struct {
+	SuratID     uint
+	UserID      uuid.UUID
+	OrgID       uuid.UUID
+	ParentID    *uint
+	Assignees   []uuid.UUID
+	Instruction string
+	Note        string
+	DueDate     *time.Time
+}
+
+var disposisiInstructions = map[string]string{
+	model.DisposisiTindakLanjuti: "Tindak lanjuti",
+	model.DisposisiHadiri:        "Hadiri",
+	model.DisposisiArsipkan:      "Arsipkan",
+}
+
+// NormalizeDisposisiInstruction menerima "tindak lanjuti", "tindak_lanjuti", dst.
+func NormalizeDisposisiInstruction(v string) string {
+	v = strings.ToUpper(strings.TrimSpace(v))
+	v = strings.ReplaceAll(v, " ", "_")
+	v = strings.ReplaceAll(v, "-", "_")
+	if _, ok := disposisiInstructions[v]; ok {
+		return v
+	}
+	return ""
+}
+
+// Dispose membuat disposisi untuk setiap penerima dan mengirim notifikasi ke mereka.
+func (s *suratService) Dispose(ctx context.Context, in *DisposeSuratInput) ([]model.SuratDisposition, error) {
+	if in == nil {
+		return nil, errors.New("input nil")
+	}
+	if s.suratRepo == nil || s.dispositionRepo == nil {
+		return nil, fmt.Errorf("surat disposition repository not wired")
+	}
+	instruction := NormalizeDisposisiInstruction(in.Instruction)
+	if instruction == "" {
+		return nil, errors.New("instruksi disposisi harus tindak lanjuti, hadiri, atau arsipkan")
+	}
+	row, err := s.suratRepo.Get(ctx, in.SuratID)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status == model.SuratStatusDraft {
+		return nil, errors.New("draft surat cannot be disposed")
+	}
+
+	orgID := in.OrgID
+	if in.ParentID != nil {
+		parent, err := s.dispositionRepo.Get(ctx, *in.ParentID)
+		if err != nil || parent.SuratID != row.ID {
+			return nil, errors.New("disposisi induk tidak ditemukan")
+		}
+		if parent.ToUserID != in.UserID {
+			return nil, errors.New("hanya penerima disposisi yang dapat meneruskannya")
+		}
+		if parent.Status != model.DisposisiStatusOpen {
+			return nil, errors.New("disposisi induk sudah selesai")
+		}
+		orgID = parent.OrgID
+	}
+	if orgID == uuid.Nil {
+		return nil, errors.New("org_id required")
+	}
+
+	seen := map[uuid.UUID]struct{}{}
+	rows := make([]model.SuratDisposition, 0, len(in.Assignees))
+	for _, uid := range in.Assignees {
+		if uid == uuid.Nil || uid == in.UserID {
+			continue
+		}
+		if _, ok := seen[uid]; ok {
+			continue
+		}
+		seen[uid] = struct{}{}
+		if s.memberRepo != nil {
+			ok, err := s.memberRepo.IsMember(ctx, orgID, uid)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, fmt.Errorf("user %s bukan anggota organisasi penerima", uid)
+			}
+		}
+		rows = append(rows, model.SuratDisposition{
+			SuratID:     row.ID,
+			ParentID:    in.ParentID,
+			OrgID:       orgID,
+			FromUserID:  in.UserID,
+			ToUserID:    uid,
+			Instruction: instruction,
+			Note:        in.Note,
+			DueDate:     in.DueDate,
+			Status:      model.DisposisiStatusOpen,
+		})
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("assignees required")
+	}
+	if err := s.dispositionRepo.Create(ctx, rows); err != nil {
+		return nil, err
+	}
+
+	if s.notify != nil {
+		body := fmt.Sprintf("%s: %s", disposisiInstructions[instruction], row.Subject)
+		if in.DueDate != nil {
+			body += fmt.Sprintf(" (batas %s)", TanggalIndonesia(*in.DueDate))
+		}
+		for _, d := range rows {
+			_ = s.notify.Push(ctx, d.ToUserID, "Disposisi surat", body, map[string]any{"surat_id": row.ID, "disposition_id": d.ID})
+		}
+	}
+	if s.audit != nil && in.UserID != uuid.Nil {
+		s.audit.Log(ctx, in.UserID, "surat_dispose", map[string]any{"surat_id": row.ID, "org_id": orgID, "instruction": instruction, "count": len(rows)})
+	}
+	return rows, nil
+}
+
+// CompleteDisposition menandai disposisi selesai oleh penerimanya dan memberi tahu pemberi disposisi.
+func (s *suratService) CompleteDisposition(ctx context.Context, userID uuid.UUID, id uint, note string) (*model.SuratDisposition, error) {
+	if s.dispositionRepo == nil {
+		return nil, fmt.Errorf("surat disposition repository not wired")
+	}
+	d, err := s.dispositionRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.ToUserID != userID {
+		return nil, errors.New("forbidden")
+	}
+	if d.Status == model.DisposisiStatusDone {
+		return nil, errors.New("disposisi sudah selesai")
+	}
+	now := time.Now()
+	d.Status = model.DisposisiStatusDone
+	d.DoneAt = &now
+	d.DoneNote = note
+	if err := s.dispositionRepo.Update(ctx, d); err != nil {
+		return nil, err
+	}
+	if s.notify != nil {
+		subject := ""
+		if s.suratRepo != nil {
+			if row, err := s.suratRepo.Get(ctx, d.SuratID); err == nil {
+				subject = row.Subject
+			}
+		}
+		_ = s.notify.Push(ctx, d.FromUserID, "Disposisi selesai", subject, map[string]any{"surat_id": d.SuratID, "disposition_id": d.ID})
+	}
+	if s.audit != nil {
+		s.audit.Log(ctx, userID, "surat_disposition_done", map[string]any{"surat_id": d.SuratID, "disposition_id": d.ID})
+	}
+	return d, nil
+}
+
+func (s *suratService) ListDispositions(ctx context.Context, suratID uint) ([]model.SuratDisposition, error) {
+	if s.dispositionRepo == nil {
+		return nil, fmt.Errorf("surat disposition repository not wired")
+	}
+	return s.dispositionRepo.ListBySurat(ctx, suratID)
+}
+
+func (s *suratService) ListMyDispositions(ctx context.Context, userID uuid.UUID, status string, page, size int) ([]model.SuratDisposition, int64, error) {
+	if s.dispositionRepo == nil {
+		return nil, 0, fmt.Errorf("surat disposition repository not wired")
+	}
+	return s.dispositionRepo.ListByAssignee(ctx, userID, strings.ToUpper(strings.TrimSpace(status)), page, size)
+}
+
+// IsDispositionAssignee dipakai handler agar penerima disposisi bisa membaca surat yang didisposisikan kepadanya.
+func (s *suratService) IsDispositionAssignee(ctx context.Context, suratID uint, userID uuid.UUID) bool {
+	if s.dispositionRepo == nil {
+		return false
+	}
+	ok, _ := s.dispositionRepo.IsAssignee(ctx, suratID, userID)
+	return ok
+}
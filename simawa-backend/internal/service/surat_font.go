@@ -0,0 +1,144 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/util/storage"
+	"simawa-backend/internal/util/suratpdf"
+)
+
+// MaxFontSize batas ukuran berkas font TTF unggahan.
+const MaxFontSize = 5 * 1024 * 1024
+
+var fontFamilyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9 _-]{0,63}$`)
+
+// SuratFontUpload berisi satu berkas TTF untuk family/gaya milik organisasi.
+type SuratFontUpload struct {
+	OrgID      uuid.UUID
+	Family     string
+	Style      string
+	Data       []byte
+	UploadedBy uuid.UUID
+}
+
+// SaveFont menyimpan atau mengganti font organisasi untuk family dan gaya tertentu.
+func (s *suratService) SaveFont(ctx context.Context, in *SuratFontUpload, mc *minio.Client, bucket string) (*model.SuratFont, error) {
+	if s.fontRepo == nil {
+		return nil, fmt.Errorf("surat font repository not wired")
+	}
+	if in == nil || in.OrgID == uuid.Nil {
+		return nil, errors.New("organisasi font wajib diisi")
+	}
+	if mc == nil || bucket == "" {
+		return nil, fmt.Errorf("minio disabled or bucket missing")
+	}
+	family := strings.TrimSpace(in.Family)
+	if !fontFamilyPattern.MatchString(family) {
+		return nil, errors.New("nama font hanya boleh huruf, angka, spasi, - dan _ (maks 64)")
+	}
+	switch strings.ToLower(family) {
+	case "times", "helvetica", "arial", "courier", strings.ToLower(suratpdf.UnicodeFont):
+		return nil, errors.New("nama font sudah dipakai font bawaan")
+	}
+	style, ok := suratpdf.NormalizeFontStyle(in.Style)
+	if !ok {
+		return nil, errors.New("gaya font harus regular, B, I, atau BI")
+	}
+	if len(in.Data) == 0 || len(in.Data) > MaxFontSize {
+		return nil, errors.New("ukuran font maksimal 5MB")
+	}
+	if err := suratpdf.CheckFont(in.Data); err != nil {
+		return nil, fmt.Errorf("font tidak valid: %w", err)
+	}
+
+	m, err := s.fontRepo.Find(ctx, in.OrgID, family, style)
+	if err != nil || m == nil {
+		m = &model.SuratFont{OrgID: in.OrgID, Style: style}
+	}
+	oldKey := m.FileKey
+	key := fmt.Sprintf("private/fonts/%s/%s.ttf", in.OrgID, uuid.New())
+	if _, err := storage.UploadToMinio(ctx, mc, bucket, key, bytes.NewReader(in.Data), int64(len(in.Data)), "font/ttf"); err != nil {
+		return nil, err
+	}
+	m.Family = family
+	m.FileKey = key
+	m.Size = int64(len(in.Data))
+	m.UploadedBy = in.UploadedBy
+	if err := s.fontRepo.Save(ctx, m); err != nil {
+		_ = storage.DeleteFromMinio(ctx, mc, bucket, key)
+		return nil, err
+	}
+	if oldKey != "" {
+		_ = storage.DeleteFromMinio(ctx, mc, bucket, oldKey)
+	}
+	if s.audit != nil && in.UploadedBy != uuid.Nil {
+		s.audit.Log(ctx, in.UploadedBy, "surat_font_save", map[string]any{"org_id": in.OrgID, "family": family, "style": style})
+	}
+	return m, nil
+}
+
+func (s *suratService) ListFonts(ctx context.Context, orgID uuid.UUID) ([]model.SuratFont, error) {
+	if s.fontRepo == nil {
+		return nil, fmt.Errorf("surat font repository not wired")
+	}
+	return s.fontRepo.ListByOrg(ctx, orgID)
+}
+
+func (s *suratService) DeleteFont(ctx context.Context, userID, orgID uuid.UUID, id uint, mc *minio.Client, bucket string) error {
+	if s.fontRepo == nil {
+		return fmt.Errorf("surat font repository not wired")
+	}
+	m, err := s.fontRepo.Get(ctx, id)
+	if err != nil || m.OrgID != orgID {
+		return errors.New("font tidak ditemukan")
+	}
+	if err := s.fontRepo.Delete(ctx, m.ID); err != nil {
+		return err
+	}
+	_ = storage.DeleteFromMinio(ctx, mc, bucket, m.FileKey)
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_font_delete", map[string]any{"org_id": orgID, "family": m.Family, "style": m.Style})
+	}
+	return nil
+}
+
+// withFonts mengembalikan salinan theme berisi font organisasi bila FontFamily-nya
+// merujuk font unggahan. Font yang gagal dibaca dilewati; renderer lalu memakai
+// font bawaan.
+func (s *suratService) withFonts(ctx context.Context, orgID uuid.UUID, th *suratpdf.Theme, mc *minio.Client, bucket string) *suratpdf.Theme {
+	if th == nil || s.fontRepo == nil || orgID == uuid.Nil || strings.TrimSpace(th.FontFamily) == "" {
+		return th
+	}
+	rows, err := s.fontRepo.ListFamily(ctx, orgID, strings.TrimSpace(th.FontFamily))
+	if err != nil || len(rows) == 0 {
+		return th
+	}
+	out := *th
+	out.Fonts = nil
+	for _, f := range rows {
+		data, err := s.readObject(ctx, mc, bucket, f.FileKey)
+		if err != nil {
+			fmt.Printf("[SURAT] font %s/%s tidak tersedia: %v\n", f.Family, f.Style, err)
+			continue
+		}
+		out.Fonts = append(out.Fonts, suratpdf.Font{Family: th.FontFamily, Style: f.Style, Data: data})
+	}
+	return &out
+}
+
+// validateTheme menolak ukuran kertas/orientasi yang tidak dikenal.
+func validateTheme(th *suratpdf.Theme) error {
+	if th != nil && !suratpdf.ValidPaper(*th) {
+		return errors.New("paper_size harus F4, A4, Letter, atau Legal dan orientation portrait/landscape")
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/util/suratpdf"
+)
+
+// applyLetterhead mengisi bagian kop yang masih kosong dari profil kop organisasi.
+// Isian klien tidak ditimpa; Title yang diisi manual menonaktifkan baris kop otomatis.
+func applyLetterhead(org *model.Organization, p *suratpdf.Payload) {
+	if org == nil || p == nil {
+		return
+	}
+	if p.Header == nil {
+		p.Header = &suratpdf.Header{}
+	}
+	h, kop := p.Header, org.Letterhead
+	if h.LeftLogo == "" {
+		h.LeftLogo = firstNonEmpty(kop.LeftLogoKey, org.LogoKey)
+	}
+	if h.RightLogo == "" {
+		h.RightLogo = firstNonEmpty(kop.RightLogoKey, org.LogoKey)
+	}
+	if len(h.Title) == 0 && strings.TrimSpace(h.OrgName) == "" {
+		h.OrgName = firstNonEmpty(kop.Institution, org.Name)
+		if strings.TrimSpace(h.OrgUnit) == "" {
+			h.OrgUnit = kop.Unit
+		}
+		if strings.TrimSpace(h.OrgAddress) == "" {
+			h.OrgAddress = kop.Address
+		}
+		if strings.TrimSpace(h.OrgPhone) == "" {
+			h.OrgPhone = kop.Phone
+		}
+	}
+	if h.LineStyle == "" {
+		h.LineStyle = kop.LineStyle
+	}
+	if h.TextColor == "" {
+		h.TextColor = kop.TextColor
+	}
+	if h.LineColor == "" {
+		h.LineColor = kop.LineColor
+	}
+}
+
+// previewLetterhead menerapkan profil kop organisasi untuk pratinjau (Generate).
+func (s *suratService) previewLetterhead(ctx context.Context, orgID uuid.UUID, p *suratpdf.Payload) {
+	if s.orgRepo == nil || orgID == uuid.Nil {
+		return
+	}
+	if org, err := s.orgRepo.GetByID(ctx, orgID); err == nil && org != nil {
+		applyLetterhead(org, p)
+	}
+}
+
+// withLetterheadLogos mengembalikan salinan payload untuk dirender dengan logo kop
+// berupa object key MinIO diganti isi gambarnya (base64). Hanya key logo yang
+// tersimpan di profil organisasi orgID yang dibaca; logo base64/data URI dibiarkan,
+// dan nilai lain (key asing, path file) dikosongkan agar tidak pernah dibaca.
+func (s *suratService) withLetterheadLogos(ctx context.Context, orgID uuid.UUID, p suratpdf.Payload, mc *minio.Client, bucket string) suratpdf.Payload {
+	if p.Header == nil {
+		return p
+	}
+	var org *model.Organization
+	if s.orgRepo != nil && orgID != uuid.Nil {
+		org, _ = s.orgRepo.GetByID(ctx, orgID)
+	}
+	h := *p.Header
+	h.LeftLogo = s.letterheadLogo(ctx, org, h.LeftLogo, mc, bucket)
+	if h.RightLogo == p.Header.LeftLogo {
+		h.RightLogo = h.LeftLogo
+	} else {
+		h.RightLogo = s.letterheadLogo(ctx, org, h.RightLogo, mc, bucket)
+	}
+	p.Header = &h
+	return p
+}
+
+func (s *suratService) letterheadLogo(ctx context.Context, org *model.Organization, src string, mc *minio.Client, bucket string) string {
+	src = strings.TrimSpace(src)
+	if src == "" || isBase64Image(src) {
+		return src
+	}
+	if !isOrgLogoKey(org, src) {
+		fmt.Printf("[SURAT] logo kop %q bukan logo organisasi, diabaikan\n", src)
+		return ""
+	}
+	data, err := s.readObject(ctx, mc, bucket, src)
+	if err != nil {
+		fmt.Printf("[SURAT] logo kop %s tidak tersedia: %v\n", src, err)
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// isOrgLogoKey true bila key adalah logo organisasi atau logo kop yang tersimpan di profilnya.
+func isOrgLogoKey(org *model.Organization, key string) bool {
+	if org == nil {
+		return false
+	}
+	for _, k := range []string{org.LogoKey, org.Letterhead.LeftLogoKey, org.Letterhead.RightLogoKey} {
+		if k = strings.TrimSpace(k); k != "" && k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// isBase64Image membedakan gambar base64/data URI dari object key atau path.
+func isBase64Image(v string) bool {
+	if strings.HasPrefix(v, "data:") {
+		if i := strings.Index(v, ","); i >= 0 {
+			v = v[i+1:]
+		}
+	}
+	_, err := base64.StdEncoding.DecodeString(v)
+	return err == nil
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"simawa-backend/internal/model"
+)
+
+const defaultSuratNumberPattern = "{seq}/{org_slug}/{variant_code}/{roman_month}/{year}"
+
+var suratVariantCodes = map[string]string{
+	model.SuratVariantPeminjaman: "PMJ",
+	model.SuratVariantPengajuan:  "PGJ",
+	model.SuratVariantPermohonan: "PMH",
+	model.SuratVariantUndangan:   "UND",
+}
+
+var romanMonths = [...]string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X", "XI", "XII"}
+
+// NormalizeSuratVariant menyeragamkan varian surat ke bentuk konstanta model (uppercase).
+// Payload dari frontend memakai huruf kecil ("peminjaman"), sedangkan upload memakai uppercase.
+func NormalizeSuratVariant(v string) string {
+	v = strings.ToUpper(strings.TrimSpace(v))
+	if v == "" {
+		return model.SuratVariantPeminjaman
+	}
+	return v
+}
+
+// SuratVariantCode returns the short code printed in a surat number (e.g. PEMINJAMAN -> PMJ).
+func SuratVariantCode(variant string) string {
+	if code, ok := suratVariantCodes[variant]; ok {
+		return code
+	}
+	if len(variant) > 3 {
+		return variant[:3]
+	}
+	return variant
+}
+
+// RomanMonth returns the month in roman numerals as used on kop surat (1 -> I).
+func RomanMonth(m time.Month) string {
+	if m < time.January || m > time.December {
+		return ""
+	}
+	return romanMonths[m-1]
+}
+
+// FormatSuratNumber menyusun nomor surat dari pattern, mis.
+// "{seq}/{org_slug}/{variant_code}/{roman_month}/{year}" -> "007/BEM/PMJ/X/2026".
+func FormatSuratNumber(pattern string, seqDigits int, seq int, orgSlug, variant string, at time.Time) string {
+	if strings.TrimSpace(pattern) == "" {
+		pattern = defaultSuratNumberPattern
+	}
+	if seqDigits <= 0 {
+		seqDigits = 1
+	}
+	r := strings.NewReplacer(
+		"{seq}", fmt.Sprintf("%0*d", seqDigits, seq),
+		"{org_slug}", strings.ToUpper(orgSlug),
+		"{variant_code}", SuratVariantCode(variant),
+		"{roman_month}", RomanMonth(at.Month()),
+		"{month}", fmt.Sprintf("%02d", int(at.Month())),
+		"{year}", strconv.Itoa(at.Year()),
+	)
+	return r.Replace(pattern)
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/repository"
+	"simawa-backend/internal/util/storage"
+)
+
+// retentionBatch membatasi jumlah surat per varian yang diproses sekali jalan.
+const retentionBatch = 200
+
+const defaultColdPrefix = "cold/"
+
+// SuratRetentionInput adalah aturan retensi untuk satu varian surat.
+type SuratRetentionInput struct {
+	Variant      string
+	RetainMonths int
+	Action       string
+}
+
+// SuratRetentionResult merangkum satu kali jalan job retensi.
+type SuratRetentionResult struct {
+	Flagged  int `json:"flagged"`
+	Archived int `json:"archived"`
+	Failed   int `json:"failed"`
+}
+
+// SuratArchiveExport memilih surat satu organisasi (keluar maupun masuk) untuk
+// diekspor; rentang tanggal memakai tanggal dibuat dan inklusif.
+type SuratArchiveExport struct {
+	OrgID uuid.UUID
+	From  *time.Time
+	To    *time.Time
+}
+
+func (s *suratService) ListRetentionRules(ctx context.Context) ([]model.SuratRetentionRule, error) {
+	if s.retentionRepo == nil {
+		return nil, fmt.Errorf("surat retention repository not wired")
+	}
+	return s.retentionRepo.List(ctx)
+}
+
+// SaveRetentionRule membuat atau mengganti aturan retensi sebuah varian.
+func (s *suratService) SaveRetentionRule(ctx context.Context, userID uuid.UUID, in *SuratRetentionInput) (*model.SuratRetentionRule, error) {
+	if s.retentionRepo == nil {
+		return nil, fmt.Errorf("surat retention repository not wired")
+	}
+	variant := strings.ToUpper(strings.TrimSpace(in.Variant))
+	if _, ok := suratVariantCodes[variant]; !ok {
+		return nil, fmt.Errorf("varian surat %q tidak dikenal", in.Variant)
+	}
+	if in.RetainMonths < 1 || in.RetainMonths > 1200 {
+		return nil, errors.New("masa simpan harus 1 sampai 1200 bulan")
+	}
+	action := strings.ToUpper(strings.TrimSpace(in.Action))
+	switch action {
+	case "":
+		action = model.SuratRetentionFlag
+	case model.SuratRetentionFlag, model.SuratRetentionArchive:
+	default:
+		return nil, errors.New("aksi retensi harus FLAG atau ARCHIVE")
+	}
+
+	m, err := s.retentionRepo.GetByVariant(ctx, variant)
+	if err != nil || m == nil {
+		m = &model.SuratRetentionRule{Variant: variant}
+	}
+	m.RetainMonths = in.RetainMonths
+	m.Action = action
+	if userID != uuid.Nil {
+		m.UpdatedBy = &userID
+	}
+	if err := s.retentionRepo.Save(ctx, m); err != nil {
+		return nil, err
+	}
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_retention_save", map[string]any{"variant": variant, "retain_months": m.RetainMonths, "action": action})
+	}
+	return m, nil
+}
+
+func (s *suratService) DeleteRetentionRule(ctx context.Context, userID uuid.UUID, variant string) error {
+	if s.retentionRepo == nil {
+		return fmt.Errorf("surat retention repository not wired")
+	}
+	variant = strings.ToUpper(strings.TrimSpace(variant))
+	if _, err := s.retentionRepo.GetByVariant(ctx, variant); err != nil {
+		return errors.New("aturan retensi tidak ditemukan")
+	}
+	if err := s.retentionRepo.Delete(ctx, variant); err != nil {
+		return err
+	}
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_retention_delete", map[string]any{"variant": variant})
+	}
+	return nil
+}
+
+// ApplyRetention menandai surat final yang masa simpannya habis dan, untuk aturan
+// ARCHIVE, memindahkan PDF beserta versinya ke prefix cold storage. Dipanggil
+// berkala oleh server; surat yang sudah diproses tidak diambil lagi.
+func (s *suratService) ApplyRetention(ctx context.Context, mc *minio.Client, bucket string) (*SuratRetentionResult, error) {
+	if s.retentionRepo == nil || s.suratRepo == nil {
+		return nil, fmt.Errorf("surat retention repository not wired")
+	}
+	rules, err := s.retentionRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := &SuratRetentionResult{}
+	now := time.Now()
+	for i := range rules {
+		rule := &rules[i]
+		archive := rule.Action == model.SuratRetentionArchive
+		if archive && (mc == nil || bucket == "") {
+			fmt.Printf("[SURAT] retensi %s dilewati: minio tidak aktif\n", rule.Variant)
+			continue
+		}
+		rows, err := s.suratRepo.ListExpired(ctx, rule.Variant, now.AddDate(0, -rule.RetainMonths, 0), archive, retentionBatch)
+		if err != nil {
+			return out, err
+		}
+		for j := range rows {
+			id, status := rows[j].ID, rows[j].Status
+			// diproses di bawah kunci surat agar tidak bertabrakan dengan Void/Withdraw
+			err := s.suratRepo.WithLock(ctx, id, func() error {
+				row, err := s.suratRepo.Get(ctx, id)
+				if err != nil {
+					return err
+				}
+				if row.Status != status {
+					return nil // berubah sejak daftar diambil; diambil lagi run berikutnya bila masih kedaluwarsa
+				}
+				if row.ExpiredAt == nil {
+					row.ExpiredAt = &now
+					out.Flagged++
+				}
+				if archive {
+					if err := s.moveToCold(ctx, row, mc, bucket); err != nil {
+						fmt.Printf("[SURAT] arsip surat %d gagal: %v\n", row.ID, err)
+						out.Failed++
+					} else {
+						row.ArchivedAt = &now
+						out.Archived++
+					}
+				}
+				return s.suratRepo.Update(ctx, row)
+			})
+			if err != nil {
+				fmt.Printf("[SURAT] simpan status retensi surat %d gagal: %v\n", id, err)
+				out.Failed++
+			}
+		}
+		rule.LastRunAt = &now
+		_ = s.retentionRepo.Save(ctx, rule)
+	}
+	return out, nil
+}
+
+// moveToCold memindahkan PDF surat, semua versinya, dan lampirannya ke prefix cold
+// storage. Setiap key dipindah lalu referensinya langsung diperbarui, dan key yang
+// sudah pernah dipindah dikenali (lihat moveObjectOnce), sehingga kegagalan di
+// tengah jalan cukup diulang pada run berikutnya tanpa berkas terbelah.
+func (s *suratService) moveToCold(ctx context.Context, row *model.Surat, mc *minio.Client, bucket string) error {
+	prefix := defaultColdPrefix
+	if s.cfg != nil && strings.TrimSpace(s.cfg.ColdPrefix) != "" {
+		prefix = strings.TrimRight(strings.TrimSpace(s.cfg.ColdPrefix), "/") + "/"
+	}
+	keys := []string{row.FileKey}
+	if s.versionRepo != nil {
+		versions, err := s.versionRepo.List(ctx, row.ID)
+		if err != nil {
+			return err
+		}
+		for _, v := range versions {
+			keys = append(keys, v.FileKey)
+		}
+	}
+	if s.attachmentRepo != nil {
+		atts, err := s.attachmentRepo.ListBySurat(ctx, row.ID)
+		if err != nil {
+			return err
+		}
+		for _, a := range atts {
+			keys = append(keys, a.FileKey)
+		}
+	}
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if key == "" || seen[key] || strings.HasPrefix(key, prefix) {
+			continue
+		}
+		seen[key] = true
+		cold := prefix + key
+		if err := moveObjectOnce(ctx, mc, bucket, key, cold); err != nil {
+			return err
+		}
+		if s.versionRepo != nil {
+			if err := s.versionRepo.ReplaceFileKey(ctx, row.ID, key, cold); err != nil {
+				return err
+			}
+		}
+		if s.attachmentRepo != nil {
+			if err := s.attachmentRepo.ReplaceFileKey(ctx, row.ID, key, cold); err != nil {
+				return err
+			}
+		}
+		if row.FileKey == key {
+			row.FileKey = cold
+		}
+	}
+	return nil
+}
+
+// moveObjectOnce memindahkan key ke cold. Bila key asal sudah tidak ada tetapi
+// salinan cold-nya ada (run sebelumnya terhenti setelah memindah berkas), langkah
+// ini dianggap selesai agar referensinya tetap bisa diperbarui.
+func moveObjectOnce(ctx context.Context, mc *minio.Client, bucket, key, cold string) error {
+	if _, err := mc.StatObject(ctx, bucket, key, minio.StatObjectOptions{}); err != nil {
+		if minio.ToErrorResponse(err).Code != "NoSuchKey" {
+			return err
+		}
+		if _, cerr := mc.StatObject(ctx, bucket, cold, minio.StatObjectOptions{}); cerr == nil {
+			return nil
+		}
+		return err
+	}
+	return storage.MoveInMinio(ctx, mc, bucket, key, cold)
+}
+
+// ExportArchive menulis ZIP berisi PDF surat organisasi beserta index.csv langsung
+// ke w, sehingga arsip besar tidak perlu ditampung di memori. PDF yang tidak bisa
+// dibaca dilewati dan ditandai pada kolom Berkas di index.
+func (s *suratService) ExportArchive(ctx context.Context, q SuratArchiveExport, w io.Writer, mc *minio.Client, bucket string) error {
+	if s.suratRepo == nil {
+		return fmt.Errorf("surat repository not wired")
+	}
+	if mc == nil || bucket == "" {
+		return fmt.Errorf("minio disabled or bucket missing")
+	}
+	lq := repository.ListSuratQuery{ForOrgIDs: []uuid.UUID{q.OrgID}, Size: 100}
+	if q.From != nil {
+		lq.DateFrom = q.From.Format(time.RFC3339)
+	}
+	if q.To != nil {
+		lq.DateTo = q.To.Add(24*time.Hour - time.Nanosecond).Format(time.RFC3339Nano)
+	}
+
+	zw := zip.NewWriter(w)
+	var index [][]string
+	for lq.Page = 1; ; lq.Page++ {
+		rows, total, err := s.suratRepo.List(ctx, lq)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			index = append(index, s.exportArchiveRow(ctx, zw, row, q.OrgID, mc, bucket))
+		}
+		if len(rows) == 0 || int64(lq.Page*lq.Size) >= total {
+			break
+		}
+	}
+
+	iw, err := zw.Create("index.csv")
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(iw)
+	_ = cw.Write([]string{"Surat ID", "Nomor Surat", "Perihal", "Varian", "Arah", "Status", "Dibuat", "Disetujui", "Dibatalkan", "Alasan Batal", "Kedaluwarsa", "Berkas"})
+	_ = cw.WriteAll(index)
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (s *suratService) exportArchiveRow(ctx context.Context, zw *zip.Writer, row model.Surat, orgID uuid.UUID, mc *minio.Client, bucket string) []string {
+	dir := "keluar"
+	if row.OrgID != orgID {
+		dir = "masuk"
+	}
+	file := ""
+	if row.FileKey != "" {
+		label := row.Number
+		if label == "" {
+			label = row.Subject
+		}
+		name := fmt.Sprintf("%s/%d_%s.pdf", dir, row.ID, zipSafeName(label))
+		if err := copyObjectToZip(ctx, zw, name, mc, bucket, row.FileKey); err != nil {
+			fmt.Printf("[SURAT] ekspor arsip surat %d gagal: %v\n", row.ID, err)
+			file = "(tidak tersedia)"
+		} else {
+			file = name
+		}
+	}
+	return []string{
+		strconv.FormatUint(uint64(row.ID), 10),
+		row.Number,
+		row.Subject,
+		row.Variant,
+		strings.ToUpper(dir),
+		row.Status,
+		row.CreatedAt.Format("2006-01-02"),
+		formatDatePtr(row.ApprovedAt),
+		formatDatePtr(row.VoidedAt),
+		row.VoidReason,
+		formatDatePtr(row.ExpiredAt),
+		file,
+	}
+}
+
+func copyObjectToZip(ctx context.Context, zw *zip.Writer, name string, mc *minio.Client, bucket, key string) error {
+	obj, err := mc.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	// Stat dulu agar objek yang hilang tidak meninggalkan entri ZIP kosong.
+	if _, err := obj.Stat(); err != nil {
+		return err
+	}
+	fw, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, obj)
+	return err
+}
+
+func formatDatePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
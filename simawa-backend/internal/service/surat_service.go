@@ -3,18 +3,27 @@ package service
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
 
+	"simawa-backend/internal/config"
 	"simawa-backend/internal/model"
 	"simawa-backend/internal/repository"
+	"simawa-backend/internal/util/storage"
+	"simawa-backend/internal/util/suratdocx"
 	"simawa-backend/internal/util/suratpdf"
+	"simawa-backend/internal/util/suratsign"
 )
 
 type CreateSuratInput struct {
@@ -24,13 +33,28 @@ type CreateSuratInput struct {
 	Theme       *suratpdf.Theme
 	CreatedBy   uuid.UUID
 	Status      string
+
+	// TemplateID (opsional) mengisi payload dari SuratTemplate; Variables dan
+	// kegiatan dari ActivityID dipakai untuk placeholder-nya.
+	TemplateID *uint
+	Variables  map[string]string
+	ActivityID *uuid.UUID
+
+	// BatchID diisi saat surat dibuat oleh mail-merge (CreateBatch).
+	BatchID *uint
+
+	// EmailTo/EmailCc (opsional) menerima PDF surat lewat email setelah disetujui.
+	EmailTo []string
+	EmailCc []string
+
+	// TembusanOrgIDs organisasi penerima tembusan; namanya ditambahkan ke payload.Tembusan.
+	TembusanOrgIDs []uuid.UUID
 }
 
 type UploadSuratInput struct {
 	OrgID       uuid.UUID
 	TargetOrgID *uuid.UUID
 	Subject     string
-	Number      string
 	ToRole      string
 	ToName      string
 	Variant     string
@@ -39,22 +63,38 @@ type UploadSuratInput struct {
 	CreatedBy   uuid.UUID
 }
 
+// ResubmitSuratInput berisi perbaikan untuk surat berstatus REVISION: payload baru
+// (dirender ulang) atau file PDF pengganti.
+type ResubmitSuratInput struct {
+	ID       uint
+	UserID   uuid.UUID
+	Payload  *suratpdf.Payload
+	Theme    *suratpdf.Theme
+	File     io.Reader
+	FileName string
+	Reason   string
+}
+
 type InboxFilter struct {
 	OrgIDs []uuid.UUID
 	Roles  []string
 	Status string
+	CC     bool // true: hanya surat yang ditembuskan ke organisasi user
 	Page   int
 	Size   int
 }
 
 type SuratService interface {
-	Generate(ctx context.Context, payload suratpdf.Payload, theme *suratpdf.Theme) ([]byte, error)
-	GenerateAndUpload(ctx context.Context, payload suratpdf.Payload, theme *suratpdf.Theme, mc *minio.Client, bucket string) (string, error)
+	Generate(ctx context.Context, orgID uuid.UUID, payload suratpdf.Payload, theme *suratpdf.Theme, mc *minio.Client, bucket string) ([]byte, error)
+	GenerateAndUpload(ctx context.Context, orgID uuid.UUID, payload suratpdf.Payload, theme *suratpdf.Theme, mc *minio.Client, bucket string) (string, error)
+	GenerateDOCX(ctx context.Context, orgID uuid.UUID, payload suratpdf.Payload, theme *suratpdf.Theme, mc *minio.Client, bucket string) ([]byte, error)
+	ExportDOCX(ctx context.Context, id uint, mc *minio.Client, bucket string) ([]byte, string, error)
 	Create(ctx context.Context, in *CreateSuratInput, mc *minio.Client, bucket string) (*model.Surat, error)
 	Upload(ctx context.Context, in *UploadSuratInput, mc *minio.Client, bucket string) (*model.Surat, error)
-	Submit(ctx context.Context, userID uuid.UUID, id uint) (*model.Surat, error)
-	Decide(ctx context.Context, approver uuid.UUID, id uint, approve bool, note string) (*model.Surat, error)
+	Submit(ctx context.Context, userID uuid.UUID, id uint, mc *minio.Client, bucket string) (*model.Surat, error)
+	Decide(ctx context.Context, approver uuid.UUID, id uint, approve bool, note string, mc *minio.Client, bucket string) (*model.Surat, error)
 	Revise(ctx context.Context, approver uuid.UUID, id uint, note string) (*model.Surat, error)
+	Resubmit(ctx context.Context, in *ResubmitSuratInput, mc *minio.Client, bucket string) (*model.Surat, error)
 	SaveMetadata(ctx context.Context, m *model.Surat) error
 	Get(ctx context.Context, id uint) (*model.Surat, error)
 	List(ctx context.Context, q repository.ListSuratQuery) ([]model.Surat, int64, error)
@@ -62,29 +102,193 @@ type SuratService interface {
 	ListInbox(ctx context.Context, in InboxFilter) ([]model.Surat, int64, error)
 	ListArchive(ctx context.Context, orgIDs []uuid.UUID, page, size int) ([]model.Surat, int64, error)
 	PresignOrURL(ctx context.Context, mc *minio.Client, bucket string, key string, expire time.Duration) (string, error)
+	ListNumberRegister(ctx context.Context, q repository.ListSuratNumberQuery) ([]model.SuratNumber, int64, error)
+	ExportNumberRegister(ctx context.Context, q repository.ListSuratNumberQuery) ([]byte, string, error)
+	Verify(ctx context.Context, code string) (*SuratVerification, error)
+	ValidateSignature(ctx context.Context, id uint, content []byte) (*SuratSignatureCheck, error)
+	SigningKey() (*SuratSigningKey, error)
+	ListTemplates(ctx context.Context, orgID *uuid.UUID, variant string) ([]model.SuratTemplate, error)
+	GetTemplate(ctx context.Context, id uint) (*model.SuratTemplate, error)
+	CreateTemplate(ctx context.Context, userID uuid.UUID, in *SuratTemplateInput) (*model.SuratTemplate, error)
+	UpdateTemplate(ctx context.Context, userID uuid.UUID, id uint, in *SuratTemplateInput) (*model.SuratTemplate, error)
+	DeleteTemplate(ctx context.Context, userID uuid.UUID, id uint) error
+	ListVersions(ctx context.Context, id uint) ([]model.SuratVersion, error)
+	GetVersion(ctx context.Context, id uint, version int) (*model.SuratVersion, error)
+	DiffVersions(ctx context.Context, id uint, from, to int) (*SuratVersionDiff, error)
+	AddAttachments(ctx context.Context, userID uuid.UUID, id uint, files []SuratAttachmentUpload, mc *minio.Client, bucket string) ([]model.SuratAttachment, error)
+	DeleteAttachment(ctx context.Context, userID uuid.UUID, id, attachmentID uint, mc *minio.Client, bucket string) error
+	ListAttachments(ctx context.Context, id uint) ([]model.SuratAttachment, error)
+	AttachmentBundle(ctx context.Context, id uint, mc *minio.Client, bucket string) ([]byte, string, error)
+	Dispose(ctx context.Context, in *DisposeSuratInput) ([]model.SuratDisposition, error)
+	CompleteDisposition(ctx context.Context, userID uuid.UUID, id uint, note string) (*model.SuratDisposition, error)
+	ListDispositions(ctx context.Context, suratID uint) ([]model.SuratDisposition, error)
+	ListMyDispositions(ctx context.Context, userID uuid.UUID, status string, page, size int) ([]model.SuratDisposition, int64, error)
+	IsDispositionAssignee(ctx context.Context, suratID uint, userID uuid.UUID) bool
+	SetTembusan(ctx context.Context, userID uuid.UUID, id uint, orgIDs []uuid.UUID, mc *minio.Client, bucket string) ([]model.SuratTembusan, error)
+	ListTembusan(ctx context.Context, suratID uint) ([]model.SuratTembusan, error)
+	IsTembusanRecipient(ctx context.Context, suratID uint, orgIDs []uuid.UUID) bool
+	SaveSpecimen(ctx context.Context, in *SuratSpecimenUpload, mc *minio.Client, bucket string) (*model.SuratSpecimen, error)
+	GetSpecimen(ctx context.Context, kind string, ownerID uuid.UUID) (*model.SuratSpecimen, error)
+	DeleteSpecimen(ctx context.Context, userID uuid.UUID, kind string, ownerID uuid.UUID, mc *minio.Client, bucket string) error
+
+	// Font TTF organisasi untuk Theme.FontFamily
+	SaveFont(ctx context.Context, in *SuratFontUpload, mc *minio.Client, bucket string) (*model.SuratFont, error)
+	ListFonts(ctx context.Context, orgID uuid.UUID) ([]model.SuratFont, error)
+	DeleteFont(ctx context.Context, userID, orgID uuid.UUID, id uint, mc *minio.Client, bucket string) error
+	Sign(ctx context.Context, userID uuid.UUID, id uint, approve bool, note string, mc *minio.Client, bucket string) (*model.Surat, error)
+	SigningStatus(ctx context.Context, id uint) (*SuratSigningStatus, error)
+	ListMySignatures(ctx context.Context, userID uuid.UUID) ([]model.SuratSignature, error)
+	RemindSigner(ctx context.Context, userID uuid.UUID, id uint) (*model.SuratSignature, error)
+	RemindPendingSigners(ctx context.Context, olderThan time.Duration) int
+	IsSigner(ctx context.Context, suratID uint, userID uuid.UUID) bool
+	CreateBatch(ctx context.Context, in *CreateSuratBatchInput, mc *minio.Client, bucket string) (*model.SuratBatch, error)
+	GetBatch(ctx context.Context, id uint) (*model.SuratBatch, error)
+	ListBatchSurat(ctx context.Context, id uint) ([]model.Surat, error)
+	SubmitBatch(ctx context.Context, userID uuid.UUID, id uint, mc *minio.Client, bucket string) (*SuratBatchSubmitResult, error)
+	BatchArchive(ctx context.Context, id uint, mc *minio.Client, bucket string) ([]byte, string, error)
+	FailInterruptedBatches(ctx context.Context) (int, error)
+	RegisterIncoming(ctx context.Context, in *RegisterIncomingInput, mc *minio.Client, bucket string) (*model.Surat, error)
+	GetAgenda(ctx context.Context, suratID uint) (*model.SuratAgenda, error)
+	ListAgenda(ctx context.Context, q repository.ListSuratAgendaQuery) ([]model.SuratAgenda, int64, error)
+	ExportAgenda(ctx context.Context, q repository.ListSuratAgendaQuery, format string) ([]byte, string, string, error)
+	SetEmailRecipients(ctx context.Context, userID uuid.UUID, id uint, to, cc []string) (*model.Surat, error)
+	ListDeliveries(ctx context.Context, id uint) ([]model.SuratDelivery, error)
+	RetryDelivery(ctx context.Context, userID uuid.UUID, id uint, mc *minio.Client, bucket string) (int, error)
+
+	// Penarikan (PENDING → DRAFT) dan pembatalan (APPROVED → VOIDED) oleh organisasi pembuat
+	Withdraw(ctx context.Context, userID uuid.UUID, id uint, mc *minio.Client, bucket string) (*model.Surat, error)
+	Void(ctx context.Context, userID uuid.UUID, id uint, reason string, mc *minio.Client, bucket string) (*model.Surat, error)
+
+	// Retensi arsip per varian dan ekspor ZIP arsip organisasi
+	ListRetentionRules(ctx context.Context) ([]model.SuratRetentionRule, error)
+	SaveRetentionRule(ctx context.Context, userID uuid.UUID, in *SuratRetentionInput) (*model.SuratRetentionRule, error)
+	DeleteRetentionRule(ctx context.Context, userID uuid.UUID, variant string) error
+	ApplyRetention(ctx context.Context, mc *minio.Client, bucket string) (*SuratRetentionResult, error)
+	ExportArchive(ctx context.Context, q SuratArchiveExport, w io.Writer, mc *minio.Client, bucket string) error
+}
+
+// SuratVerification adalah data publik yang ditampilkan saat kode verifikasi surat dicek.
+type SuratVerification struct {
+	Code       string     `json:"code"`
+	Number     string     `json:"number"`
+	Subject    string     `json:"subject"`
+	Variant    string     `json:"variant"`
+	Status     string     `json:"status"`
+	OrgName    string     `json:"org_name"`
+	ApprovedBy string     `json:"approved_by"`
+	ApprovedAt *time.Time `json:"approved_at"`
+	Voided     bool       `json:"voided"`
+	VoidedAt   *time.Time `json:"voided_at,omitempty"`
+	VoidReason string     `json:"void_reason,omitempty"`
+}
+
+// SuratSignatureCheck adalah hasil pencocokan file PDF dengan tanda tangan surat yang tersimpan.
+type SuratSignatureCheck struct {
+	SuratID        uint       `json:"surat_id"`
+	Number         string     `json:"number"`
+	Status         string     `json:"status"`
+	Valid          bool       `json:"valid"`
+	HashMatch      bool       `json:"hash_match"`
+	SignatureValid bool       `json:"signature_valid"`
+	ContentHash    string     `json:"content_hash"`
+	UploadedHash   string     `json:"uploaded_hash"`
+	KeyID          string     `json:"key_id"`
+	SignedAt       *time.Time `json:"signed_at"`
+	Message        string     `json:"message"`
+}
+
+// SuratSigningKey adalah kunci publik penandatangan surat untuk verifikasi mandiri.
+type SuratSigningKey struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"`
 }
 
 type suratService struct {
-	suratRepo repository.SuratRepository
-	audit     *AuditService
-	notify    *NotificationService
-	orgRepo   repository.OrganizationRepository
+	suratRepo       repository.SuratRepository
+	numberRepo      repository.SuratNumberRepository
+	templateRepo    repository.SuratTemplateRepository
+	versionRepo     repository.SuratVersionRepository
+	attachmentRepo  repository.SuratAttachmentRepository
+	dispositionRepo repository.SuratDispositionRepository
+	batchRepo       repository.SuratBatchRepository
+	agendaRepo      repository.SuratAgendaRepository
+	deliveryRepo    repository.SuratDeliveryRepository
+	tembusanRepo    repository.SuratTembusanRepository
+	specimenRepo    repository.SuratSpecimenRepository
+	signatureRepo   repository.SuratSignatureRepository
+	fontRepo        repository.SuratFontRepository
+	retentionRepo   repository.SuratRetentionRepository
+	memberRepo      repository.OrgMemberRepository
+	audit           *AuditService
+	notify          *NotificationService
+	email           *EmailService
+	orgRepo         repository.OrganizationRepository
+	userRepo        repository.UserRepository
+	activityRepo    repository.ActivityRepository
+	cfg             *config.SuratEnv
+	signer          *suratsign.Keystore
+	// sending berisi target email ("surat|email|kind") yang sedang dikirim di background.
+	sending sync.Map
 }
 
-func NewSuratServiceWithRepo(suratRepo repository.SuratRepository, orgRepo repository.OrganizationRepository, audit *AuditService, notify *NotificationService) SuratService {
-	return &suratService{suratRepo: suratRepo, orgRepo: orgRepo, audit: audit, notify: notify}
+func NewSuratServiceWithRepo(suratRepo repository.SuratRepository, numberRepo repository.SuratNumberRepository, templateRepo repository.SuratTemplateRepository, versionRepo repository.SuratVersionRepository, attachmentRepo repository.SuratAttachmentRepository, dispositionRepo repository.SuratDispositionRepository, batchRepo repository.SuratBatchRepository, agendaRepo repository.SuratAgendaRepository, deliveryRepo repository.SuratDeliveryRepository, tembusanRepo repository.SuratTembusanRepository, specimenRepo repository.SuratSpecimenRepository, signatureRepo repository.SuratSignatureRepository, fontRepo repository.SuratFontRepository, retentionRepo repository.SuratRetentionRepository, orgRepo repository.OrganizationRepository, memberRepo repository.OrgMemberRepository, userRepo repository.UserRepository, activityRepo repository.ActivityRepository, audit *AuditService, notify *NotificationService, email *EmailService, cfg *config.SuratEnv, signer *suratsign.Keystore) SuratService {
+	return &suratService{suratRepo: suratRepo, numberRepo: numberRepo, templateRepo: templateRepo, versionRepo: versionRepo, attachmentRepo: attachmentRepo, dispositionRepo: dispositionRepo, batchRepo: batchRepo, agendaRepo: agendaRepo, deliveryRepo: deliveryRepo, tembusanRepo: tembusanRepo, specimenRepo: specimenRepo, signatureRepo: signatureRepo, fontRepo: fontRepo, retentionRepo: retentionRepo, orgRepo: orgRepo, memberRepo: memberRepo, userRepo: userRepo, activityRepo: activityRepo, audit: audit, notify: notify, email: email, cfg: cfg, signer: signer}
 }
 func NewSuratService() SuratService { return &suratService{} }
 
-func (s *suratService) Generate(ctx context.Context, payload suratpdf.Payload, theme *suratpdf.Theme) ([]byte, error) {
-	return suratpdf.Render(payload, theme)
+// Generate merender pratinjau PDF; kop yang kosong diisi dari profil kop orgID.
+func (s *suratService) Generate(ctx context.Context, orgID uuid.UUID, payload suratpdf.Payload, theme *suratpdf.Theme, mc *minio.Client, bucket string) ([]byte, error) {
+	if err := validateTheme(theme); err != nil {
+		return nil, err
+	}
+	stripSignImages(&payload)
+	payload.Verification = nil
+	s.previewLetterhead(ctx, orgID, &payload)
+	return suratpdf.Render(s.withLetterheadLogos(ctx, orgID, payload, mc, bucket), s.withFonts(ctx, orgID, theme, mc, bucket))
+}
+
+func (s *suratService) GenerateDOCX(ctx context.Context, orgID uuid.UUID, payload suratpdf.Payload, theme *suratpdf.Theme, mc *minio.Client, bucket string) ([]byte, error) {
+	if err := validateTheme(theme); err != nil {
+		return nil, err
+	}
+	stripSignImages(&payload)
+	payload.Verification = nil
+	s.previewLetterhead(ctx, orgID, &payload)
+	return suratdocx.Render(s.withLetterheadLogos(ctx, orgID, payload, mc, bucket), theme)
+}
+
+// ExportDOCX merender surat tersimpan ke DOCX dari payload di meta_json.
+// Lampiran tidak ikut, karena hanya bisa digabung ke PDF. Spesimen TTD/cap juga
+// tidak ikut: DOCX bisa disunting, jadi tanda tangan hanya ada di PDF.
+func (s *suratService) ExportDOCX(ctx context.Context, id uint, mc *minio.Client, bucket string) ([]byte, string, error) {
+	if s.suratRepo == nil {
+		return nil, "", fmt.Errorf("surat repository not wired")
+	}
+	row, err := s.suratRepo.Get(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	payload, theme := decodeSuratPayload(row.MetaJSON)
+	if payload == nil {
+		return nil, "", errors.New("surat hasil upload tidak tersedia dalam format docx")
+	}
+	payload.Meta.Number = row.Number
+	stripSignImages(payload)
+	data, err := suratdocx.Render(s.withLetterheadLogos(ctx, row.OrgID, *payload, mc, bucket), theme)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, fmt.Sprintf("surat_%d.docx", row.ID), nil
 }
 
-func (s *suratService) GenerateAndUpload(ctx context.Context, payload suratpdf.Payload, theme *suratpdf.Theme, mc *minio.Client, bucket string) (string, error) {
+func (s *suratService) GenerateAndUpload(ctx context.Context, orgID uuid.UUID, payload suratpdf.Payload, theme *suratpdf.Theme, mc *minio.Client, bucket string) (string, error) {
 	if mc == nil || bucket == "" {
 		return "", fmt.Errorf("minio disabled or bucket missing")
 	}
-	pdfBytes, err := suratpdf.Render(payload, theme)
+	stripSignImages(&payload)
+	payload.Verification = nil
+	pdfBytes, err := suratpdf.Render(s.withLetterheadLogos(ctx, orgID, payload, mc, bucket), theme)
 	if err != nil {
 		return "", err
 	}
@@ -116,42 +320,60 @@ func (s *suratService) Create(ctx context.Context, in *CreateSuratInput, mc *min
 	if in.OrgID == uuid.Nil {
 		return nil, errors.New("org_id required")
 	}
+	if err := validateTheme(in.Theme); err != nil {
+		return nil, err
+	}
 
-	status := strings.ToUpper(strings.TrimSpace(in.Status))
-	if status == "" {
-		status = model.SuratStatusPending
+	status, err := initialSuratStatus(in.Status)
+	if err != nil {
+		return nil, err
 	}
 	if in.Payload.CreatedAt.IsZero() {
 		in.Payload.CreatedAt = time.Now()
 	}
 
-	// Prefill header/logo dari organisasi jika ada dan belum diisi.
+	// Prefill kop dari profil kop organisasi jika belum diisi.
+	var org *model.Organization
 	if s.orgRepo != nil {
-		if org, err := s.orgRepo.GetByID(ctx, in.OrgID); err == nil && org != nil {
-			if in.Payload.Header == nil {
-				in.Payload.Header = &suratpdf.Header{}
-			}
-			if in.Payload.Header.LeftLogo == "" {
-				in.Payload.Header.LeftLogo = org.LogoKey
-			}
-			if in.Payload.Header.RightLogo == "" {
-				in.Payload.Header.RightLogo = org.LogoKey
-			}
-			if strings.TrimSpace(in.Payload.Header.OrgName) == "" && len(in.Payload.Header.Title) == 0 && strings.TrimSpace(org.Name) != "" {
-				in.Payload.Header.OrgName = org.Name
-			}
+		if o, err := s.orgRepo.GetByID(ctx, in.OrgID); err == nil && o != nil {
+			org = o
+			applyLetterhead(org, &in.Payload)
 		}
 	}
 
-	key, err := s.GenerateAndUpload(ctx, in.Payload, in.Theme, mc, bucket)
+	if in.TemplateID != nil {
+		if err := s.applyTemplate(ctx, in, org); err != nil {
+			return nil, err
+		}
+	}
+	emailTo, err := normalizeEmails(in.EmailTo)
+	if err != nil {
+		return nil, err
+	}
+	emailCc, err := normalizeEmails(in.EmailCc)
+	if err != nil {
+		return nil, err
+	}
+	tembusan, err := s.resolveTembusan(ctx, in.OrgID, in.TembusanOrgIDs, &in.Payload)
 	if err != nil {
 		return nil, err
 	}
+	stripSignImages(&in.Payload)
+	// Stempel verifikasi hanya diisi stampVerification saat surat disetujui.
+	in.Payload.Verification = nil
+	if err := s.validateSigners(ctx, &in.Payload); err != nil {
+		return nil, err
+	}
+
+	// Nomor surat selalu diambil dari counter; nomor ketikan manual diabaikan.
+	in.Payload.Meta.Number = ""
 
 	meta := map[string]any{
 		"place_and_date": in.Payload.Meta.PlaceAndDate,
 		"footer":         in.Payload.Footer,
 		"created_at":     in.Payload.CreatedAt,
+		"payload":        in.Payload,
+		"theme":          in.Theme,
 	}
 	metaB, _ := json.Marshal(meta)
 
@@ -167,24 +389,45 @@ func (s *suratService) Create(ctx context.Context, in *CreateSuratInput, mc *min
 	row := &model.Surat{
 		OrgID:       in.OrgID,
 		TargetOrgID: in.TargetOrgID,
-		Variant:     string(in.Payload.Variant),
+		BatchID:     in.BatchID,
+		Source:      model.SuratSourceInternal,
+		Variant:     NormalizeSuratVariant(string(in.Payload.Variant)),
 		Status:      status,
-		Number:      in.Payload.Meta.Number,
 		Subject:     in.Payload.Meta.Subject,
 		ToRole:      in.Payload.Meta.ToRole,
 		ToName:      in.Payload.Meta.ToName,
 		ToPlace:     in.Payload.Meta.ToPlace,
 		ToCity:      in.Payload.Meta.ToCity,
-		FileKey:     key,
 		FileURL:     "",
 		CreatedBy:   createdByPtr,
 		SubmittedBy: submittedByPtr,
+		EmailTo:     encodeEmails(emailTo),
+		EmailCc:     encodeEmails(emailCc),
 		MetaJSON:    metaB,
 	}
 
-	if err := s.suratRepo.Create(ctx, row); err != nil {
+	if status == model.SuratStatusDraft {
+		// Draft belum bernomor; nomor diberikan saat Submit.
+		key, err := s.GenerateAndUpload(ctx, in.OrgID, in.Payload, s.withFonts(ctx, in.OrgID, in.Theme, mc, bucket), mc, bucket)
+		if err != nil {
+			return nil, err
+		}
+		row.FileKey = key
+		if err := s.suratRepo.Create(ctx, row); err != nil {
+			return nil, err
+		}
+	} else if err := s.issueNumber(ctx, row, org, &in.Payload, in.Theme, createdByPtr, mc, bucket); err != nil {
 		return nil, err
 	}
+	if err := s.saveTembusan(ctx, row.ID, tembusan); err != nil {
+		fmt.Printf("[SURAT] simpan tembusan surat %d gagal: %v\n", row.ID, err)
+	}
+	s.recordVersion(ctx, row, createdByPtr, "surat dibuat")
+	if row.Status == model.SuratStatusPending {
+		if err := s.startSigning(ctx, row); err != nil {
+			fmt.Printf("[SURAT] susun tanda tangan surat %d gagal: %v\n", row.ID, err)
+		}
+	}
 
 	if s.audit != nil && in.CreatedBy != uuid.Nil {
 		s.audit.Log(ctx, in.CreatedBy, "surat_create", map[string]any{"surat_id": row.ID, "org_id": row.OrgID, "status": row.Status})
@@ -192,6 +435,20 @@ func (s *suratService) Create(ctx context.Context, in *CreateSuratInput, mc *min
 	return row, nil
 }
 
+// initialSuratStatus memvalidasi status surat baru: hanya DRAFT atau PENDING (kosong
+// berarti PENDING). APPROVED hanya lewat penanda tangan terakhir agar stempel
+// verifikasi dan tanda tangan digital selalu terpasang.
+func initialSuratStatus(raw string) (string, error) {
+	switch status := strings.ToUpper(strings.TrimSpace(raw)); status {
+	case "":
+		return model.SuratStatusPending, nil
+	case model.SuratStatusDraft, model.SuratStatusPending:
+		return status, nil
+	default:
+		return "", errors.New("status surat baru hanya boleh DRAFT atau PENDING")
+	}
+}
+
 func (s *suratService) Upload(ctx context.Context, in *UploadSuratInput, mc *minio.Client, bucket string) (*model.Surat, error) {
 	if in == nil {
 		return nil, errors.New("input nil")
@@ -236,17 +493,14 @@ func (s *suratService) Upload(ctx context.Context, in *UploadSuratInput, mc *min
 		createdByPtr = &in.CreatedBy
 	}
 
-	variant := in.Variant
-	if variant == "" {
-		variant = "PEMINJAMAN"
-	}
+	variant := NormalizeSuratVariant(in.Variant)
 
 	row := &model.Surat{
 		OrgID:       in.OrgID,
 		TargetOrgID: in.TargetOrgID,
+		Source:      model.SuratSourceInternal,
 		Variant:     variant,
 		Status:      model.SuratStatusDraft,
-		Number:      in.Number,
 		Subject:     in.Subject,
 		ToRole:      in.ToRole,
 		ToName:      in.ToName,
@@ -258,6 +512,7 @@ func (s *suratService) Upload(ctx context.Context, in *UploadSuratInput, mc *min
 	if err := s.suratRepo.Create(ctx, row); err != nil {
 		return nil, err
 	}
+	s.recordVersion(ctx, row, createdByPtr, "surat diunggah")
 
 	if s.audit != nil && in.CreatedBy != uuid.Nil {
 		s.audit.Log(ctx, in.CreatedBy, "surat_upload", map[string]any{"surat_id": row.ID, "org_id": row.OrgID})
@@ -265,7 +520,7 @@ func (s *suratService) Upload(ctx context.Context, in *UploadSuratInput, mc *min
 	return row, nil
 }
 
-func (s *suratService) Submit(ctx context.Context, userID uuid.UUID, id uint) (*model.Surat, error) {
+func (s *suratService) Submit(ctx context.Context, userID uuid.UUID, id uint, mc *minio.Client, bucket string) (*model.Surat, error) {
 	if s.suratRepo == nil {
 		return nil, fmt.Errorf("surat repository not wired")
 	}
@@ -277,22 +532,144 @@ func (s *suratService) Submit(ctx context.Context, userID uuid.UUID, id uint) (*
 		return nil, errors.New("only draft can be submitted")
 	}
 	row.Status = model.SuratStatusPending
+	var submittedBy *uuid.UUID
 	if userID != uuid.Nil {
 		row.SubmittedBy = &userID
+		submittedBy = &userID
 	}
-	if err := s.suratRepo.Update(ctx, row); err != nil {
+	// Nomor selalu berasal dari counter/register, tidak pernah dari input klien.
+	var org *model.Organization
+	if s.orgRepo != nil {
+		org, _ = s.orgRepo.GetByID(ctx, row.OrgID)
+	}
+	payload, theme := decodeSuratPayload(row.MetaJSON)
+	if err := s.issueNumber(ctx, row, org, payload, theme, submittedBy, mc, bucket); err != nil {
 		return nil, err
 	}
+	s.recordVersion(ctx, row, submittedBy, "nomor surat diterbitkan")
+	if err := s.startSigning(ctx, row); err != nil {
+		fmt.Printf("[SURAT] susun tanda tangan surat %d gagal: %v\n", row.ID, err)
+	}
 	if s.audit != nil && userID != uuid.Nil {
-		s.audit.Log(ctx, userID, "surat_submit", map[string]any{"surat_id": row.ID, "org_id": row.OrgID})
+		s.audit.Log(ctx, userID, "surat_submit", map[string]any{"surat_id": row.ID, "org_id": row.OrgID, "number": row.Number})
 	}
 	return row, nil
 }
 
-func (s *suratService) Decide(ctx context.Context, approver uuid.UUID, id uint, approve bool, note string) (*model.Surat, error) {
+// issueNumber mencadangkan nomor surat dari counter org/varian/tahun (atau memakai
+// nomor yang sudah dicadangkan untuk surat ini di register) lalu menyimpan row.
+// Render ulang PDF dengan nomor tersebut dilakukan setelah transaksi counter
+// selesai, agar alokasi lain tidak menunggu render/upload. Bila render gagal,
+// surat dikembalikan ke draft dengan nomor tetap tercadang untuk pengajuan ulang.
+func (s *suratService) issueNumber(ctx context.Context, row *model.Surat, org *model.Organization, payload *suratpdf.Payload, theme *suratpdf.Theme, issuedBy *uuid.UUID, mc *minio.Client, bucket string) error {
+	if s.numberRepo == nil {
+		return fmt.Errorf("surat number repository not wired")
+	}
+	var reserved *model.SuratNumber
+	if row.ID != 0 {
+		r, err := s.numberRepo.GetBySurat(ctx, row.ID)
+		if err != nil {
+			return err
+		}
+		reserved = r
+	}
+	if reserved != nil {
+		row.Number = reserved.Number
+		if payload == nil {
+			return s.suratRepo.Update(ctx, row)
+		}
+	} else {
+		now := time.Now()
+		pattern, digits, slug := defaultSuratNumberPattern, 3, ""
+		if s.cfg != nil {
+			pattern, digits = s.cfg.NumberPattern, s.cfg.SeqDigits
+		}
+		if org != nil {
+			slug = org.Slug
+			if strings.TrimSpace(org.SuratNumberPattern) != "" {
+				pattern = org.SuratNumberPattern
+			}
+		}
+		if slug == "" {
+			slug = row.OrgID.String()[:8]
+		}
+		if _, err := s.numberRepo.Allocate(ctx, row, now.Year(), issuedBy, func(seq int) string {
+			return FormatSuratNumber(pattern, digits, seq, slug, row.Variant, now)
+		}); err != nil {
+			return err
+		}
+		if payload == nil {
+			// Surat hasil upload: PDF tidak dapat diubah, nomor hanya tercatat di metadata.
+			return nil
+		}
+	}
+
+	payload.Meta.Number = row.Number
+	key, err := s.renderAndUpload(ctx, row, payload, theme, mc, bucket)
+	if err == nil {
+		prevKey, prevMeta := row.FileKey, row.MetaJSON
+		row.FileKey = key
+		row.MetaJSON = withSuratPayload(row.MetaJSON, payload)
+		if err = s.suratRepo.Update(ctx, row); err != nil {
+			_ = storage.DeleteFromMinio(ctx, mc, bucket, key)
+			row.FileKey, row.MetaJSON = prevKey, prevMeta
+		}
+	}
+	if err != nil {
+		row.Status = model.SuratStatusDraft
+		row.SubmittedBy = nil
+		if uerr := s.suratRepo.Update(ctx, row); uerr != nil {
+			fmt.Printf("[SURAT] kembalikan surat %d ke draft gagal: %v\n", row.ID, uerr)
+		}
+		return fmt.Errorf("nomor %s tercadang, tetapi render surat gagal; surat disimpan sebagai draft: %w", row.Number, err)
+	}
+	return nil
+}
+
+// decodeSuratPayload membaca payload & theme yang disimpan di meta_json saat Create.
+// Mengembalikan payload nil untuk surat hasil upload atau data lama tanpa payload.
+func decodeSuratPayload(raw []byte) (*suratpdf.Payload, *suratpdf.Theme) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var meta struct {
+		Payload *suratpdf.Payload `json:"payload"`
+		Theme   *suratpdf.Theme   `json:"theme"`
+	}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, nil
+	}
+	return meta.Payload, meta.Theme
+}
+
+// withSuratPayload mengganti key "payload" di meta_json tanpa menyentuh key lain.
+func withSuratPayload(raw []byte, payload *suratpdf.Payload) []byte {
+	meta := map[string]any{}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &meta)
+	}
+	meta["payload"] = payload
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return raw
+	}
+	return b
+}
+
+func (s *suratService) Decide(ctx context.Context, approver uuid.UUID, id uint, approve bool, note string, mc *minio.Client, bucket string) (*model.Surat, error) {
 	if s.suratRepo == nil {
 		return nil, fmt.Errorf("surat repository not wired")
 	}
+	var out *model.Surat
+	err := s.suratRepo.WithLock(ctx, id, func() error {
+		row, err := s.decide(ctx, approver, id, approve, note, mc, bucket)
+		out = row
+		return err
+	})
+	return out, err
+}
+
+func (s *suratService) decide(ctx context.Context, approver uuid.UUID, id uint, approve bool, note string, mc *minio.Client, bucket string) (*model.Surat, error) {
 	row, err := s.suratRepo.Get(ctx, id)
 	if err != nil {
 		return nil, err
@@ -301,16 +678,26 @@ func (s *suratService) Decide(ctx context.Context, approver uuid.UUID, id uint,
 		return nil, errors.New("only pending can be decided")
 	}
 	if approve {
-		row.Status = model.SuratStatusApproved
-	} else {
-		row.Status = model.SuratStatusRejected
+		// Surat dengan penanda tangan ber-user_id disetujui oleh penanda tangan terakhir (Sign).
+		if _, current, err := s.signingSteps(ctx, row.ID); err != nil {
+			return nil, err
+		} else if current != nil {
+			return nil, errors.New("surat ini menunggu tanda tangan berurutan dan disetujui oleh penanda tangan terakhir")
+		}
 	}
 	row.ApprovalNote = note
 	if approver != uuid.Nil {
 		row.ApprovedBy = &approver
 	}
-	if err := s.suratRepo.Update(ctx, row); err != nil {
-		return nil, err
+	if approve {
+		if err := s.approve(ctx, row, mc, bucket); err != nil {
+			return nil, err
+		}
+	} else {
+		row.Status = model.SuratStatusRejected
+		if err := s.suratRepo.Update(ctx, row); err != nil {
+			return nil, err
+		}
 	}
 	if s.audit != nil && approver != uuid.Nil {
 		s.audit.Log(ctx, approver, "surat_decide", map[string]any{"surat_id": row.ID, "approve": approve})
@@ -321,6 +708,202 @@ func (s *suratService) Decide(ctx context.Context, approver uuid.UUID, id uint,
 	return row, nil
 }
 
+// approve menerbitkan persetujuan final: kode verifikasi, tanda tangan digital,
+// status APPROVED, lalu pengiriman email dan notifikasi tembusan.
+func (s *suratService) approve(ctx context.Context, row *model.Surat, mc *minio.Client, bucket string) error {
+	if err := s.stampVerification(ctx, row, mc, bucket); err != nil {
+		return err
+	}
+	if err := s.signFile(ctx, row, mc, bucket); err != nil {
+		return err
+	}
+	row.Status = model.SuratStatusApproved
+	if err := s.suratRepo.Update(ctx, row); err != nil {
+		return err
+	}
+	s.recordVersion(ctx, row, row.ApprovedBy, "surat disetujui")
+	s.queueDelivery(row, mc, bucket)
+	s.notifyTembusan(ctx, row)
+	return nil
+}
+
+// stampVerification memberi kode verifikasi pada surat lalu merender ulang PDF-nya
+// dengan QR code. Surat hasil upload tidak punya payload, jadi hanya kodenya yang tersimpan.
+func (s *suratService) stampVerification(ctx context.Context, row *model.Surat, mc *minio.Client, bucket string) error {
+	code, err := newVerifyCode()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	row.VerifyCode = &code
+	row.ApprovedAt = &now
+
+	payload, theme := decodeSuratPayload(row.MetaJSON)
+	if payload == nil {
+		return nil
+	}
+	payload.Meta.Number = row.Number
+	payload.Verification = &suratpdf.Verification{Code: code, URL: s.verifyURL(code)}
+	key, err := s.renderAndUpload(ctx, row, payload, theme, mc, bucket)
+	if err != nil {
+		return err
+	}
+	row.FileKey = key
+	row.MetaJSON = withSuratPayload(row.MetaJSON, payload)
+	return nil
+}
+
+// signFile menandatangani PDF final di MinIO (detached) dan menyimpan hash + tanda tangannya di surat.
+func (s *suratService) signFile(ctx context.Context, row *model.Surat, mc *minio.Client, bucket string) error {
+	if s.signer == nil {
+		return nil
+	}
+	if mc == nil || bucket == "" {
+		return fmt.Errorf("minio disabled or bucket missing")
+	}
+	if row.FileKey == "" {
+		return errors.New("surat has no file to sign")
+	}
+	obj, err := mc.GetObject(ctx, bucket, row.FileKey, minio.GetObjectOptions{})
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	content, err := io.ReadAll(obj)
+	if err != nil {
+		return fmt.Errorf("read surat file: %w", err)
+	}
+	sig := s.signer.Sign(content)
+	now := time.Now()
+	row.ContentHash = sig.ContentHash
+	row.Signature = sig.Value
+	row.SignKeyID = sig.KeyID
+	row.SignedAt = &now
+	return nil
+}
+
+// ValidateSignature mencocokkan file PDF yang diunggah dengan hash dan tanda tangan surat.
+func (s *suratService) ValidateSignature(ctx context.Context, id uint, content []byte) (*SuratSignatureCheck, error) {
+	if s.suratRepo == nil {
+		return nil, fmt.Errorf("surat repository not wired")
+	}
+	row, err := s.suratRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Signature == "" || row.ContentHash == "" {
+		return nil, errors.New("surat belum ditandatangani")
+	}
+	if s.signer == nil {
+		return nil, errors.New("keystore penandatangan tidak dikonfigurasi")
+	}
+	out := &SuratSignatureCheck{
+		SuratID:      row.ID,
+		Number:       row.Number,
+		Status:       row.Status,
+		ContentHash:  row.ContentHash,
+		UploadedHash: suratsign.Digest(content),
+		KeyID:        row.SignKeyID,
+		SignedAt:     row.SignedAt,
+	}
+	out.HashMatch = out.UploadedHash == row.ContentHash
+	if err := s.signer.VerifySignature(suratsign.Signature{KeyID: row.SignKeyID, ContentHash: row.ContentHash, Value: row.Signature}); err != nil {
+		out.Message = err.Error()
+	} else {
+		out.SignatureValid = true
+	}
+	out.Valid = out.HashMatch && out.SignatureValid
+	switch {
+	case out.Valid && row.Status == model.SuratStatusVoided:
+		out.Message = "dokumen asli, namun surat sudah dibatalkan"
+		if row.VoidReason != "" {
+			out.Message += ": " + row.VoidReason
+		}
+	case out.Valid:
+		out.Message = "dokumen asli dan tidak berubah sejak disetujui"
+	case !out.HashMatch && out.SignatureValid:
+		out.Message = "isi dokumen berbeda dengan versi yang disetujui"
+	}
+	return out, nil
+}
+
+// SigningKey mengembalikan kunci publik penandatangan aktif.
+func (s *suratService) SigningKey() (*SuratSigningKey, error) {
+	if s.signer == nil {
+		return nil, errors.New("keystore penandatangan tidak dikonfigurasi")
+	}
+	pub, err := s.signer.PublicKeyPEM()
+	if err != nil {
+		return nil, err
+	}
+	return &SuratSigningKey{KeyID: s.signer.KeyID(), Algorithm: suratsign.Algorithm, PublicKey: pub}, nil
+}
+
+func (s *suratService) verifyURL(code string) string {
+	if s.cfg == nil || strings.TrimSpace(s.cfg.VerifyBaseURL) == "" {
+		return ""
+	}
+	return strings.TrimRight(s.cfg.VerifyBaseURL, "/") + "/" + code
+}
+
+// verifyCodeAlphabet tanpa karakter yang mudah tertukar (0/O, 1/I/L).
+const verifyCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// newVerifyCode menghasilkan kode acak 8 karakter berformat "XXXX-XXXX".
+func newVerifyCode() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	out := make([]byte, 0, 9)
+	for i, b := range buf {
+		if i == 4 {
+			out = append(out, '-')
+		}
+		out = append(out, verifyCodeAlphabet[int(b)%len(verifyCodeAlphabet)])
+	}
+	return string(out), nil
+}
+
+// Verify mencari surat berdasarkan kode verifikasi publik.
+func (s *suratService) Verify(ctx context.Context, code string) (*SuratVerification, error) {
+	if s.suratRepo == nil {
+		return nil, fmt.Errorf("surat repository not wired")
+	}
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil, errors.New("code required")
+	}
+	row, err := s.suratRepo.GetByVerifyCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	out := &SuratVerification{
+		Code:       code,
+		Number:     row.Number,
+		Subject:    row.Subject,
+		Variant:    row.Variant,
+		Status:     row.Status,
+		ApprovedAt: row.ApprovedAt,
+	}
+	if row.Status == model.SuratStatusVoided {
+		out.Voided = true
+		out.VoidedAt = row.VoidedAt
+		out.VoidReason = row.VoidReason
+	}
+	if s.orgRepo != nil {
+		if org, err := s.orgRepo.GetByID(ctx, row.OrgID); err == nil && org != nil {
+			out.OrgName = org.Name
+		}
+	}
+	if s.userRepo != nil && row.ApprovedBy != nil {
+		if u, err := s.userRepo.GetByUUID(ctx, *row.ApprovedBy); err == nil && u != nil {
+			out.ApprovedBy = strings.TrimSpace(u.FirstName + " " + u.SecondName)
+		}
+	}
+	return out, nil
+}
+
 func (s *suratService) Get(ctx context.Context, id uint) (*model.Surat, error) {
 	if s.suratRepo == nil {
 		return nil, fmt.Errorf("surat repository not wired")
@@ -363,6 +946,23 @@ func (s *suratService) ListInbox(ctx context.Context, in InboxFilter) ([]model.S
 		}
 	}
 
+	if in.CC {
+		// Tembusan hanya terlihat setelah surat disetujui.
+		q := repository.ListSuratQuery{
+			Status:       model.SuratStatusApproved,
+			Page:         in.Page,
+			Size:         in.Size,
+			TembusanOnly: true,
+		}
+		if !isAdmin {
+			if len(in.OrgIDs) == 0 {
+				return []model.Surat{}, 0, nil
+			}
+			q.TembusanOrgIDs = in.OrgIDs
+		}
+		return s.List(ctx, q)
+	}
+
 	q := repository.ListSuratQuery{
 		Status:    in.Status,
 		Page:      in.Page,
@@ -395,6 +995,16 @@ func (s *suratService) Revise(ctx context.Context, approver uuid.UUID, id uint,
 	if s.suratRepo == nil {
 		return nil, fmt.Errorf("surat repository not wired")
 	}
+	var out *model.Surat
+	err := s.suratRepo.WithLock(ctx, id, func() error {
+		row, err := s.revise(ctx, approver, id, note)
+		out = row
+		return err
+	})
+	return out, err
+}
+
+func (s *suratService) revise(ctx context.Context, approver uuid.UUID, id uint, note string) (*model.Surat, error) {
 	row, err := s.suratRepo.Get(ctx, id)
 	if err != nil {
 		return nil, err
@@ -419,7 +1029,147 @@ func (s *suratService) Revise(ctx context.Context, approver uuid.UUID, id uint,
 	return row, nil
 }
 
-// ListArchive returns approved/rejected surat for archive page
+// Resubmit menerima perbaikan surat REVISION, merender ulang/mengganti PDF-nya,
+// mengembalikan status ke PENDING dan memberi tahu reviewer yang meminta revisi.
+func (s *suratService) Resubmit(ctx context.Context, in *ResubmitSuratInput, mc *minio.Client, bucket string) (*model.Surat, error) {
+	if in == nil {
+		return nil, errors.New("input nil")
+	}
+	if s.suratRepo == nil {
+		return nil, fmt.Errorf("surat repository not wired")
+	}
+	var out *model.Surat
+	err := s.suratRepo.WithLock(ctx, in.ID, func() error {
+		row, err := s.resubmit(ctx, in, mc, bucket)
+		out = row
+		return err
+	})
+	return out, err
+}
+
+func (s *suratService) resubmit(ctx context.Context, in *ResubmitSuratInput, mc *minio.Client, bucket string) (*model.Surat, error) {
+	row, err := s.suratRepo.Get(ctx, in.ID)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != model.SuratStatusRevision {
+		return nil, errors.New("only surat in revision can be resubmitted")
+	}
+	switch {
+	case in.Payload != nil:
+		p := in.Payload
+		// Nomor dan varian sudah terbit, tidak ikut berubah karena revisi.
+		p.Meta.Number = row.Number
+		p.Variant = suratpdf.Variant(strings.ToLower(row.Variant))
+		p.Verification = nil
+		if err := s.validateSigners(ctx, p); err != nil {
+			return nil, err
+		}
+		if err := validateTheme(in.Theme); err != nil {
+			return nil, err
+		}
+	case in.File != nil:
+		if mc == nil || bucket == "" {
+			return nil, fmt.Errorf("minio disabled or bucket missing")
+		}
+	default:
+		return nil, errors.New("payload or file required")
+	}
+	// Tanda tangan yang sudah terkumpul tidak berlaku untuk isi hasil revisi. Direset
+	// setelah validasi dan sebelum render agar TTD lama tidak ikut tercetak.
+	if err := s.resetSigning(ctx, row.ID); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case in.Payload != nil:
+		oldPayload, oldTheme := decodeSuratPayload(row.MetaJSON)
+		p := in.Payload
+		if oldPayload != nil {
+			if p.Header == nil {
+				p.Header = oldPayload.Header
+			}
+			if p.CreatedAt.IsZero() {
+				p.CreatedAt = oldPayload.CreatedAt
+			}
+		}
+		if p.CreatedAt.IsZero() {
+			p.CreatedAt = time.Now()
+		}
+		theme := in.Theme
+		if theme == nil {
+			theme = oldTheme
+		}
+		key, err := s.renderAndUpload(ctx, row, p, theme, mc, bucket)
+		if err != nil {
+			return nil, err
+		}
+		row.FileKey = key
+		row.Subject = p.Meta.Subject
+		row.ToRole = p.Meta.ToRole
+		row.ToName = p.Meta.ToName
+		row.ToPlace = p.Meta.ToPlace
+		row.ToCity = p.Meta.ToCity
+		meta := map[string]any{}
+		if len(row.MetaJSON) > 0 {
+			_ = json.Unmarshal(row.MetaJSON, &meta)
+		}
+		meta["place_and_date"] = p.Meta.PlaceAndDate
+		meta["footer"] = p.Footer
+		meta["created_at"] = p.CreatedAt
+		meta["payload"] = p
+		meta["theme"] = theme
+		row.MetaJSON, _ = json.Marshal(meta)
+		if err := s.syncTembusan(ctx, row.ID, p.Tembusan); err != nil {
+			fmt.Printf("[SURAT] sinkron tembusan surat %d gagal: %v\n", row.ID, err)
+		}
+	case in.File != nil:
+		buf := new(bytes.Buffer)
+		size, err := buf.ReadFrom(in.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		key, err := storage.UploadToMinio(ctx, mc, bucket, fmt.Sprintf("surat/%s.pdf", uuid.New().String()), bytes.NewReader(buf.Bytes()), size, "application/pdf")
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload file: %w", err)
+		}
+		row.FileKey = key
+		// PDF pengganti tidak lagi sesuai payload lama, jadi payload dilepas agar
+		// persetujuan berikutnya tidak merender ulang isi yang usang.
+		row.MetaJSON = withSuratPayload(row.MetaJSON, nil)
+	}
+
+	reviewer := row.ApprovedBy
+	row.Status = model.SuratStatusPending
+	row.ApprovedBy = nil
+	var submittedBy *uuid.UUID
+	if in.UserID != uuid.Nil {
+		submittedBy = &in.UserID
+		row.SubmittedBy = submittedBy
+	}
+	if err := s.suratRepo.Update(ctx, row); err != nil {
+		return nil, err
+	}
+
+	reason := strings.TrimSpace(in.Reason)
+	if reason == "" {
+		reason = "revisi dikirim ulang"
+	}
+	s.recordVersion(ctx, row, submittedBy, reason)
+	if err := s.startSigning(ctx, row); err != nil {
+		fmt.Printf("[SURAT] susun tanda tangan surat %d gagal: %v\n", row.ID, err)
+	}
+	if s.audit != nil && in.UserID != uuid.Nil {
+		s.audit.Log(ctx, in.UserID, "surat_resubmit", map[string]any{"surat_id": row.ID, "org_id": row.OrgID, "reason": reason})
+	}
+	if s.notify != nil && reviewer != nil {
+		_ = s.notify.Push(ctx, *reviewer, "Revisi surat dikirim ulang", fmt.Sprintf("Surat %s siap ditinjau kembali", row.Subject), map[string]any{"surat_id": row.ID})
+	}
+	return row, nil
+}
+
+// ListArchive returns surat of the given orgs for archive page; voided surat keep
+// their VOIDED status and void_reason so the archive can flag them.
 func (s *suratService) ListArchive(ctx context.Context, orgIDs []uuid.UUID, page, size int) ([]model.Surat, int64, error) {
 	if s.suratRepo == nil {
 		return nil, 0, fmt.Errorf("surat repository not wired")
@@ -431,3 +1181,43 @@ func (s *suratService) ListArchive(ctx context.Context, orgIDs []uuid.UUID, page
 	}
 	return s.suratRepo.List(ctx, q)
 }
+
+// ListNumberRegister returns the issued surat numbers of an organization.
+func (s *suratService) ListNumberRegister(ctx context.Context, q repository.ListSuratNumberQuery) ([]model.SuratNumber, int64, error) {
+	if s.numberRepo == nil {
+		return nil, 0, fmt.Errorf("surat number repository not wired")
+	}
+	q.Variant = strings.ToUpper(strings.TrimSpace(q.Variant))
+	return s.numberRepo.List(ctx, q)
+}
+
+// ExportNumberRegister generates a CSV of the number register (buku register nomor surat).
+func (s *suratService) ExportNumberRegister(ctx context.Context, q repository.ListSuratNumberQuery) ([]byte, string, error) {
+	q.Page, q.Size = 0, 0
+	rows, _, err := s.ListNumberRegister(ctx, q)
+	if err != nil {
+		return nil, "", err
+	}
+
+	b := &bytes.Buffer{}
+	w := csv.NewWriter(b)
+	_ = w.Write([]string{"Tahun", "Varian", "No Urut", "Nomor Surat", "Perihal", "Surat ID", "Diterbitkan"})
+	for _, r := range rows {
+		_ = w.Write([]string{
+			strconv.Itoa(r.Year),
+			r.Variant,
+			strconv.Itoa(r.Seq),
+			r.Number,
+			r.Subject,
+			strconv.FormatUint(uint64(r.SuratID), 10),
+			r.CreatedAt.Format("2006-01-02 15:04"),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, "", err
+	}
+
+	filename := fmt.Sprintf("register_surat_%s.csv", time.Now().Format("20060102"))
+	return b.Bytes(), filename, nil
+}
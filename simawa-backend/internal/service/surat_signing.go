@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/util/suratpdf"
+)
+
+// SuratSigningStatus adalah ringkasan alur tanda tangan berurutan sebuah surat.
+type SuratSigningStatus struct {
+	Steps       []model.SuratSignature `json:"steps"`
+	CurrentStep int                    `json:"current_step"` // 0 bila tidak ada langkah berjalan
+	Signed      int                    `json:"signed"`
+	Total       int                    `json:"total"`
+}
+
+// validateSigners memastikan user_id penanda tangan di payload valid dan terdaftar.
+func (s *suratService) validateSigners(ctx context.Context, p *suratpdf.Payload) error {
+	for i, sg := range p.Signs {
+		raw := strings.TrimSpace(sg.UserID)
+		if raw == "" {
+			continue
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("user_id penanda tangan ke-%d tidak valid", i+1)
+		}
+		if s.userRepo != nil {
+			if u, err := s.userRepo.GetByUUID(ctx, id); err != nil || u == nil {
+				return fmt.Errorf("penanda tangan ke-%d tidak ditemukan", i+1)
+			}
+		}
+		p.Signs[i].UserID = id.String()
+	}
+	return nil
+}
+
+// startSigning menyusun ulang langkah tanda tangan saat surat masuk PENDING
+// (Create, Submit, Resubmit) dan memberi tahu penanda tangan pertama.
+// Surat tanpa penanda tangan ber-user_id tetap memakai Decide biasa.
+func (s *suratService) startSigning(ctx context.Context, row *model.Surat) error {
+	if s.signatureRepo == nil {
+		return nil
+	}
+	var steps []model.SuratSignature
+	if payload, _ := decodeSuratPayload(row.MetaJSON); payload != nil {
+		steps = payloadSigners(payload)
+	} else {
+		// PDF hasil upload tidak punya payload: ulangi penanda tangan sebelumnya.
+		old, err := s.signatureRepo.ListBySurat(ctx, row.ID)
+		if err != nil {
+			return err
+		}
+		for _, o := range old {
+			steps = append(steps, model.SuratSignature{UserID: o.UserID, Name: o.Name, Role: o.Role})
+		}
+	}
+	numberSteps(row.ID, steps)
+	if err := s.signatureRepo.Replace(ctx, row.ID, steps); err != nil {
+		return err
+	}
+	if len(steps) > 0 {
+		s.notifySigner(ctx, row, steps[0], "Surat menunggu tanda tangan")
+	}
+	return nil
+}
+
+// payloadSigners mengambil penanda tangan ber-user_id dari payload sesuai urutan kolom TTD.
+func payloadSigners(p *suratpdf.Payload) []model.SuratSignature {
+	var out []model.SuratSignature
+	for _, sg := range p.Signs {
+		id, err := uuid.Parse(sg.UserID)
+		if err != nil {
+			continue
+		}
+		out = append(out, model.SuratSignature{UserID: id, Name: sg.Name, Role: sg.Role})
+	}
+	return out
+}
+
+// numberSteps memberi nomor langkah 1..n sesuai urutan dan mengembalikannya ke PENDING.
+func numberSteps(suratID uint, steps []model.SuratSignature) {
+	for i := range steps {
+		steps[i].SuratID = suratID
+		steps[i].Step = i + 1
+		steps[i].Status = model.SuratSignPending
+	}
+}
+
+// pendingStep mengembalikan langkah PENDING pertama setelah langkah after
+// (0 untuk langkah yang sedang berjalan); steps terurut menurut Step.
+func pendingStep(steps []model.SuratSignature, after int) *model.SuratSignature {
+	for i := range steps {
+		if steps[i].Step > after && steps[i].Status == model.SuratSignPending {
+			return &steps[i]
+		}
+	}
+	return nil
+}
+
+// resetSigning mengembalikan semua langkah ke PENDING sehingga TTD yang sudah
+// terkumpul tidak lagi dicetak.
+func (s *suratService) resetSigning(ctx context.Context, suratID uint) error {
+	steps, _, err := s.signingSteps(ctx, suratID)
+	if err != nil {
+		return err
+	}
+	for i := range steps {
+		if steps[i].Status == model.SuratSignPending {
+			continue
+		}
+		steps[i].Status = model.SuratSignPending
+		steps[i].SignedAt = nil
+		if err := s.signatureRepo.Update(ctx, &steps[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// signingSteps mengembalikan langkah tanda tangan dan langkah yang sedang berjalan.
+func (s *suratService) signingSteps(ctx context.Context, suratID uint) ([]model.SuratSignature, *model.SuratSignature, error) {
+	if s.signatureRepo == nil {
+		return nil, nil, nil
+	}
+	steps, err := s.signatureRepo.ListBySurat(ctx, suratID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return steps, pendingStep(steps, 0), nil
+}
+
+// Sign mencatat tanda tangan (atau penolakan) penanda tangan yang sedang mendapat giliran.
+// PDF dirender ulang setiap tanda tangan terkumpul; langkah terakhir menyetujui surat.
+// Penolakan mengembalikan surat ke REVISION dan alur dimulai ulang saat Resubmit.
+func (s *suratService) Sign(ctx context.Context, userID uuid.UUID, id uint, approve bool, note string, mc *minio.Client, bucket string) (*model.Surat, error) {
+	if s.suratRepo == nil || s.signatureRepo == nil {
+		return nil, fmt.Errorf("surat repository not wired")
+	}
+	// Status dan langkah dibaca ulang di dalam kunci agar dua permintaan bersamaan
+	// tidak sama-sama menjadi langkah terakhir dan menyetujui surat dua kali.
+	var out *model.Surat
+	err := s.suratRepo.WithLock(ctx, id, func() error {
+		row, err := s.sign(ctx, userID, id, approve, note, mc, bucket)
+		out = row
+		return err
+	})
+	return out, err
+}
+
+func (s *suratService) sign(ctx context.Context, userID uuid.UUID, id uint, approve bool, note string, mc *minio.Client, bucket string) (*model.Surat, error) {
+	row, err := s.suratRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != model.SuratStatusPending {
+		return nil, errors.New("hanya surat pending yang bisa ditandatangani")
+	}
+	steps, current, err := s.signingSteps(ctx, row.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, errors.New("surat ini tidak memakai tanda tangan berurutan")
+	}
+	if current.UserID != userID {
+		return nil, errors.New("belum giliran Anda menandatangani surat ini")
+	}
+
+	now := time.Now()
+	current.Note = note
+	if !approve {
+		current.Status = model.SuratSignRejected
+		if err := s.signatureRepo.Update(ctx, current); err != nil {
+			return nil, err
+		}
+		row.Status = model.SuratStatusRevision
+		row.ApprovalNote = note
+		row.ApprovedBy = &userID
+		if err := s.suratRepo.Update(ctx, row); err != nil {
+			return nil, err
+		}
+		if s.audit != nil {
+			s.audit.Log(ctx, userID, "surat_sign", map[string]any{"surat_id": row.ID, "step": current.Step, "approve": false})
+		}
+		if s.notify != nil && row.CreatedBy != nil {
+			_ = s.notify.Push(ctx, *row.CreatedBy, "Surat perlu revisi", fmt.Sprintf("%s menolak menandatangani: %s", current.Name, note), map[string]any{"surat_id": row.ID})
+		}
+		return row, nil
+	}
+
+	current.Status = model.SuratSignSigned
+	current.SignedAt = &now
+	if err := s.signatureRepo.Update(ctx, current); err != nil {
+		return nil, err
+	}
+	if s.audit != nil {
+		s.audit.Log(ctx, userID, "surat_sign", map[string]any{"surat_id": row.ID, "step": current.Step, "approve": true})
+	}
+
+	next := pendingStep(steps, current.Step)
+	if next == nil {
+		// Penanda tangan terakhir: surat resmi disetujui (kode verifikasi + tanda tangan digital).
+		row.ApprovalNote = note
+		row.ApprovedBy = &userID
+		if err := s.approve(ctx, row, mc, bucket); err != nil {
+			return nil, err
+		}
+		if s.notify != nil && row.CreatedBy != nil {
+			_ = s.notify.Push(ctx, *row.CreatedBy, "Status surat diperbarui", fmt.Sprintf("Surat %s", strings.ToLower(row.Status)), map[string]any{"surat_id": row.ID})
+		}
+		return row, nil
+	}
+
+	if err := s.rerender(ctx, row, &userID, fmt.Sprintf("ditandatangani %s", current.Name), mc, bucket); err != nil {
+		return nil, err
+	}
+	s.notifySigner(ctx, row, *next, "Surat menunggu tanda tangan")
+	if s.notify != nil && row.CreatedBy != nil {
+		_ = s.notify.Push(ctx, *row.CreatedBy, "Surat ditandatangani", fmt.Sprintf("%s (%d/%d)", current.Name, current.Step, len(steps)), map[string]any{"surat_id": row.ID})
+	}
+	return row, nil
+}
+
+// IsSigner true bila user terdaftar sebagai penanda tangan surat.
+func (s *suratService) IsSigner(ctx context.Context, suratID uint, userID uuid.UUID) bool {
+	steps, _, err := s.signingSteps(ctx, suratID)
+	if err != nil {
+		return false
+	}
+	for _, st := range steps {
+		if st.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// SigningStatus mengembalikan linimasa tanda tangan surat.
+func (s *suratService) SigningStatus(ctx context.Context, id uint) (*SuratSigningStatus, error) {
+	steps, current, err := s.signingSteps(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out := &SuratSigningStatus{Steps: steps, Total: len(steps)}
+	if out.Steps == nil {
+		out.Steps = []model.SuratSignature{}
+	}
+	for _, st := range steps {
+		if st.Status == model.SuratSignSigned {
+			out.Signed++
+		}
+	}
+	if current != nil {
+		out.CurrentStep = current.Step
+	}
+	return out, nil
+}
+
+// ListMySignatures mengembalikan surat yang sedang menunggu tanda tangan user.
+func (s *suratService) ListMySignatures(ctx context.Context, userID uuid.UUID) ([]model.SuratSignature, error) {
+	if s.signatureRepo == nil {
+		return nil, fmt.Errorf("surat signature repository not wired")
+	}
+	return s.signatureRepo.ListCurrentForUser(ctx, userID)
+}
+
+// RemindSigner mengirim pengingat manual ke penanda tangan yang sedang mendapat giliran.
+func (s *suratService) RemindSigner(ctx context.Context, userID uuid.UUID, id uint) (*model.SuratSignature, error) {
+	row, err := s.suratRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != model.SuratStatusPending {
+		return nil, errors.New("surat tidak sedang menunggu tanda tangan")
+	}
+	_, current, err := s.signingSteps(ctx, row.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, errors.New("surat ini tidak memakai tanda tangan berurutan")
+	}
+	if err := s.remind(ctx, row, current); err != nil {
+		return nil, err
+	}
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_sign_remind", map[string]any{"surat_id": row.ID, "step": current.Step})
+	}
+	return current, nil
+}
+
+// RemindPendingSigners mengingatkan penanda tangan yang belum bertindak sejak olderThan.
+// Dipanggil berkala oleh server.
+func (s *suratService) RemindPendingSigners(ctx context.Context, olderThan time.Duration) int {
+	if s.signatureRepo == nil {
+		return 0
+	}
+	rows, err := s.signatureRepo.ListStale(ctx, time.Now().Add(-olderThan))
+	if err != nil {
+		fmt.Printf("[SURAT] daftar pengingat tanda tangan gagal: %v\n", err)
+		return 0
+	}
+	n := 0
+	for i := range rows {
+		if rows[i].Surat == nil {
+			continue
+		}
+		if err := s.remind(ctx, rows[i].Surat, &rows[i]); err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *suratService) remind(ctx context.Context, row *model.Surat, step *model.SuratSignature) error {
+	now := time.Now()
+	step.RemindedAt = &now
+	step.Reminders++
+	if err := s.signatureRepo.Update(ctx, step); err != nil {
+		return err
+	}
+	s.notifySigner(ctx, row, *step, "Pengingat tanda tangan surat")
+	return nil
+}
+
+func (s *suratService) notifySigner(ctx context.Context, row *model.Surat, step model.SuratSignature, title string) {
+	if s.notify == nil {
+		return
+	}
+	body := fmt.Sprintf("%s sebagai %s", row.Subject, step.Role)
+	if row.Number != "" {
+		body = fmt.Sprintf("%s (%s) sebagai %s", row.Subject, row.Number, step.Role)
+	}
+	_ = s.notify.Push(ctx, step.UserID, title, body, map[string]any{"surat_id": row.ID, "step": step.Step})
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/util/suratpdf"
+)
+
+// Urutan langkah mengikuti urutan kolom TTD di payload; penanda tangan tanpa
+// user_id hanya dicetak dan tidak mendapat langkah.
+func TestPayloadSignersOrder(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	p := &suratpdf.Payload{Signs: []suratpdf.Signer{
+		{Name: "Ketua", UserID: a.String()},
+		{Name: "Pembina"},
+		{Name: "Sekretaris", UserID: "bukan-uuid"},
+		{Name: "Bendahara", UserID: b.String()},
+	}}
+	steps := payloadSigners(p)
+	numberSteps(7, steps)
+	want := []struct {
+		user uuid.UUID
+		name string
+	}{{a, "Ketua"}, {b, "Bendahara"}}
+	if len(steps) != len(want) {
+		t.Fatalf("got %d langkah, want %d", len(steps), len(want))
+	}
+	for i, w := range want {
+		st := steps[i]
+		if st.UserID != w.user || st.Name != w.name || st.Step != i+1 || st.SuratID != 7 || st.Status != model.SuratSignPending {
+			t.Errorf("langkah #%d = %+v, want %s step %d PENDING", i, st, w.name, i+1)
+		}
+	}
+}
+
+func TestPendingStep(t *testing.T) {
+	step := func(n int, status string) model.SuratSignature {
+		return model.SuratSignature{Step: n, Status: status}
+	}
+	tests := []struct {
+		name  string
+		steps []model.SuratSignature
+		after int
+		want  int // 0 berarti tidak ada
+	}{
+		{"belum ada yang tanda tangan", []model.SuratSignature{step(1, model.SuratSignPending), step(2, model.SuratSignPending)}, 0, 1},
+		{"giliran kedua", []model.SuratSignature{step(1, model.SuratSignSigned), step(2, model.SuratSignPending), step(3, model.SuratSignPending)}, 0, 2},
+		{"berikutnya setelah langkah kedua", []model.SuratSignature{step(1, model.SuratSignSigned), step(2, model.SuratSignPending), step(3, model.SuratSignPending)}, 2, 3},
+		{"langkah terakhir", []model.SuratSignature{step(1, model.SuratSignSigned), step(2, model.SuratSignPending)}, 2, 0},
+		{"semua sudah tanda tangan", []model.SuratSignature{step(1, model.SuratSignSigned), step(2, model.SuratSignSigned)}, 0, 0},
+		{"ditolak menghentikan alur", []model.SuratSignature{step(1, model.SuratSignRejected)}, 0, 0},
+		{"tanpa langkah", nil, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pendingStep(tt.steps, tt.after)
+			switch {
+			case tt.want == 0 && got != nil:
+				t.Fatalf("pendingStep = langkah %d, want tidak ada", got.Step)
+			case tt.want != 0 && (got == nil || got.Step != tt.want):
+				t.Fatalf("pendingStep = %+v, want langkah %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitialSuratStatus(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // kosong berarti harus error
+	}{
+		{"", model.SuratStatusPending},
+		{" draft ", model.SuratStatusDraft},
+		{"PENDING", model.SuratStatusPending},
+		{"APPROVED", ""},
+		{"revision", ""},
+		{"VOID", ""},
+	}
+	for _, tt := range tests {
+		got, err := initialSuratStatus(tt.in)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("initialSuratStatus(%q) = %q, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("initialSuratStatus(%q) = (%q, %v), want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
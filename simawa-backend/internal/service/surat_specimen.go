@@ -0,0 +1,215 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/util/storage"
+	"simawa-backend/internal/util/suratpdf"
+)
+
+// MaxSpecimenSize batas ukuran gambar spesimen TTD/cap.
+const MaxSpecimenSize = 2 * 1024 * 1024
+
+// SuratSpecimenUpload berisi gambar spesimen TTD (pemilik user) atau cap (pemilik organisasi).
+type SuratSpecimenUpload struct {
+	Kind       string
+	OwnerID    uuid.UUID
+	Data       []byte
+	UploadedBy uuid.UUID
+}
+
+// SaveSpecimen menyimpan atau mengganti spesimen milik user/organisasi.
+func (s *suratService) SaveSpecimen(ctx context.Context, in *SuratSpecimenUpload, mc *minio.Client, bucket string) (*model.SuratSpecimen, error) {
+	if s.specimenRepo == nil {
+		return nil, fmt.Errorf("surat specimen repository not wired")
+	}
+	if in == nil || in.OwnerID == uuid.Nil {
+		return nil, errors.New("pemilik spesimen wajib diisi")
+	}
+	if mc == nil || bucket == "" {
+		return nil, fmt.Errorf("minio disabled or bucket missing")
+	}
+	if len(in.Data) == 0 || len(in.Data) > MaxSpecimenSize {
+		return nil, errors.New("ukuran gambar spesimen maksimal 2MB")
+	}
+	contentType := http.DetectContentType(in.Data)
+	ext := ""
+	switch contentType {
+	case "image/png":
+		ext = ".png"
+	case "image/jpeg":
+		ext = ".jpg"
+	default:
+		return nil, errors.New("spesimen harus berupa gambar PNG atau JPEG")
+	}
+
+	m, err := s.getSpecimen(ctx, in.Kind, in.OwnerID)
+	if err != nil && !errors.Is(err, errSpecimenNotFound) {
+		return nil, err
+	}
+	if m == nil {
+		m = &model.SuratSpecimen{Kind: in.Kind}
+		owner := in.OwnerID
+		if in.Kind == model.SuratSpecimenSignature {
+			m.UserID = &owner
+		} else {
+			m.OrgID = &owner
+		}
+	}
+	oldKey := m.FileKey
+
+	key := fmt.Sprintf("private/specimen/%s/%s/%s%s", m.Kind, in.OwnerID, uuid.New(), ext)
+	if _, err := storage.UploadToMinio(ctx, mc, bucket, key, bytes.NewReader(in.Data), int64(len(in.Data)), contentType); err != nil {
+		return nil, err
+	}
+	m.FileKey = key
+	m.ContentType = contentType
+	m.Size = int64(len(in.Data))
+	m.UploadedBy = in.UploadedBy
+	if err := s.specimenRepo.Save(ctx, m); err != nil {
+		_ = storage.DeleteFromMinio(ctx, mc, bucket, key)
+		return nil, err
+	}
+	if oldKey != "" {
+		_ = storage.DeleteFromMinio(ctx, mc, bucket, oldKey)
+	}
+	if s.audit != nil && in.UploadedBy != uuid.Nil {
+		s.audit.Log(ctx, in.UploadedBy, "surat_specimen_save", map[string]any{"kind": m.Kind, "owner_id": in.OwnerID})
+	}
+	return m, nil
+}
+
+func (s *suratService) GetSpecimen(ctx context.Context, kind string, ownerID uuid.UUID) (*model.SuratSpecimen, error) {
+	if s.specimenRepo == nil {
+		return nil, fmt.Errorf("surat specimen repository not wired")
+	}
+	return s.getSpecimen(ctx, kind, ownerID)
+}
+
+func (s *suratService) DeleteSpecimen(ctx context.Context, userID uuid.UUID, kind string, ownerID uuid.UUID, mc *minio.Client, bucket string) error {
+	m, err := s.GetSpecimen(ctx, kind, ownerID)
+	if err != nil {
+		return err
+	}
+	if err := s.specimenRepo.Delete(ctx, m.ID); err != nil {
+		return err
+	}
+	_ = storage.DeleteFromMinio(ctx, mc, bucket, m.FileKey)
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_specimen_delete", map[string]any{"kind": kind, "owner_id": ownerID})
+	}
+	return nil
+}
+
+var errSpecimenNotFound = errors.New("spesimen belum diunggah")
+
+func (s *suratService) getSpecimen(ctx context.Context, kind string, ownerID uuid.UUID) (*model.SuratSpecimen, error) {
+	var (
+		m   *model.SuratSpecimen
+		err error
+	)
+	switch kind {
+	case model.SuratSpecimenSignature:
+		m, err = s.specimenRepo.GetSignature(ctx, ownerID)
+	case model.SuratSpecimenStamp:
+		m, err = s.specimenRepo.GetStamp(ctx, ownerID)
+	default:
+		return nil, errors.New("jenis spesimen tidak dikenal")
+	}
+	if err != nil {
+		return nil, errSpecimenNotFound
+	}
+	return m, nil
+}
+
+// stripSignImages membuang gambar TTD/cap yang dikirim klien. Gambar hanya boleh
+// berasal dari pustaka spesimen (lihat withSpecimens).
+func stripSignImages(p *suratpdf.Payload) {
+	if len(p.Signs) == 0 {
+		return
+	}
+	signs := make([]suratpdf.Signer, len(p.Signs))
+	for i, sg := range p.Signs {
+		sg.TTD = ""
+		sg.Stamp = ""
+		signs[i] = sg
+	}
+	p.Signs = signs
+}
+
+// approvedSigners mengembalikan user yang sudah menyetujui surat secara pribadi:
+// penanda tangan berurutan yang sudah SIGNED, atau approver Decide.
+func (s *suratService) approvedSigners(ctx context.Context, row *model.Surat) map[uuid.UUID]bool {
+	out := map[uuid.UUID]bool{}
+	if row == nil {
+		return out
+	}
+	if row.ApprovedBy != nil && row.ApprovedAt != nil {
+		out[*row.ApprovedBy] = true
+	}
+	if s.signatureRepo != nil && row.ID != 0 {
+		steps, _ := s.signatureRepo.ListBySurat(ctx, row.ID)
+		for _, st := range steps {
+			if st.Status == model.SuratSignSigned {
+				out[st.UserID] = true
+			}
+		}
+	}
+	return out
+}
+
+// withSpecimens mengembalikan salinan payload untuk dirender: TTD penanda tangan
+// yang sudah menyetujui diambil dari spesimennya, beserta cap OrgID bila ia anggota
+// organisasi tersebut. Salinan ini tidak disimpan ke meta_json.
+func (s *suratService) withSpecimens(ctx context.Context, row *model.Surat, p suratpdf.Payload, mc *minio.Client, bucket string) suratpdf.Payload {
+	stripSignImages(&p)
+	if s.specimenRepo == nil || len(p.Signs) == 0 {
+		return p
+	}
+	approved := s.approvedSigners(ctx, row)
+	for i, sg := range p.Signs {
+		userID, err := uuid.Parse(sg.UserID)
+		if err != nil || !approved[userID] {
+			continue
+		}
+		if img, err := s.specimenImage(ctx, model.SuratSpecimenSignature, userID, mc, bucket); err == nil {
+			p.Signs[i].TTD = img
+		} else {
+			fmt.Printf("[SURAT] spesimen TTD user %s tidak tersedia: %v\n", userID, err)
+		}
+		orgID, err := uuid.Parse(sg.OrgID)
+		if err != nil {
+			continue
+		}
+		if s.memberRepo != nil {
+			if ok, _ := s.memberRepo.IsMember(ctx, orgID, userID); !ok {
+				continue
+			}
+		}
+		if img, err := s.specimenImage(ctx, model.SuratSpecimenStamp, orgID, mc, bucket); err == nil {
+			p.Signs[i].Stamp = img
+		}
+	}
+	return p
+}
+
+func (s *suratService) specimenImage(ctx context.Context, kind string, ownerID uuid.UUID, mc *minio.Client, bucket string) (string, error) {
+	m, err := s.getSpecimen(ctx, kind, ownerID)
+	if err != nil {
+		return "", err
+	}
+	data, err := s.readObject(ctx, mc, bucket, m.FileKey)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/util/suratpdf"
+)
+
+// resolveTembusan memvalidasi organisasi tembusan (unik, bukan pengirim) lalu
+// menambahkan namanya ke payload.Tembusan agar ikut tercetak di PDF.
+func (s *suratService) resolveTembusan(ctx context.Context, senderOrg uuid.UUID, ids []uuid.UUID, p *suratpdf.Payload) ([]model.Organization, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if s.orgRepo == nil {
+		return nil, fmt.Errorf("organization repository not wired")
+	}
+	seen := map[uuid.UUID]struct{}{}
+	orgs := make([]model.Organization, 0, len(ids))
+	for _, id := range ids {
+		if id == uuid.Nil || id == senderOrg {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		org, err := s.orgRepo.GetByID(ctx, id)
+		if err != nil || org == nil {
+			return nil, fmt.Errorf("organisasi tembusan %s tidak ditemukan", id)
+		}
+		orgs = append(orgs, *org)
+	}
+	if p != nil {
+		for _, org := range orgs {
+			if !containsFold(p.Tembusan, org.Name) {
+				p.Tembusan = append(p.Tembusan, org.Name)
+			}
+		}
+	}
+	return orgs, nil
+}
+
+func (s *suratService) saveTembusan(ctx context.Context, suratID uint, orgs []model.Organization) error {
+	if s.tembusanRepo == nil {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(orgs))
+	for _, o := range orgs {
+		ids = append(ids, o.ID)
+	}
+	return s.tembusanRepo.Replace(ctx, suratID, ids)
+}
+
+// SetTembusan mengganti organisasi tembusan surat draft/revisi dan merender ulang PDF-nya.
+// Nama organisasi tembusan lama dihapus dari daftar tembusan cetak; entri teks bebas tetap.
+func (s *suratService) SetTembusan(ctx context.Context, userID uuid.UUID, id uint, orgIDs []uuid.UUID, mc *minio.Client, bucket string) ([]model.SuratTembusan, error) {
+	if s.suratRepo == nil || s.tembusanRepo == nil {
+		return nil, fmt.Errorf("surat repository not wired")
+	}
+	row, err := s.suratRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != model.SuratStatusDraft && row.Status != model.SuratStatusRevision {
+		return nil, errors.New("tembusan hanya bisa diubah pada surat draft atau revisi")
+	}
+	old, err := s.tembusanRepo.ListBySurat(ctx, row.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, _ := decodeSuratPayload(row.MetaJSON)
+	if payload != nil {
+		kept := make([]string, 0, len(payload.Tembusan))
+		for _, t := range payload.Tembusan {
+			linked := false
+			for _, o := range old {
+				if o.Org != nil && strings.EqualFold(strings.TrimSpace(t), strings.TrimSpace(o.Org.Name)) {
+					linked = true
+					break
+				}
+			}
+			if !linked {
+				kept = append(kept, t)
+			}
+		}
+		payload.Tembusan = kept
+	}
+	orgs, err := s.resolveTembusan(ctx, row.OrgID, orgIDs, payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.saveTembusan(ctx, row.ID, orgs); err != nil {
+		return nil, err
+	}
+
+	var by *uuid.UUID
+	if userID != uuid.Nil {
+		by = &userID
+	}
+	if payload != nil {
+		row.MetaJSON = withSuratPayload(row.MetaJSON, payload)
+		if err := s.rerender(ctx, row, by, "tembusan diubah", mc, bucket); err != nil {
+			return nil, err
+		}
+	}
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_tembusan_set", map[string]any{"surat_id": row.ID, "org_ids": orgIDs})
+	}
+	return s.tembusanRepo.ListBySurat(ctx, row.ID)
+}
+
+// syncTembusan melepas organisasi tembusan yang namanya sudah dihapus dari daftar
+// tembusan payload (mis. saat revisi dikirim ulang), agar akses CC ikut berubah.
+func (s *suratService) syncTembusan(ctx context.Context, suratID uint, printed []string) error {
+	if s.tembusanRepo == nil {
+		return nil
+	}
+	rows, err := s.tembusanRepo.ListBySurat(ctx, suratID)
+	if err != nil {
+		return err
+	}
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, t := range rows {
+		if t.Org != nil && containsFold(printed, t.Org.Name) {
+			ids = append(ids, t.OrgID)
+		}
+	}
+	if len(ids) == len(rows) {
+		return nil
+	}
+	return s.tembusanRepo.Replace(ctx, suratID, ids)
+}
+
+func (s *suratService) ListTembusan(ctx context.Context, suratID uint) ([]model.SuratTembusan, error) {
+	if s.tembusanRepo == nil {
+		return nil, fmt.Errorf("surat tembusan repository not wired")
+	}
+	return s.tembusanRepo.ListBySurat(ctx, suratID)
+}
+
+// IsTembusanRecipient true bila salah satu organisasi user menerima tembusan surat.
+func (s *suratService) IsTembusanRecipient(ctx context.Context, suratID uint, orgIDs []uuid.UUID) bool {
+	if s.tembusanRepo == nil {
+		return false
+	}
+	ok, err := s.tembusanRepo.IsRecipient(ctx, suratID, orgIDs)
+	return err == nil && ok
+}
+
+// notifyTembusan memberi tahu admin organisasi tembusan bahwa surat sudah disetujui.
+func (s *suratService) notifyTembusan(ctx context.Context, row *model.Surat) {
+	s.pushTembusan(ctx, row, "Tembusan surat", fmt.Sprintf("Tembusan surat %s: %s", row.Number, row.Subject))
+}
+
+// pushTembusan mengirim notifikasi ke admin setiap organisasi tembusan surat.
+func (s *suratService) pushTembusan(ctx context.Context, row *model.Surat, title, body string) {
+	if s.notify == nil || s.tembusanRepo == nil || s.memberRepo == nil {
+		return
+	}
+	rows, err := s.tembusanRepo.ListBySurat(ctx, row.ID)
+	if err != nil {
+		return
+	}
+	for _, t := range rows {
+		members, err := s.memberRepo.ListByOrg(ctx, t.OrgID)
+		if err != nil {
+			continue
+		}
+		for _, m := range members {
+			role := strings.ToUpper(strings.TrimSpace(m.Role))
+			if role != "ADMIN" && role != model.RoleOrgAdmin {
+				continue
+			}
+			_ = s.notify.Push(ctx, m.UserID, title, body, map[string]any{"surat_id": row.ID, "org_id": t.OrgID})
+		}
+	}
+}
+
+func containsFold(list []string, v string) bool {
+	v = strings.TrimSpace(v)
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), v) {
+			return true
+		}
+	}
+	return false
+}
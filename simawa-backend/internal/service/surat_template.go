@@ -0,0 +1,315 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/util/suratpdf"
+)
+
+// SuratTemplateInput adalah isi template yang dikirim saat membuat/mengubah template surat.
+type SuratTemplateInput struct {
+	OrgID       *uuid.UUID
+	Variant     string
+	Name        string
+	Description string
+	Subject     string
+	BodyOpening string
+	BodyContent []string
+	BodyClosing string
+	Footer      string
+	Signs       []suratpdf.Signer
+	Tembusan    []string
+	Theme       *suratpdf.Theme
+}
+
+var bulanIndonesia = [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// TanggalIndonesia memformat tanggal menjadi "2 Januari 2006".
+func TanggalIndonesia(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%d %s %d", t.Day(), bulanIndonesia[t.Month()-1], t.Year())
+}
+
+var suratTemplateFuncs = template.FuncMap{
+	"tanggal": TanggalIndonesia,
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+}
+
+func (s *suratService) ListTemplates(ctx context.Context, orgID *uuid.UUID, variant string) ([]model.SuratTemplate, error) {
+	if s.templateRepo == nil {
+		return nil, fmt.Errorf("surat template repository not wired")
+	}
+	return s.templateRepo.List(ctx, orgID, variant)
+}
+
+func (s *suratService) GetTemplate(ctx context.Context, id uint) (*model.SuratTemplate, error) {
+	if s.templateRepo == nil {
+		return nil, fmt.Errorf("surat template repository not wired")
+	}
+	return s.templateRepo.Get(ctx, id)
+}
+
+func (s *suratService) CreateTemplate(ctx context.Context, userID uuid.UUID, in *SuratTemplateInput) (*model.SuratTemplate, error) {
+	if s.templateRepo == nil {
+		return nil, fmt.Errorf("surat template repository not wired")
+	}
+	row := &model.SuratTemplate{OrgID: in.OrgID}
+	if userID != uuid.Nil {
+		row.CreatedBy = &userID
+	}
+	if err := fillSuratTemplate(row, in); err != nil {
+		return nil, err
+	}
+	if err := s.templateRepo.Create(ctx, row); err != nil {
+		return nil, err
+	}
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_template_create", map[string]any{"template_id": row.ID, "org_id": row.OrgID})
+	}
+	return row, nil
+}
+
+func (s *suratService) UpdateTemplate(ctx context.Context, userID uuid.UUID, id uint, in *SuratTemplateInput) (*model.SuratTemplate, error) {
+	if s.templateRepo == nil {
+		return nil, fmt.Errorf("surat template repository not wired")
+	}
+	row, err := s.templateRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := fillSuratTemplate(row, in); err != nil {
+		return nil, err
+	}
+	if err := s.templateRepo.Update(ctx, row); err != nil {
+		return nil, err
+	}
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_template_update", map[string]any{"template_id": row.ID, "org_id": row.OrgID})
+	}
+	return row, nil
+}
+
+func (s *suratService) DeleteTemplate(ctx context.Context, userID uuid.UUID, id uint) error {
+	if s.templateRepo == nil {
+		return fmt.Errorf("surat template repository not wired")
+	}
+	if err := s.templateRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_template_delete", map[string]any{"template_id": id})
+	}
+	return nil
+}
+
+// fillSuratTemplate memvalidasi input (termasuk sintaks placeholder) lalu menyalinnya ke row.
+func fillSuratTemplate(row *model.SuratTemplate, in *SuratTemplateInput) error {
+	if in == nil {
+		return errors.New("input nil")
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return errors.New("nama template wajib diisi")
+	}
+	variant := NormalizeSuratVariant(in.Variant)
+	if _, ok := suratVariantCodes[variant]; !ok {
+		return fmt.Errorf("variant %q tidak dikenal", in.Variant)
+	}
+	texts := append([]string{in.Subject, in.BodyOpening, in.BodyClosing, in.Footer}, in.BodyContent...)
+	texts = append(texts, in.Tembusan...)
+	for _, sg := range in.Signs {
+		texts = append(texts, sg.Name, sg.Role, sg.NIP)
+	}
+	for _, t := range texts {
+		if _, err := parseSuratTemplate(t); err != nil {
+			return err
+		}
+	}
+
+	row.Variant = variant
+	row.Name = name
+	row.Description = in.Description
+	row.Subject = in.Subject
+	row.BodyOpening = in.BodyOpening
+	row.BodyClosing = in.BodyClosing
+	row.Footer = in.Footer
+	row.BodyContent, _ = json.Marshal(in.BodyContent)
+	row.Signs, _ = json.Marshal(in.Signs)
+	row.Tembusan, _ = json.Marshal(in.Tembusan)
+	row.Theme = nil
+	if in.Theme != nil {
+		row.Theme, _ = json.Marshal(in.Theme)
+	}
+	return nil
+}
+
+func parseSuratTemplate(text string) (*template.Template, error) {
+	tpl, err := template.New("surat").Funcs(suratTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("template tidak valid: %w", err)
+	}
+	return tpl, nil
+}
+
+func executeSuratTemplate(text string, data map[string]any) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tpl, err := parseSuratTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("isi template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// applyTemplate mengisi payload dari template. Field yang sudah diisi di request
+// tidak ditimpa, sehingga template hanya menjadi nilai default.
+func (s *suratService) applyTemplate(ctx context.Context, in *CreateSuratInput, org *model.Organization) error {
+	if s.templateRepo == nil {
+		return fmt.Errorf("surat template repository not wired")
+	}
+	tpl, err := s.templateRepo.Get(ctx, *in.TemplateID)
+	if err != nil {
+		return fmt.Errorf("template surat tidak ditemukan")
+	}
+	if tpl.OrgID != nil && *tpl.OrgID != in.OrgID {
+		return errors.New("template milik organisasi lain")
+	}
+
+	data, err := s.templateData(ctx, in, org)
+	if err != nil {
+		return err
+	}
+	render := func(text string) (string, error) { return executeSuratTemplate(text, data) }
+	renderAll := func(list []string) ([]string, error) {
+		out := make([]string, 0, len(list))
+		for _, t := range list {
+			v, err := render(t)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	}
+
+	p := &in.Payload
+	p.Variant = suratpdf.Variant(strings.ToLower(tpl.Variant))
+	if strings.TrimSpace(p.Meta.Subject) == "" {
+		if p.Meta.Subject, err = render(tpl.Subject); err != nil {
+			return err
+		}
+	}
+	if p.BodyOpening == "" && len(p.BodyContent) == 0 && p.BodyClosing == "" && len(p.Body) == 0 {
+		if p.BodyOpening, err = render(tpl.BodyOpening); err != nil {
+			return err
+		}
+		var content []string
+		_ = json.Unmarshal(tpl.BodyContent, &content)
+		if p.BodyContent, err = renderAll(content); err != nil {
+			return err
+		}
+		if p.BodyClosing, err = render(tpl.BodyClosing); err != nil {
+			return err
+		}
+	}
+	if p.Footer == "" {
+		if p.Footer, err = render(tpl.Footer); err != nil {
+			return err
+		}
+	}
+	if len(p.Signs) == 0 {
+		var signs []suratpdf.Signer
+		_ = json.Unmarshal(tpl.Signs, &signs)
+		for i := range signs {
+			if signs[i].Name, err = render(signs[i].Name); err != nil {
+				return err
+			}
+			if signs[i].Role, err = render(signs[i].Role); err != nil {
+				return err
+			}
+			if signs[i].NIP, err = render(signs[i].NIP); err != nil {
+				return err
+			}
+		}
+		p.Signs = signs
+	}
+	if len(p.Tembusan) == 0 {
+		var tembusan []string
+		_ = json.Unmarshal(tpl.Tembusan, &tembusan)
+		if p.Tembusan, err = renderAll(tembusan); err != nil {
+			return err
+		}
+	}
+	if in.Theme == nil && len(tpl.Theme) > 0 && string(tpl.Theme) != "null" {
+		var th suratpdf.Theme
+		if err := json.Unmarshal(tpl.Theme, &th); err == nil {
+			in.Theme = &th
+		}
+	}
+	return nil
+}
+
+// templateData menyiapkan nilai placeholder: data penerima dari payload, organisasi,
+// kegiatan (jika activity_id diisi), lalu variabel bebas dari request.
+func (s *suratService) templateData(ctx context.Context, in *CreateSuratInput, org *model.Organization) (map[string]any, error) {
+	p := in.Payload
+	data := map[string]any{
+		"ToName":  p.Meta.ToName,
+		"ToRole":  p.Meta.ToRole,
+		"ToPlace": p.Meta.ToPlace,
+		"ToCity":  p.Meta.ToCity,
+		"Subject": p.Meta.Subject,
+		"OrgName": "",
+		"Date":    TanggalIndonesia(p.CreatedAt),
+		"Year":    p.CreatedAt.Year(),
+	}
+	if org != nil {
+		data["OrgName"] = org.Name
+	}
+	if in.ActivityID != nil {
+		if s.activityRepo == nil {
+			return nil, fmt.Errorf("activity repository not wired")
+		}
+		act, err := s.activityRepo.Get(ctx, *in.ActivityID)
+		if err != nil {
+			return nil, fmt.Errorf("kegiatan tidak ditemukan")
+		}
+		if act.OrgID != in.OrgID {
+			return nil, errors.New("kegiatan bukan milik organisasi ini")
+		}
+		data["Activity"] = map[string]any{
+			"Title":       act.Title,
+			"Description": act.Description,
+			"Location":    act.Location,
+			"Type":        act.Type,
+			"StartAt":     act.StartAt,
+			"EndAt":       act.EndAt,
+			"StartDate":   TanggalIndonesia(act.StartAt),
+			"EndDate":     TanggalIndonesia(act.EndAt),
+			"StartTime":   act.StartAt.Format("15:04"),
+			"EndTime":     act.EndAt.Format("15:04"),
+		}
+	}
+	for k, v := range in.Variables {
+		data[k] = v
+	}
+	return data, nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+)
+
+// SuratFieldChange adalah satu field payload yang berbeda antara dua versi.
+type SuratFieldChange struct {
+	Field string `json:"field"` // path payload, mis. "meta.subject" atau "body_content[1]"
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// SuratVersionDiff adalah hasil perbandingan payload dua versi surat.
+type SuratVersionDiff struct {
+	SuratID uint               `json:"surat_id"`
+	From    int                `json:"from"`
+	To      int                `json:"to"`
+	Changes []SuratFieldChange `json:"changes"`
+}
+
+// recordVersion menyimpan PDF & payload surat saat ini sebagai versi baru. Tidak membuat
+// versi baru jika file belum berubah sejak versi terakhir (mis. surat upload yang disetujui).
+// Gagal mencatat versi tidak membatalkan aksi utama, cukup dicatat di log.
+func (s *suratService) recordVersion(ctx context.Context, row *model.Surat, author *uuid.UUID, reason string) {
+	if s.versionRepo == nil || row == nil || row.ID == 0 || row.FileKey == "" {
+		return
+	}
+	if last, err := s.versionRepo.Latest(ctx, row.ID); err == nil && last.FileKey == row.FileKey {
+		return
+	}
+	v := &model.SuratVersion{
+		SuratID:   row.ID,
+		Status:    row.Status,
+		Number:    row.Number,
+		FileKey:   row.FileKey,
+		Reason:    reason,
+		CreatedBy: author,
+	}
+	var meta map[string]json.RawMessage
+	if len(row.MetaJSON) > 0 && json.Unmarshal(row.MetaJSON, &meta) == nil {
+		if raw, ok := meta["payload"]; ok && string(raw) != "null" {
+			v.Payload = []byte(raw)
+		}
+		if raw, ok := meta["theme"]; ok && string(raw) != "null" {
+			v.Theme = []byte(raw)
+		}
+	}
+	if err := s.versionRepo.Create(ctx, v); err != nil {
+		fmt.Printf("[SURAT] failed to record version for surat %d: %v\n", row.ID, err)
+	}
+}
+
+func (s *suratService) ListVersions(ctx context.Context, id uint) ([]model.SuratVersion, error) {
+	if s.versionRepo == nil {
+		return nil, fmt.Errorf("surat version repository not wired")
+	}
+	return s.versionRepo.List(ctx, id)
+}
+
+func (s *suratService) GetVersion(ctx context.Context, id uint, version int) (*model.SuratVersion, error) {
+	if s.versionRepo == nil {
+		return nil, fmt.Errorf("surat version repository not wired")
+	}
+	return s.versionRepo.Get(ctx, id, version)
+}
+
+// DiffVersions membandingkan payload dan theme dua versi per field.
+func (s *suratService) DiffVersions(ctx context.Context, id uint, from, to int) (*SuratVersionDiff, error) {
+	if s.versionRepo == nil {
+		return nil, fmt.Errorf("surat version repository not wired")
+	}
+	if from <= 0 || to <= 0 {
+		return nil, errors.New("from and to version required")
+	}
+	a, err := s.versionRepo.Get(ctx, id, from)
+	if err != nil {
+		return nil, fmt.Errorf("versi %d tidak ditemukan", from)
+	}
+	b, err := s.versionRepo.Get(ctx, id, to)
+	if err != nil {
+		return nil, fmt.Errorf("versi %d tidak ditemukan", to)
+	}
+
+	left, right := versionFields(a), versionFields(b)
+	keys := map[string]struct{}{}
+	for k := range left {
+		keys[k] = struct{}{}
+	}
+	for k := range right {
+		keys[k] = struct{}{}
+	}
+	out := &SuratVersionDiff{SuratID: id, From: from, To: to, Changes: []SuratFieldChange{}}
+	for k := range keys {
+		if left[k] != right[k] {
+			out.Changes = append(out.Changes, SuratFieldChange{Field: k, From: left[k], To: right[k]})
+		}
+	}
+	sort.Slice(out.Changes, func(i, j int) bool { return out.Changes[i].Field < out.Changes[j].Field })
+	return out, nil
+}
+
+// versionFields meratakan payload/theme versi menjadi map path -> nilai teks.
+func versionFields(v *model.SuratVersion) map[string]string {
+	out := map[string]string{"number": v.Number}
+	for prefix, raw := range map[string][]byte{"": v.Payload, "theme": v.Theme} {
+		if len(raw) == 0 {
+			continue
+		}
+		var doc any
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			continue
+		}
+		flattenPayload(prefix, doc, out)
+	}
+	return out
+}
+
+func flattenPayload(path string, node any, out map[string]string) {
+	switch val := node.(type) {
+	case map[string]any:
+		for k, child := range val {
+			next := k
+			if path != "" {
+				next = path + "." + k
+			}
+			flattenPayload(next, child, out)
+		}
+	case []any:
+		for i, child := range val {
+			flattenPayload(path+"["+strconv.Itoa(i)+"]", child, out)
+		}
+	case nil:
+		// field kosong dianggap sama dengan field yang tidak ada
+	case string:
+		if val == "" {
+			return
+		}
+		if isImageField(path) {
+			// gambar base64 bisa ratusan KB; cukup tampilkan sidik jarinya
+			sum := sha256.Sum256([]byte(val))
+			out[path] = "[gambar " + hex.EncodeToString(sum[:4]) + "]"
+			return
+		}
+		out[path] = val
+	default:
+		b, _ := json.Marshal(val)
+		out[path] = string(b)
+	}
+}
+
+func isImageField(path string) bool {
+	return strings.HasSuffix(path, "_base64") || strings.HasSuffix(path, "_logo")
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/util/storage"
+	"simawa-backend/internal/util/suratpdf"
+)
+
+// Withdraw menarik kembali surat PENDING ke DRAFT. Nomor surat yang sudah terbit
+// tetap dipakai saat diajukan ulang; tanda tangan yang terkumpul dibatalkan.
+func (s *suratService) Withdraw(ctx context.Context, userID uuid.UUID, id uint, mc *minio.Client, bucket string) (*model.Surat, error) {
+	if s.suratRepo == nil {
+		return nil, fmt.Errorf("surat repository not wired")
+	}
+	// status dibaca ulang di dalam kunci, sama seperti Sign/Decide
+	var out *model.Surat
+	err := s.suratRepo.WithLock(ctx, id, func() error {
+		row, err := s.withdraw(ctx, userID, id, mc, bucket)
+		out = row
+		return err
+	})
+	return out, err
+}
+
+func (s *suratService) withdraw(ctx context.Context, userID uuid.UUID, id uint, mc *minio.Client, bucket string) (*model.Surat, error) {
+	row, err := s.suratRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != model.SuratStatusPending {
+		return nil, errors.New("hanya surat pending yang bisa ditarik kembali")
+	}
+	var by *uuid.UUID
+	if userID != uuid.Nil {
+		by = &userID
+	}
+	_, current, err := s.signingSteps(ctx, row.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.resetSigning(ctx, row.ID); err != nil {
+		return nil, err
+	}
+	row.Status = model.SuratStatusDraft
+	if err := s.suratRepo.Update(ctx, row); err != nil {
+		return nil, err
+	}
+	// PDF dirender ulang tanpa TTD yang sudah terkumpul.
+	if err := s.rerender(ctx, row, by, "surat ditarik kembali", mc, bucket); err != nil {
+		return nil, err
+	}
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_withdraw", map[string]any{"surat_id": row.ID, "org_id": row.OrgID, "number": row.Number})
+	}
+	if s.notify != nil && current != nil {
+		_ = s.notify.Push(ctx, current.UserID, "Surat ditarik kembali", fmt.Sprintf("%s tidak lagi menunggu tanda tangan Anda", row.Subject), map[string]any{"surat_id": row.ID})
+	}
+	return row, nil
+}
+
+// Void membatalkan surat APPROVED. PDF final diberi cap "DIBATALKAN" dan disimpan
+// sebagai versi baru; hash dan tanda tangan digital surat asli tetap tersimpan
+// sehingga salinan lama masih bisa dicocokkan lalu ditandai batal.
+func (s *suratService) Void(ctx context.Context, userID uuid.UUID, id uint, reason string, mc *minio.Client, bucket string) (*model.Surat, error) {
+	if s.suratRepo == nil {
+		return nil, fmt.Errorf("surat repository not wired")
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("alasan pembatalan wajib diisi")
+	}
+	var out *model.Surat
+	err := s.suratRepo.WithLock(ctx, id, func() error {
+		row, err := s.void(ctx, userID, id, reason, mc, bucket)
+		out = row
+		return err
+	})
+	return out, err
+}
+
+func (s *suratService) void(ctx context.Context, userID uuid.UUID, id uint, reason string, mc *minio.Client, bucket string) (*model.Surat, error) {
+	row, err := s.suratRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != model.SuratStatusApproved {
+		return nil, errors.New("hanya surat yang sudah disetujui yang bisa dibatalkan")
+	}
+	now := time.Now()
+	if row.FileKey != "" {
+		src, err := s.readObject(ctx, mc, bucket, row.FileKey)
+		if err != nil {
+			return nil, err
+		}
+		stamped, err := suratpdf.StampVoid(src, reason, now)
+		if err != nil {
+			return nil, err
+		}
+		key := fmt.Sprintf("surat/%s.pdf", uuid.New().String())
+		if _, err := storage.UploadToMinio(ctx, mc, bucket, key, bytes.NewReader(stamped), int64(len(stamped)), "application/pdf"); err != nil {
+			return nil, err
+		}
+		row.FileKey = key
+	}
+	row.Status = model.SuratStatusVoided
+	row.VoidReason = reason
+	row.VoidedAt = &now
+	if userID != uuid.Nil {
+		row.VoidedBy = &userID
+	}
+	if err := s.suratRepo.Update(ctx, row); err != nil {
+		return nil, err
+	}
+	s.recordVersion(ctx, row, row.VoidedBy, "surat dibatalkan: "+reason)
+	if s.audit != nil && userID != uuid.Nil {
+		s.audit.Log(ctx, userID, "surat_void", map[string]any{"surat_id": row.ID, "org_id": row.OrgID, "number": row.Number, "reason": reason})
+	}
+	title := "Surat dibatalkan"
+	body := fmt.Sprintf("Surat %s (%s) dibatalkan: %s", row.Number, row.Subject, reason)
+	if s.notify != nil && row.CreatedBy != nil && (row.VoidedBy == nil || *row.CreatedBy != *row.VoidedBy) {
+		_ = s.notify.Push(ctx, *row.CreatedBy, title, body, map[string]any{"surat_id": row.ID})
+	}
+	s.pushTembusan(ctx, row, title, body)
+	return row, nil
+}
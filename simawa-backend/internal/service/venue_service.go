@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"simawa-backend/internal/model"
+	"simawa-backend/internal/repository"
+)
+
+// maxVenueWindow membatasi rentang ketersediaan ruangan per permintaan.
+const maxVenueWindow = 366 * 24 * time.Hour
+
+type VenueService struct {
+	repo     repository.VenueRepository
+	activity *ActivityService
+	audit    *AuditService
+}
+
+func NewVenueService(repo repository.VenueRepository, activity *ActivityService, audit *AuditService) *VenueService {
+	return &VenueService{repo: repo, activity: activity, audit: audit}
+}
+
+// VenueInput berisi data katalog ruangan; Active nil berarti tidak diubah
+// (atau aktif untuk ruangan baru).
+type VenueInput struct {
+	Code        string
+	Name        string
+	Description string
+	Capacity    int
+	Facilities  []string
+	OwnerUnit   string
+	Active      *bool
+}
+
+func (s *VenueService) apply(v *model.Venue, in *VenueInput) error {
+	if code := strings.ToUpper(strings.TrimSpace(in.Code)); code != "" {
+		v.Code = code
+	}
+	if name := strings.TrimSpace(in.Name); name != "" {
+		v.Name = name
+	}
+	if v.Code == "" || v.Name == "" {
+		return errors.New("kode dan nama ruangan wajib diisi")
+	}
+	if in.Capacity < 0 {
+		return errors.New("kapasitas tidak boleh negatif")
+	}
+	v.Description = in.Description
+	v.Capacity = in.Capacity
+	v.OwnerUnit = strings.TrimSpace(in.OwnerUnit)
+	facilities := make([]string, 0, len(in.Facilities))
+	for _, f := range in.Facilities {
+		if f = strings.TrimSpace(f); f != "" {
+			facilities = append(facilities, f)
+		}
+	}
+	v.Facilities, _ = json.Marshal(facilities)
+	if in.Active != nil {
+		v.Active = *in.Active
+	}
+	return nil
+}
+
+func (s *VenueService) Create(ctx context.Context, userID uuid.UUID, in *VenueInput) (*model.Venue, error) {
+	v := &model.Venue{Active: true}
+	if err := s.apply(v, in); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, v); err != nil {
+		return nil, err
+	}
+	if s.audit != nil {
+		s.audit.Log(ctx, userID, "venue_create", map[string]any{"venue_id": v.ID, "code": v.Code})
+	}
+	return v, nil
+}
+
+func (s *VenueService) Update(ctx context.Context, userID uuid.UUID, id uint, in *VenueInput) (*model.Venue, error) {
+	v, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, errors.New("ruangan tidak ditemukan")
+	}
+	if err := s.apply(v, in); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, v); err != nil {
+		return nil, err
+	}
+	if s.audit != nil {
+		s.audit.Log(ctx, userID, "venue_update", map[string]any{"venue_id": v.ID, "code": v.Code})
+	}
+	return v, nil
+}
+
+// Delete menghapus ruangan yang belum pernah dipakai kegiatan; ruangan yang sudah
+// punya riwayat pemesanan cukup dinonaktifkan.
+func (s *VenueService) Delete(ctx context.Context, userID uuid.UUID, id uint) error {
+	v, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return errors.New("ruangan tidak ditemukan")
+	}
+	n, err := s.repo.CountActivities(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return fmt.Errorf("ruangan dipakai %d kegiatan, nonaktifkan saja", n)
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if s.audit != nil {
+		s.audit.Log(ctx, userID, "venue_delete", map[string]any{"venue_id": id, "code": v.Code})
+	}
+	return nil
+}
+
+func (s *VenueService) Get(ctx context.Context, id uint) (*model.Venue, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *VenueService) List(ctx context.Context, activeOnly bool) ([]model.Venue, error) {
+	return s.repo.List(ctx, activeOnly)
+}
+
+// Availability mengembalikan slot terpakai ruangan di [from, to]. Kegiatan PENDING
+// ikut ditampilkan bila includePending, karena pengajuan baru akan bentrok dengannya.
+func (s *VenueService) Availability(ctx context.Context, id uint, from, to time.Time, includePending bool) (*model.Venue, []VenueBooking, error) {
+	if !to.After(from) {
+		return nil, nil, errors.New("rentang waktu tidak valid")
+	}
+	if to.Sub(from) > maxVenueWindow {
+		return nil, nil, errors.New("rentang waktu maksimal satu tahun")
+	}
+	v, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, nil, errors.New("ruangan tidak ditemukan")
+	}
+	rows, err := s.activity.VenueBookings(ctx, id, from, to, includePending)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, rows, nil
+}
@@ -0,0 +1,86 @@
+// Package checkin membuat dan memeriksa token QR presensi yang berputar.
+// Token tidak disimpan: isinya (jenis, ID, jendela waktu) ditandatangani HMAC
+// sehingga server cukup menghitung ulang untuk memverifikasi.
+package checkin
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	KindActivity byte = 'A' // QR yang ditampilkan penyelenggara, dipindai peserta
+	KindUser     byte = 'U' // QR pribadi peserta, dipindai penyelenggara
+)
+
+const macLen = 12
+
+var (
+	ErrInvalid = errors.New("token presensi tidak valid")
+	ErrExpired = errors.New("token presensi sudah kedaluwarsa, pindai ulang QR terbaru")
+)
+
+// Signer menerbitkan token yang berlaku selama satu jendela Rotate (ditambah satu
+// jendela sebelumnya agar QR yang baru berganti masih diterima).
+type Signer struct {
+	key    []byte
+	Rotate time.Duration
+}
+
+func NewSigner(secret string, rotate time.Duration) *Signer {
+	if rotate < 5*time.Second {
+		rotate = 30 * time.Second
+	}
+	return &Signer{key: []byte("checkin:" + secret), Rotate: rotate}
+}
+
+func (s *Signer) window(t time.Time) int64 {
+	return t.UnixNano() / int64(s.Rotate)
+}
+
+func (s *Signer) mac(payload []byte) []byte {
+	m := hmac.New(sha256.New, s.key)
+	m.Write(payload)
+	return m.Sum(nil)[:macLen]
+}
+
+// Issue menerbitkan token untuk subjek pada saat now beserta waktu kedaluwarsanya
+// (akhir jendela berikutnya).
+func (s *Signer) Issue(kind byte, subject uuid.UUID, now time.Time) (string, time.Time) {
+	w := s.window(now)
+	payload := make([]byte, 0, 1+16+8+macLen)
+	payload = append(payload, kind)
+	payload = append(payload, subject[:]...)
+	payload = binary.BigEndian.AppendUint64(payload, uint64(w))
+	payload = append(payload, s.mac(payload)...)
+	expires := time.Unix(0, (w+2)*int64(s.Rotate))
+	return base64.RawURLEncoding.EncodeToString(payload), expires
+}
+
+// Verify memeriksa token dan mengembalikan jenis serta subjeknya.
+func (s *Signer) Verify(token string, now time.Time) (byte, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) != 1+16+8+macLen {
+		return 0, uuid.Nil, ErrInvalid
+	}
+	body, sig := raw[:25], raw[25:]
+	if !hmac.Equal(sig, s.mac(body)) {
+		return 0, uuid.Nil, ErrInvalid
+	}
+	kind := body[0]
+	if kind != KindActivity && kind != KindUser {
+		return 0, uuid.Nil, ErrInvalid
+	}
+	subject, _ := uuid.FromBytes(body[1:17])
+	w := int64(binary.BigEndian.Uint64(body[17:25]))
+	if cur := s.window(now); w > cur || cur-w > 1 {
+		return 0, uuid.Nil, ErrExpired
+	}
+	return kind, subject, nil
+}
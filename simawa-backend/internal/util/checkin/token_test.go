@@ -0,0 +1,101 @@
+package checkin
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestVerifyWindow(t *testing.T) {
+	s := NewSigner("rahasia", 30*time.Second)
+	subject := uuid.New()
+	issued := time.Date(2026, 3, 1, 9, 0, 10, 0, time.UTC) // 10 detik setelah awal jendela
+	tests := []struct {
+		name    string
+		kind    byte
+		at      time.Time
+		wantErr error
+	}{
+		{"jendela yang sama", KindActivity, issued, nil},
+		{"akhir jendela yang sama", KindActivity, issued.Add(19 * time.Second), nil},
+		{"jendela berikutnya masih diterima", KindActivity, issued.Add(20 * time.Second), nil},
+		{"akhir jendela berikutnya", KindUser, issued.Add(49 * time.Second), nil},
+		{"dua jendela kemudian kedaluwarsa", KindActivity, issued.Add(50 * time.Second), ErrExpired},
+		{"jauh setelahnya", KindUser, issued.Add(time.Hour), ErrExpired},
+		{"token dari masa depan", KindActivity, issued.Add(-11 * time.Second), ErrExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tok, expires := s.Issue(tt.kind, subject, issued)
+			if want := time.Date(2026, 3, 1, 9, 1, 0, 0, time.UTC); !expires.Equal(want) {
+				t.Fatalf("expires = %v, want %v", expires, want)
+			}
+			kind, got, err := s.Verify(tok, tt.at)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if kind != tt.kind || got != subject {
+				t.Fatalf("Verify = (%c, %s), want (%c, %s)", kind, got, tt.kind, subject)
+			}
+		})
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	s := NewSigner("rahasia", 30*time.Second)
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	tok, _ := s.Issue(KindUser, uuid.New(), now)
+	raw, err := base64.RawURLEncoding.DecodeString(tok)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flip := func(i int) string {
+		b := append([]byte(nil), raw...)
+		b[i] ^= 0x01
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	// token jenis lain yang ditandatangani dengan kunci yang sama
+	bad := append([]byte(nil), raw[:25]...)
+	bad[0] = 'X'
+	bad = append(bad, s.mac(bad)...)
+
+	tests := []struct {
+		name   string
+		signer *Signer
+		token  string
+	}{
+		{"jenis diubah", s, flip(0)},
+		{"subjek diubah", s, flip(5)},
+		{"jendela diubah", s, flip(24)},
+		{"mac diubah", s, flip(len(raw) - 1)},
+		{"dipotong", s, tok[:len(tok)-2]},
+		{"bukan base64", s, "!!!" + tok},
+		{"kosong", s, ""},
+		{"rahasia lain", NewSigner("lain", 30*time.Second), tok},
+		{"jenis tidak dikenal", s, base64.RawURLEncoding.EncodeToString(bad)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.signer.Verify(tt.token, now); !errors.Is(err, ErrInvalid) {
+				t.Fatalf("Verify err = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
+
+func TestNewSignerMinimumRotate(t *testing.T) {
+	for _, rotate := range []time.Duration{0, time.Second, 4 * time.Second} {
+		if got := NewSigner("x", rotate).Rotate; got != 30*time.Second {
+			t.Errorf("NewSigner(%v).Rotate = %v, want 30s", rotate, got)
+		}
+	}
+	if got := NewSigner("x", time.Minute).Rotate; got != time.Minute {
+		t.Errorf("NewSigner(1m).Rotate = %v, want 1m", got)
+	}
+}
@@ -0,0 +1,443 @@
+// Package rrule mengimplementasikan subset aturan pengulangan RFC 5545 (RRULE) yang
+// dipakai kegiatan berulang: FREQ DAILY/WEEKLY/MONTHLY/YEARLY dengan INTERVAL,
+// COUNT, UNTIL, BYDAY (termasuk ordinal seperti 1MO atau -1FR), BYMONTHDAY dan
+// BYMONTH. Minggu selalu dimulai Senin (WKST=MO).
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Freq string
+
+const (
+	Daily   Freq = "DAILY"
+	Weekly  Freq = "WEEKLY"
+	Monthly Freq = "MONTHLY"
+	Yearly  Freq = "YEARLY"
+)
+
+// MaxCount membatasi COUNT agar satu seri tidak menghasilkan kemunculan tak wajar.
+const MaxCount = 1000
+
+// maxPeriods membatasi jumlah periode yang diperiksa saat mencari kemunculan,
+// supaya aturan yang hampir tidak pernah cocok (mis. 31 Februari) tetap berhenti.
+const maxPeriods = 50000
+
+// WeekdayNum adalah satu nilai BYDAY; N 0 berarti setiap hari tersebut dalam periode.
+type WeekdayNum struct {
+	Day time.Weekday
+	N   int
+}
+
+type Rule struct {
+	Freq       Freq
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []int
+}
+
+var dayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// Parse membaca RRULE, dengan atau tanpa awalan "RRULE:". UNTIL tanpa zona waktu
+// dibaca pada zona loc.
+func Parse(s string, loc *time.Location) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.ToUpper(s), "RRULE:")
+	if s == "" {
+		return nil, errors.New("rrule kosong")
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	r := &Rule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("rrule: bagian %q tidak valid", part)
+		}
+		key, val := kv[0], kv[1]
+		var err error
+		switch key {
+		case "FREQ":
+			switch Freq(val) {
+			case Daily, Weekly, Monthly, Yearly:
+				r.Freq = Freq(val)
+			default:
+				return nil, fmt.Errorf("rrule: FREQ %s tidak didukung", val)
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(val)
+			if err != nil || r.Interval < 1 {
+				return nil, errors.New("rrule: INTERVAL harus bilangan positif")
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(val)
+			if err != nil || r.Count < 1 || r.Count > MaxCount {
+				return nil, fmt.Errorf("rrule: COUNT harus 1 sampai %d", MaxCount)
+			}
+		case "UNTIL":
+			r.Until, err = parseUntil(val, loc)
+			if err != nil {
+				return nil, err
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				wd, err := parseWeekdayNum(d)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseInts(val, -31, 31)
+			if err != nil {
+				return nil, fmt.Errorf("rrule: BYMONTHDAY %v", err)
+			}
+		case "BYMONTH":
+			r.ByMonth, err = parseInts(val, 1, 12)
+			if err != nil {
+				return nil, fmt.Errorf("rrule: BYMONTH %v", err)
+			}
+		case "WKST":
+			if val != "MO" {
+				return nil, errors.New("rrule: hanya WKST=MO yang didukung")
+			}
+		default:
+			return nil, fmt.Errorf("rrule: %s tidak didukung", key)
+		}
+	}
+	if r.Freq == "" {
+		return nil, errors.New("rrule: FREQ wajib diisi")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, errors.New("rrule: COUNT dan UNTIL tidak boleh dipakai bersamaan")
+	}
+	for _, d := range r.ByDay {
+		if d.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return nil, errors.New("rrule: BYDAY berordinal hanya untuk FREQ MONTHLY/YEARLY")
+		}
+	}
+	if r.Freq == Yearly && len(r.ByDay) > 0 && len(r.ByMonth) == 0 {
+		return nil, errors.New("rrule: BYDAY pada FREQ YEARLY memerlukan BYMONTH")
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return nil, errors.New("rrule: BYMONTHDAY tidak berlaku untuk FREQ WEEKLY")
+	}
+	return r, nil
+}
+
+func parseUntil(v string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		var (
+			t   time.Time
+			err error
+		)
+		if strings.HasSuffix(layout, "Z") {
+			t, err = time.Parse(layout, v)
+		} else {
+			t, err = time.ParseInLocation(layout, v, loc)
+		}
+		if err == nil {
+			if layout == "20060102" {
+				// tanggal saja: berlaku sampai akhir hari tersebut
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("rrule: UNTIL %s tidak valid", v)
+}
+
+func parseWeekdayNum(v string) (WeekdayNum, error) {
+	v = strings.TrimSpace(v)
+	if len(v) < 2 {
+		return WeekdayNum{}, fmt.Errorf("rrule: BYDAY %q tidak valid", v)
+	}
+	day, ok := dayCodes[v[len(v)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("rrule: BYDAY %q tidak valid", v)
+	}
+	out := WeekdayNum{Day: day}
+	if num := v[:len(v)-2]; num != "" {
+		n, err := strconv.Atoi(num)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("rrule: BYDAY %q tidak valid", v)
+		}
+		out.N = n
+	}
+	return out, nil
+}
+
+func parseInts(v string, min, max int) ([]int, error) {
+	var out []int
+	for _, p := range strings.Split(v, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || n == 0 || n < min || n > max {
+			return nil, fmt.Errorf("nilai %q tidak valid", p)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// String menyusun kembali aturan dalam bentuk RRULE kanonis (tanpa awalan).
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = d.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	return strings.Join(parts, ";")
+}
+
+func (d WeekdayNum) String() string {
+	code := strings.ToUpper(d.Day.String()[:2])
+	if d.N != 0 {
+		return strconv.Itoa(d.N) + code
+	}
+	return code
+}
+
+func joinInts(v []int) string {
+	s := make([]string, len(v))
+	for i, n := range v {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ",")
+}
+
+// Finite true bila seri punya akhir (COUNT atau UNTIL).
+func (r *Rule) Finite() bool { return r.Count > 0 || !r.Until.IsZero() }
+
+// Between mengembalikan kemunculan berawal di [from, to] untuk seri yang dimulai
+// pada dtstart, paling banyak limit (0 = tanpa batas selain COUNT/UNTIL). dtstart
+// selalu menjadi kemunculan pertama; jam kemunculan mengikuti dtstart pada zonanya.
+func (r *Rule) Between(dtstart, from, to time.Time, limit int) []time.Time {
+	var out []time.Time
+	r.each(dtstart, func(t time.Time) bool {
+		if t.After(to) {
+			return false
+		}
+		if !t.Before(from) {
+			out = append(out, t)
+			if limit > 0 && len(out) >= limit {
+				return false
+			}
+		}
+		return true
+	})
+	return out
+}
+
+// All mengembalikan semua kemunculan seri berhingga; nil bila seri tidak berakhir.
+func (r *Rule) All(dtstart time.Time) []time.Time {
+	if !r.Finite() {
+		return nil
+	}
+	var out []time.Time
+	r.each(dtstart, func(t time.Time) bool {
+		out = append(out, t)
+		return true
+	})
+	return out
+}
+
+// each memanggil fn untuk setiap kemunculan secara berurutan sampai fn
+// mengembalikan false atau seri berakhir.
+func (r *Rule) each(dtstart time.Time, fn func(time.Time) bool) {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	n := 0
+	if !fn(dtstart) {
+		return
+	}
+	n++
+	for period := 0; period < maxPeriods; period++ {
+		for _, t := range r.candidates(dtstart, period*interval) {
+			if !t.After(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return
+			}
+			if r.Count > 0 && n >= r.Count {
+				return
+			}
+			if !fn(t) {
+				return
+			}
+			n++
+		}
+	}
+}
+
+// candidates menghasilkan kemunculan (terurut) pada periode ke-offset sejak dtstart.
+func (r *Rule) candidates(dtstart time.Time, offset int) []time.Time {
+	loc := dtstart.Location()
+	y, m, d := dtstart.Date()
+	hh, mm, ss := dtstart.Clock()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hh, mm, ss, 0, loc)
+	}
+	var out []time.Time
+	switch r.Freq {
+	case Daily:
+		t := at(y, m, d+offset)
+		if r.matchMonth(t.Month()) && r.matchMonthDay(t) && r.matchWeekday(t) {
+			out = append(out, t)
+		}
+	case Weekly:
+		// Senin pada minggu dtstart, lalu maju offset minggu
+		monday := at(y, m, d-(int(dtstart.Weekday())+6)%7+offset*7)
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []WeekdayNum{{Day: dtstart.Weekday()}}
+		}
+		for _, wd := range days {
+			t := monday.AddDate(0, 0, (int(wd.Day)+6)%7)
+			if r.matchMonth(t.Month()) {
+				out = append(out, t)
+			}
+		}
+	case Monthly:
+		first := at(y, m+time.Month(offset), 1)
+		if r.matchMonth(first.Month()) {
+			out = r.monthDays(first, d)
+		}
+	case Yearly:
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []int{int(m)}
+		}
+		for _, mo := range months {
+			out = append(out, r.monthDays(at(y+offset, time.Month(mo), 1), d)...)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return dedupe(out)
+}
+
+// monthDays menghasilkan hari-hari yang cocok dalam bulan first (tanggal 1).
+// Tanpa BYDAY/BYMONTHDAY dipakai tanggal dtstart (dilewati bila bulan lebih pendek).
+func (r *Rule) monthDays(first time.Time, dtDay int) []time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+	var out []time.Time
+	if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+		if dtDay <= last {
+			out = append(out, first.AddDate(0, 0, dtDay-1))
+		}
+		return out
+	}
+	for day := 1; day <= last; day++ {
+		t := first.AddDate(0, 0, day-1)
+		if len(r.ByMonthDay) > 0 && !r.matchMonthDay(t) {
+			continue
+		}
+		if len(r.ByDay) > 0 && !r.matchWeekdayInMonth(t, last) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+func (r *Rule) matchMonth(m time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, v := range r.ByMonth {
+		if time.Month(v) == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchMonthDay(t time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	for _, v := range r.ByMonthDay {
+		if v == t.Day() || (v < 0 && last+v+1 == t.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Day == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWeekdayInMonth mencocokkan BYDAY beserta ordinalnya di dalam bulan.
+func (r *Rule) matchWeekdayInMonth(t time.Time, lastDay int) bool {
+	for _, wd := range r.ByDay {
+		if wd.Day != t.Weekday() {
+			continue
+		}
+		switch {
+		case wd.N == 0:
+			return true
+		case wd.N > 0 && (t.Day()-1)/7+1 == wd.N:
+			return true
+		case wd.N < 0 && (lastDay-t.Day())/7+1 == -wd.N:
+			return true
+		}
+	}
+	return false
+}
+
+func dedupe(ts []time.Time) []time.Time {
+	out := ts[:0]
+	for i, t := range ts {
+		if i > 0 && t.Equal(ts[i-1]) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
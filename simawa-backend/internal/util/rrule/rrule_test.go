@@ -0,0 +1,228 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestParse(t *testing.T) {
+	jkt := mustLoad(t, "Asia/Jakarta")
+	tests := []struct {
+		name string
+		in   string
+		want string // bentuk kanonis; kosong berarti harus error
+	}{
+		{"prefix dan huruf kecil", "RRULE:freq=weekly;interval=1;byday=mo,we", "FREQ=WEEKLY;BYDAY=MO,WE"},
+		{"interval", "FREQ=MONTHLY;INTERVAL=2;COUNT=6", "FREQ=MONTHLY;INTERVAL=2;COUNT=6"},
+		{"ordinal byday", "FREQ=MONTHLY;BYDAY=1MO,-1FR", "FREQ=MONTHLY;BYDAY=1MO,-1FR"},
+		{"bymonthday negatif", "FREQ=MONTHLY;BYMONTHDAY=-1", "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{"yearly bymonth byday", "FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU", "FREQ=YEARLY;BYDAY=-1SU;BYMONTH=3"},
+		{"until lokal", "FREQ=DAILY;UNTIL=20260131T170000", "FREQ=DAILY;UNTIL=20260131T100000Z"},
+		{"until utc", "FREQ=DAILY;UNTIL=20260131T170000Z", "FREQ=DAILY;UNTIL=20260131T170000Z"},
+		{"until tanggal", "FREQ=DAILY;UNTIL=20260131", "FREQ=DAILY;UNTIL=20260131T165959Z"},
+		{"wkst mo", "FREQ=WEEKLY;WKST=MO", "FREQ=WEEKLY"},
+
+		{"kosong", "", ""},
+		{"tanpa freq", "INTERVAL=2", ""},
+		{"freq tidak didukung", "FREQ=HOURLY", ""},
+		{"interval nol", "FREQ=DAILY;INTERVAL=0", ""},
+		{"count terlalu besar", "FREQ=DAILY;COUNT=1001", ""},
+		{"count dan until", "FREQ=DAILY;COUNT=2;UNTIL=20260101", ""},
+		{"ordinal pada weekly", "FREQ=WEEKLY;BYDAY=1MO", ""},
+		{"ordinal di luar rentang", "FREQ=MONTHLY;BYDAY=6MO", ""},
+		{"bymonthday pada weekly", "FREQ=WEEKLY;BYMONTHDAY=1", ""},
+		{"bymonthday di luar rentang", "FREQ=MONTHLY;BYMONTHDAY=32", ""},
+		{"bymonthday nol", "FREQ=MONTHLY;BYMONTHDAY=0", ""},
+		{"yearly byday tanpa bymonth", "FREQ=YEARLY;BYDAY=MO", ""},
+		{"wkst lain", "FREQ=WEEKLY;WKST=SU", ""},
+		{"bagian tidak dikenal", "FREQ=DAILY;BYSETPOS=1", ""},
+		{"until rusak", "FREQ=DAILY;UNTIL=2026-01-31", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.in, jkt)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %q, want error", tt.in, r.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.in, err)
+			}
+			if got := r.String(); got != tt.want {
+				t.Fatalf("Parse(%q).String() = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAll(t *testing.T) {
+	jkt := mustLoad(t, "Asia/Jakarta")
+	at := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 9, 0, 0, 0, jkt) }
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		want    []time.Time
+	}{
+		{
+			"senin pertama tiap bulan", "FREQ=MONTHLY;BYDAY=1MO;COUNT=3", at(2026, 1, 5),
+			[]time.Time{at(2026, 1, 5), at(2026, 2, 2), at(2026, 3, 2)},
+		},
+		{
+			"jumat terakhir tiap bulan", "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", at(2026, 1, 30),
+			[]time.Time{at(2026, 1, 30), at(2026, 2, 27), at(2026, 3, 27)},
+		},
+		{
+			"hari terakhir tiap bulan", "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=4", at(2026, 1, 31),
+			[]time.Time{at(2026, 1, 31), at(2026, 2, 28), at(2026, 3, 31), at(2026, 4, 30)},
+		},
+		{
+			"dua mingguan senin dan rabu", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=5", at(2026, 1, 5),
+			[]time.Time{at(2026, 1, 5), at(2026, 1, 7), at(2026, 1, 19), at(2026, 1, 21), at(2026, 2, 2)},
+		},
+		{
+			"dua bulanan", "FREQ=MONTHLY;INTERVAL=2;COUNT=3", at(2026, 1, 15),
+			[]time.Time{at(2026, 1, 15), at(2026, 3, 15), at(2026, 5, 15)},
+		},
+		{
+			"tanggal 31 melewati bulan pendek", "FREQ=MONTHLY;COUNT=3", at(2026, 1, 31),
+			[]time.Time{at(2026, 1, 31), at(2026, 3, 31), at(2026, 5, 31)},
+		},
+		{
+			"dtstart di luar aturan tetap kemunculan pertama", "FREQ=WEEKLY;BYDAY=TU;COUNT=3", at(2026, 1, 5),
+			[]time.Time{at(2026, 1, 5), at(2026, 1, 6), at(2026, 1, 13)},
+		},
+		{
+			"until tanggal inklusif", "FREQ=DAILY;UNTIL=20260107", at(2026, 1, 5),
+			[]time.Time{at(2026, 1, 5), at(2026, 1, 6), at(2026, 1, 7)},
+		},
+		{
+			"29 februari tahunan", "FREQ=YEARLY;COUNT=2", at(2024, 2, 29),
+			[]time.Time{at(2024, 2, 29), at(2028, 2, 29)},
+		},
+		{
+			"minggu terakhir maret", "FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU;COUNT=2", at(2026, 3, 29),
+			[]time.Time{at(2026, 3, 29), at(2027, 3, 28)},
+		},
+		{"tanpa akhir", "FREQ=WEEKLY", at(2026, 1, 5), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.rule, jkt)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.rule, err)
+			}
+			assertTimes(t, r.All(tt.dtstart), tt.want)
+		})
+	}
+}
+
+func TestBetween(t *testing.T) {
+	jkt := mustLoad(t, "Asia/Jakarta")
+	at := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 9, 0, 0, 0, jkt) }
+	tests := []struct {
+		name     string
+		rule     string
+		dtstart  time.Time
+		from, to time.Time
+		limit    int
+		want     []time.Time
+	}{
+		{
+			"jendela di tengah seri tanpa akhir", "FREQ=WEEKLY;BYDAY=MO", at(2026, 1, 5),
+			at(2026, 2, 1), at(2026, 2, 28), 0,
+			[]time.Time{at(2026, 2, 2), at(2026, 2, 9), at(2026, 2, 16), at(2026, 2, 23)},
+		},
+		{
+			"batas jendela inklusif", "FREQ=DAILY", at(2026, 1, 5),
+			at(2026, 1, 6), at(2026, 1, 8), 0,
+			[]time.Time{at(2026, 1, 6), at(2026, 1, 7), at(2026, 1, 8)},
+		},
+		{
+			"limit", "FREQ=DAILY", at(2026, 1, 5),
+			at(2026, 1, 5), at(2026, 12, 31), 2,
+			[]time.Time{at(2026, 1, 5), at(2026, 1, 6)},
+		},
+		{
+			"count dihitung dari dtstart", "FREQ=DAILY;COUNT=3", at(2026, 1, 5),
+			at(2026, 1, 6), at(2026, 1, 31), 0,
+			[]time.Time{at(2026, 1, 6), at(2026, 1, 7)},
+		},
+		{
+			"jendela sebelum dtstart", "FREQ=DAILY", at(2026, 1, 5),
+			at(2025, 12, 1), at(2026, 1, 4), 0,
+			nil,
+		},
+		{
+			"ordinal dalam jendela", "FREQ=MONTHLY;BYDAY=2TU,-1FR", at(2026, 1, 13),
+			at(2026, 4, 1), at(2026, 5, 31), 0,
+			[]time.Time{at(2026, 4, 14), at(2026, 4, 24), at(2026, 5, 12), at(2026, 5, 29)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.rule, jkt)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.rule, err)
+			}
+			assertTimes(t, r.Between(tt.dtstart, tt.from, tt.to, tt.limit), tt.want)
+		})
+	}
+}
+
+// Jam kemunculan mengikuti jam dinding dtstart, termasuk saat zona berganti DST.
+func TestDST(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+	at := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 9, 0, 0, 0, ny) }
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		want    []time.Time
+	}{
+		// DST mulai 8 Maret 2026
+		{"weekly masuk DST", "FREQ=WEEKLY;COUNT=3", at(2026, 3, 1), []time.Time{at(2026, 3, 1), at(2026, 3, 8), at(2026, 3, 15)}},
+		// DST berakhir 1 November 2026
+		{"daily keluar DST", "FREQ=DAILY;COUNT=3", at(2026, 10, 31), []time.Time{at(2026, 10, 31), at(2026, 11, 1), at(2026, 11, 2)}},
+		{"monthly melewati DST", "FREQ=MONTHLY;BYDAY=1SU;COUNT=2", at(2026, 2, 1), []time.Time{at(2026, 2, 1), at(2026, 3, 1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.rule, ny)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.rule, err)
+			}
+			got := r.All(tt.dtstart)
+			assertTimes(t, got, tt.want)
+			for _, g := range got {
+				if h, m, _ := g.In(ny).Clock(); h != 9 || m != 0 {
+					t.Errorf("kemunculan %v tidak pukul 09:00 waktu setempat", g)
+				}
+			}
+		})
+	}
+}
+
+func assertTimes(t *testing.T, got, want []time.Time) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d kemunculan %v, want %d %v", len(got), got, len(want), want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("kemunculan #%d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
@@ -51,3 +51,23 @@ func DeleteFromMinio(ctx context.Context, client *minio.Client, bucket, key stri
 	return client.RemoveObject(ctx, bucket, key, minio.RemoveObjectOptions{})
 }
 
+
+// MoveInMinio menyalin objek ke key baru di bucket yang sama lalu menghapus objek lama.
+func MoveInMinio(ctx context.Context, client *minio.Client, bucket, src, dst string) error {
+	if client == nil {
+		return fmt.Errorf("minio client nil")
+	}
+	if bucket == "" {
+		return fmt.Errorf("minio bucket empty")
+	}
+	if src == "" || dst == "" {
+		return fmt.Errorf("object key empty")
+	}
+	if _, err := client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: bucket, Object: dst},
+		minio.CopySrcOptions{Bucket: bucket, Object: src},
+	); err != nil {
+		return err
+	}
+	return client.RemoveObject(ctx, bucket, src, minio.RemoveObjectOptions{})
+}
@@ -0,0 +1,451 @@
+// Package suratdocx menghasilkan surat dalam format Word (DOCX) dari payload yang
+// sama dengan suratpdf, untuk instansi yang meminta berkas yang bisa diedit.
+package suratdocx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+
+	"simawa-backend/internal/util/suratpdf"
+)
+
+// ContentType adalah MIME type berkas DOCX.
+const ContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+type media struct {
+	id   string
+	name string
+	data []byte
+}
+
+type writer struct {
+	body   bytes.Buffer
+	media  []media
+	theme  suratpdf.Theme
+	pageW  float64 // mm, dari suratpdf.PageSize
+	pageH  float64
+	textW  int // lebar area isi (twips)
+	nextID int
+	// endsWithTable: Word mensyaratkan paragraf setelah tabel terakhir sebelum sectPr
+	endsWithTable bool
+}
+
+// Render menyusun DOCX dengan urutan yang sama seperti suratpdf.Render:
+// kop, tanggal, tabel nomor, penerima, isi, penutup, grid tanda tangan, QR verifikasi, tembusan.
+// Ukuran kertas mengikuti theme; nomor halaman dan kop ulang tidak dipakai karena
+// pemisahan halaman di Word bergantung pada penyuntingnya.
+func Render(p suratpdf.Payload, th *suratpdf.Theme) ([]byte, error) {
+	t := suratpdf.ResolveTheme(th)
+	w := &writer{theme: t}
+	w.pageW, w.pageH = suratpdf.PageSize(t)
+	w.textW = mm(w.pageW - t.MarginLeft - t.MarginRight)
+
+	w.header(p)
+	w.numberTable(p)
+	w.recipient(p)
+	for _, para := range suratpdf.BodyParagraphs(p) {
+		text := strings.Join(strings.Fields(para), " ")
+		if text == "" {
+			continue
+		}
+		w.paragraph(`<w:spacing w:after="`+itoa(mm(1.5))+`"/><w:ind w:firstLine="`+itoa(mm(12))+`"/><w:jc w:val="both"/>`, run(text, ""))
+	}
+	if strings.TrimSpace(p.Footer) != "" {
+		w.paragraph(`<w:spacing w:before="`+itoa(mm(3))+`"/>`, run(p.Footer, ""))
+	}
+	if len(p.Signs) > 0 {
+		w.signers(p.Signs)
+	}
+	if p.Verification != nil && strings.TrimSpace(p.Verification.Code) != "" {
+		w.verification(*p.Verification)
+	}
+	if len(p.Tembusan) > 0 {
+		w.paragraph(`<w:spacing w:before="`+itoa(mm(4))+`"/>`, run("Tembusan :", ""))
+		for i, tbs := range p.Tembusan {
+			w.paragraph("", run(fmt.Sprintf("%d. %s", i+1, tbs), ""))
+		}
+	}
+	return w.pack()
+}
+
+func (w *writer) header(p suratpdf.Payload) {
+	if p.Header != nil {
+		lines := suratpdf.HeaderLines(p.Header)
+		left := w.image(p.Header.LeftLogo, 25, 25)
+		right := w.image(p.Header.RightLogo, 25, 25)
+		if len(lines) > 0 || left != "" || right != "" {
+			logoW := mm(30)
+			cols := []int{logoW, w.textW - 2*logoW, logoW}
+			rPr := ""
+			if _, _, _, ok := suratpdf.ParseHexColor(p.Header.TextColor); ok {
+				rPr = `<w:color w:val="` + strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(p.Header.TextColor), "#")) + `"/>`
+			}
+			var title strings.Builder
+			for _, line := range lines {
+				title.WriteString(para(`<w:jc w:val="center"/>`, run(line, rPr)))
+			}
+			w.table(cols, "", [][]string{{
+				para("", left),
+				title.String(),
+				para(`<w:jc w:val="right"/>`, right),
+			}})
+		}
+	}
+	// garis horizontal kop
+	w.paragraph(headerBorder(p.Header)+`<w:spacing w:after="`+itoa(mm(3))+`"/>`, "")
+	if strings.TrimSpace(p.Meta.PlaceAndDate) != "" {
+		w.paragraph(`<w:jc w:val="right"/>`, run(p.Meta.PlaceAndDate, ""))
+	}
+}
+
+// headerBorder menerjemahkan LineStyle/LineColor kop ke border paragraf Word.
+func headerBorder(h *suratpdf.Header) string {
+	style, color := suratpdf.LineSingle, "000000"
+	if h != nil {
+		style = suratpdf.NormalizeLineStyle(h.LineStyle)
+		if _, _, _, ok := suratpdf.ParseHexColor(h.LineColor); ok {
+			color = strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(h.LineColor), "#"))
+		}
+	}
+	val, sz := "single", "8"
+	switch style {
+	case suratpdf.LineNone:
+		return ""
+	case suratpdf.LineDouble:
+		val, sz = "double", "6"
+	case suratpdf.LineThickThin:
+		val, sz = "thickThinSmallGap", "18"
+	}
+	return `<w:pBdr><w:bottom w:val="` + val + `" w:sz="` + sz + `" w:space="1" w:color="` + color + `"/></w:pBdr>`
+}
+
+func (w *writer) numberTable(p suratpdf.Payload) {
+	rows := [][]string{}
+	for _, r := range []struct {
+		label, value string
+		bold         bool
+	}{
+		{"Nomor", p.Meta.Number, false},
+		{"Lampiran", p.Meta.Lampiran, false},
+		{"Perihal", p.Meta.Subject, true},
+	} {
+		if strings.TrimSpace(r.value) == "" {
+			continue
+		}
+		style := ""
+		if r.bold {
+			style = "<w:b/>"
+		}
+		rows = append(rows, []string{para("", run(r.label, "")), para("", run(":", "")), para("", run(r.value, style))})
+	}
+	if len(rows) == 0 {
+		return
+	}
+	labelW, colonW := mm(18), mm(3)
+	w.table([]int{labelW, colonW, w.textW - labelW - colonW}, "", rows)
+	w.paragraph("", "")
+}
+
+func (w *writer) recipient(p suratpdf.Payload) {
+	to := strings.TrimSpace(strings.Join([]string{p.Meta.ToRole, p.Meta.ToName}, " "))
+	if to != "" {
+		w.paragraph("", run("Kepada Yth.", ""))
+		w.paragraph("", run(to, "<w:b/>"))
+	}
+	loc := strings.TrimSpace(strings.Join([]string{p.Meta.ToPlace, p.Meta.ToCity}, ", "))
+	if loc != "" {
+		w.paragraph("", run("di", ""))
+		w.paragraph(`<w:ind w:left="`+itoa(mm(4))+`"/>`, run(loc, ""))
+	}
+	w.paragraph("", "")
+}
+
+// signers menyusun grid tanda tangan; satu tabel per baris agar baris berisi 1-2
+// penanda tangan bisa diletakkan di tengah seperti versi PDF.
+func (w *writer) signers(signs []suratpdf.Signer) {
+	colW := w.textW / 3
+	for _, row := range suratpdf.SignerRows(signs) {
+		cols := make([]int, len(row))
+		cells := make([]string, len(row))
+		for i, s := range row {
+			cols[i] = colW
+			cells[i] = w.signerCell(s)
+		}
+		w.paragraph("", "")
+		w.table(cols, `<w:jc w:val="center"/>`, [][]string{cells})
+	}
+}
+
+func (w *writer) signerCell(s suratpdf.Signer) string {
+	center := `<w:jc w:val="center"/>`
+	var b strings.Builder
+	b.WriteString(para(center, run(s.Role, "<w:b/>")))
+	if img := w.image(s.Stamp, 20, 20); img != "" {
+		b.WriteString(para(center, img))
+	} else if strings.TrimSpace(s.StampText) != "" {
+		b.WriteString(para(center, run("("+s.StampText+")", `<w:sz w:val="16"/>`)))
+	}
+	if img := w.image(s.TTD, 30, 12); img != "" {
+		b.WriteString(para(center, img))
+	} else {
+		// ruang kosong untuk tanda tangan basah
+		b.WriteString(para(center, "") + para(center, "") + para(center, ""))
+	}
+	b.WriteString(para(center, run(s.Name, `<w:b/><w:u w:val="single"/>`)))
+	if strings.TrimSpace(s.NIP) != "" {
+		b.WriteString(para(center, run("NIP: "+s.NIP, "<w:b/>")))
+	}
+	return b.String()
+}
+
+func (w *writer) verification(v suratpdf.Verification) {
+	content := v.URL
+	if strings.TrimSpace(content) == "" {
+		content = v.Code
+	}
+	png, err := qrcode.Encode(content, qrcode.Medium, 256)
+	if err != nil {
+		return
+	}
+	qrW := mm(28)
+	small := `<w:sz w:val="16"/>`
+	text := para("", run("Dokumen ini disetujui secara elektronik.", small)) +
+		para("", run("Kode verifikasi: "+v.Code, "<w:b/>"+small))
+	if v.URL != "" {
+		text += para("", run("Periksa keaslian: "+v.URL, small))
+	}
+	w.paragraph("", "")
+	w.table([]int{qrW, w.textW - qrW}, "", [][]string{{
+		para("", w.embed("png", png, 25, 25)),
+		text,
+	}})
+}
+
+// image memuat gambar base64/data URI atau path file lokal. Key MinIO dilewati,
+// sama seperti suratpdf.
+func (w *writer) image(src string, wMM, hMM float64) string {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return ""
+	}
+	data := src
+	if strings.HasPrefix(data, "data:") {
+		if idx := strings.Index(data, ","); idx >= 0 {
+			data = data[idx+1:]
+		}
+	}
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		if _, statErr := os.Stat(src); statErr != nil {
+			return ""
+		}
+		if raw, err = os.ReadFile(src); err != nil {
+			return ""
+		}
+	}
+	var ext string
+	switch http.DetectContentType(raw) {
+	case "image/png":
+		ext = "png"
+	case "image/jpeg":
+		ext = "jpeg"
+	case "image/gif":
+		ext = "gif"
+	default:
+		return ""
+	}
+	return w.embed(ext, raw, wMM, hMM)
+}
+
+// embed menyimpan gambar ke word/media dan mengembalikan run berisi gambar inline.
+func (w *writer) embed(ext string, data []byte, wMM, hMM float64) string {
+	w.nextID++
+	id := w.nextID
+	m := media{id: fmt.Sprintf("rIdImg%d", id), name: fmt.Sprintf("image%d.%s", id, ext), data: data}
+	w.media = append(w.media, m)
+	cx, cy := emu(wMM), emu(hMM)
+	return fmt.Sprintf(`<w:r><w:drawing><wp:inline distT="0" distB="0" distL="0" distR="0">`+
+		`<wp:extent cx="%d" cy="%d"/><wp:docPr id="%d" name="Gambar %d"/>`+
+		`<wp:cNvGraphicFramePr><a:graphicFrameLocks noChangeAspect="1"/></wp:cNvGraphicFramePr>`+
+		`<a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture"><pic:pic>`+
+		`<pic:nvPicPr><pic:cNvPr id="%d" name="%s"/><pic:cNvPicPr/></pic:nvPicPr>`+
+		`<pic:blipFill><a:blip r:embed="%s"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`+
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>`+
+		`</pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r>`,
+		cx, cy, id, id, id, m.name, m.id, cx, cy)
+}
+
+func (w *writer) paragraph(pPr, content string) {
+	w.body.WriteString(para(pPr, content))
+	w.endsWithTable = false
+}
+
+// table menulis tabel tanpa garis dengan lebar kolom tetap (twips).
+func (w *writer) table(cols []int, tblPr string, rows [][]string) {
+	total := 0
+	for _, c := range cols {
+		total += c
+	}
+	b := &w.body
+	b.WriteString(`<w:tbl><w:tblPr><w:tblW w:w="` + itoa(total) + `" w:type="dxa"/>` + tblPr + `<w:tblLayout w:type="fixed"/>`)
+	b.WriteString(`<w:tblCellMar><w:left w:w="0" w:type="dxa"/><w:right w:w="0" w:type="dxa"/></w:tblCellMar></w:tblPr><w:tblGrid>`)
+	for _, c := range cols {
+		b.WriteString(`<w:gridCol w:w="` + itoa(c) + `"/>`)
+	}
+	b.WriteString(`</w:tblGrid>`)
+	for _, row := range rows {
+		b.WriteString(`<w:tr>`)
+		for i, cell := range row {
+			b.WriteString(`<w:tc><w:tcPr><w:tcW w:w="` + itoa(cols[i]) + `" w:type="dxa"/><w:vAlign w:val="center"/></w:tcPr>`)
+			if cell == "" {
+				cell = para("", "")
+			}
+			b.WriteString(cell)
+			b.WriteString(`</w:tc>`)
+		}
+		b.WriteString(`</w:tr>`)
+	}
+	b.WriteString(`</w:tbl>`)
+	w.endsWithTable = true
+}
+
+func (w *writer) pack() ([]byte, error) {
+	if w.endsWithTable {
+		w.paragraph("", "")
+	}
+	t := w.theme
+	doc := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"` +
+		` xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"` +
+		` xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"` +
+		` xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"` +
+		` xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture"><w:body>` +
+		w.body.String() +
+		fmt.Sprintf(`<w:sectPr><w:pgSz w:w="%d" w:h="%d"%s/><w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="0" w:footer="0" w:gutter="0"/></w:sectPr>`,
+			mm(w.pageW), mm(w.pageH), orient(w.pageW, w.pageH), mm(t.MarginTop), mm(t.MarginRight), mm(t.MarginBottom), mm(t.MarginLeft)) +
+		`</w:body></w:document>`
+
+	font := escape(wordFont(t.FontFamily))
+	styles := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:docDefaults>` +
+		`<w:rPrDefault><w:rPr><w:rFonts w:ascii="` + font + `" w:hAnsi="` + font + `" w:cs="` + font + `"/>` +
+		`<w:sz w:val="` + itoa(int(t.FontSize*2)) + `"/><w:szCs w:val="` + itoa(int(t.FontSize*2)) + `"/><w:lang w:val="id-ID"/></w:rPr></w:rPrDefault>` +
+		`<w:pPrDefault><w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr></w:pPrDefault>` +
+		`</w:docDefaults></w:styles>`
+
+	rels := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rIdStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`
+	for _, m := range w.media {
+		rels += `<Relationship Id="` + m.id + `" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/` + m.name + `"/>`
+	}
+	rels += `</Relationships>`
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", []byte(contentTypesXML)},
+		{"_rels/.rels", []byte(rootRelsXML)},
+		{"word/document.xml", []byte(doc)},
+		{"word/styles.xml", []byte(styles)},
+		{"word/_rels/document.xml.rels", []byte(rels)},
+	}
+	for _, m := range w.media {
+		files = append(files, struct {
+			name string
+			data []byte
+		}{"word/media/" + m.name, m.data})
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fw.Write(f.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const contentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+	`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Default Extension="png" ContentType="image/png"/>` +
+	`<Default Extension="jpeg" ContentType="image/jpeg"/>` +
+	`<Default Extension="gif" ContentType="image/gif"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`</Types>`
+
+const rootRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`</Relationships>`
+
+func para(pPr, content string) string {
+	if pPr != "" {
+		pPr = "<w:pPr>" + pPr + "</w:pPr>"
+	}
+	return "<w:p>" + pPr + content + "</w:p>"
+}
+
+func run(text, rPr string) string {
+	if rPr != "" {
+		rPr = "<w:rPr>" + rPr + "</w:rPr>"
+	}
+	return `<w:r>` + rPr + `<w:t xml:space="preserve">` + escape(text) + `</w:t></w:r>`
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// wordFont memetakan font inti gofpdf ke nama font Word.
+func wordFont(family string) string {
+	switch strings.ToLower(family) {
+	case "times", "":
+		return "Times New Roman"
+	case "helvetica", "arial":
+		return "Arial"
+	case "courier":
+		return "Courier New"
+	case strings.ToLower(suratpdf.UnicodeFont):
+		return "DejaVu Sans Condensed"
+	default:
+		return family
+	}
+}
+
+func orient(w, h float64) string {
+	if w > h {
+		return ` w:orient="landscape"`
+	}
+	return ""
+}
+
+// mm mengonversi milimeter ke twips (1/20 pt).
+func mm(v float64) int { return int(v * 1440 / 25.4) }
+
+// emu mengonversi milimeter ke English Metric Unit untuk ukuran gambar.
+func emu(v float64) int { return int(v * 36000) }
+
+func itoa(v int) string { return fmt.Sprint(v) }
@@ -0,0 +1,113 @@
+package suratpdf
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// AgendaRow adalah satu baris buku agenda surat masuk yang sudah diformat.
+type AgendaRow struct {
+	AgendaNumber   string
+	ReceivedAt     string
+	Sender         string
+	SenderNumber   string
+	LetterDate     string
+	Subject        string
+	Classification string
+}
+
+var agendaColumns = []struct {
+	title string
+	width float64
+}{
+	{"No", 10},
+	{"No. Agenda", 38},
+	{"Tgl Terima", 26},
+	{"Pengirim", 55},
+	{"No. Surat Asal", 45},
+	{"Tgl Surat", 26},
+	{"Perihal", 88},
+	{"Sifat", 22},
+}
+
+// RenderAgendaBook mencetak buku agenda surat masuk dalam tabel F4 landscape.
+func RenderAgendaBook(title, subtitle string, rows []AgendaRow) ([]byte, error) {
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: 210, Ht: 330},
+		OrientationStr: "L",
+	})
+	pdf.SetMargins(12, 12, 12)
+	pdf.SetAutoPageBreak(true, 12)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	header := func() {
+		pdf.SetFont("Times", "B", 10)
+		pdf.SetFillColor(230, 230, 230)
+		for _, col := range agendaColumns {
+			pdf.CellFormat(col.width, 8, col.title, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Times", "", 10)
+	}
+	pdf.SetHeaderFunc(func() {
+		if pdf.PageNo() > 1 {
+			header()
+		}
+	})
+
+	pdf.AddPage()
+	pdf.SetFont("Times", "B", 14)
+	pdf.CellFormat(0, 8, tr(title), "", 1, "C", false, 0, "")
+	if subtitle != "" {
+		pdf.SetFont("Times", "", 11)
+		pdf.CellFormat(0, 6, tr(subtitle), "", 1, "C", false, 0, "")
+	}
+	pdf.Ln(4)
+	header()
+
+	const lineHt = 5.0
+	_, pageHt := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	for i, r := range rows {
+		cells := []string{
+			fmt.Sprintf("%d", i+1), r.AgendaNumber, r.ReceivedAt, r.Sender,
+			r.SenderNumber, r.LetterDate, r.Subject, r.Classification,
+		}
+		// tinggi baris mengikuti kolom dengan teks terpanjang
+		lines := 1
+		for j, v := range cells {
+			if n := len(pdf.SplitLines([]byte(tr(v)), agendaColumns[j].width-2)); n > lines {
+				lines = n
+			}
+		}
+		h := float64(lines) * lineHt
+		if pdf.GetY()+h > pageHt-bottom {
+			pdf.AddPage()
+		}
+		x, y := pdf.GetXY()
+		for j, v := range cells {
+			w := agendaColumns[j].width
+			pdf.Rect(x, y, w, h, "D")
+			pdf.SetXY(x+1, y)
+			align := "L"
+			if j == 0 {
+				align = "C"
+			}
+			pdf.MultiCell(w-2, lineHt, tr(v), "", align, false)
+			x += w
+		}
+		pdf.SetXY(12, y+h)
+	}
+	if len(rows) == 0 {
+		pdf.CellFormat(0, 8, "Belum ada surat masuk pada periode ini.", "1", 1, "C", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,110 @@
+package suratpdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/jung-kurt/gofpdf/contrib/gofpdi"
+)
+
+// Attachment adalah berkas lampiran (PDF atau gambar) yang digabung ke PDF surat
+// sebagai halaman tambahan setelah halaman surat.
+type Attachment struct {
+	Name        string
+	ContentType string // application/pdf, image/jpeg, image/png, image/gif
+	Data        []byte
+}
+
+// AttachmentTypes adalah tipe lampiran yang bisa digabung ke PDF.
+var AttachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+}
+
+// CheckAttachment mencoba menggabungkan satu lampiran ke dokumen kosong supaya
+// berkas yang rusak atau tidak didukung ditolak saat diunggah, bukan saat surat dirender.
+func CheckAttachment(a Attachment) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Times", "", 12)
+	if err := appendAttachment(pdf, a, 0); err != nil {
+		return err
+	}
+	return pdf.Output(io.Discard)
+}
+
+func appendAttachments(pdf *gofpdf.Fpdf, atts []Attachment) error {
+	for i, a := range atts {
+		if err := appendAttachment(pdf, a, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendAttachment(pdf *gofpdf.Fpdf, a Attachment, idx int) error {
+	switch {
+	case a.ContentType == "application/pdf":
+		return appendPDFPages(pdf, a)
+	case strings.HasPrefix(a.ContentType, "image/") && AttachmentTypes[a.ContentType]:
+		return appendImagePage(pdf, a, idx)
+	default:
+		return fmt.Errorf("lampiran %s: tipe %s tidak didukung", a.Name, a.ContentType)
+	}
+}
+
+// appendPDFPages mengimpor semua halaman PDF lampiran dengan ukuran halaman aslinya.
+func appendPDFPages(pdf *gofpdf.Fpdf, a Attachment) (err error) {
+	// gofpdi memakai panic untuk PDF yang tidak bisa dibaca
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("lampiran %s tidak dapat dibaca sebagai PDF: %v", a.Name, r)
+		}
+	}()
+	imp := gofpdi.NewImporter()
+	var rs io.ReadSeeker = bytes.NewReader(a.Data)
+	first := imp.ImportPageFromStream(pdf, &rs, 1, "/MediaBox")
+	sizes := imp.GetPageSizes()
+	for page := 1; page <= len(sizes); page++ {
+		tpl := first
+		if page > 1 {
+			tpl = imp.ImportPageFromStream(pdf, &rs, page, "/MediaBox")
+		}
+		box := sizes[page]["/MediaBox"]
+		w, h := ptToMM(box["w"]), ptToMM(box["h"])
+		if w <= 0 || h <= 0 {
+			return fmt.Errorf("lampiran %s: ukuran halaman %d tidak valid", a.Name, page)
+		}
+		pdf.AddPageFormat("P", gofpdf.SizeType{Wd: w, Ht: h})
+		imp.UseImportedTemplate(pdf, tpl, 0, 0, w, h)
+	}
+	return pdf.Error()
+}
+
+// appendImagePage menaruh gambar pada halaman baru, diperkecil agar muat di dalam margin.
+func appendImagePage(pdf *gofpdf.Fpdf, a Attachment, idx int) error {
+	name := fmt.Sprintf("lampiran_%d_%s", idx, a.Name)
+	info := pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: strings.TrimPrefix(a.ContentType, "image/"), ReadDpi: true}, bytes.NewReader(a.Data))
+	if err := pdf.Error(); err != nil || info == nil {
+		return fmt.Errorf("lampiran %s tidak dapat dibaca sebagai gambar: %v", a.Name, err)
+	}
+	pdf.AddPage()
+	pw, ph := pdf.GetPageSize()
+	lm, tm, rm, _ := pdf.GetMargins()
+	_, bm := pdf.GetAutoPageBreak()
+	maxW, maxH := pw-lm-rm, ph-tm-bm
+	w, h := info.Width(), info.Height()
+	scale := maxW / w
+	if h*scale > maxH {
+		scale = maxH / h
+	}
+	w, h = w*scale, h*scale
+	pdf.ImageOptions(name, lm+(maxW-w)/2, tm, w, h, false, gofpdf.ImageOptions{}, 0, "")
+	return pdf.Error()
+}
+
+func ptToMM(v float64) float64 { return v * 25.4 / 72 }
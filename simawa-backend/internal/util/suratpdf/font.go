@@ -0,0 +1,221 @@
+package suratpdf
+
+import (
+	"embed"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// UnicodeFont adalah font TTF bawaan yang di-embed ke binary. Dipakai bila theme
+// memintanya, atau otomatis bila teks surat tidak bisa ditulis dengan font inti.
+const UnicodeFont = "DejaVuSans"
+
+//go:embed fonts/*.ttf
+var bundledFS embed.FS
+
+// bundledFonts memetakan nama family (huruf kecil) ke berkas per gaya.
+var bundledFonts = map[string]map[string]string{
+	strings.ToLower(UnicodeFont): {
+		"":   "fonts/DejaVuSansCondensed.ttf",
+		"B":  "fonts/DejaVuSansCondensed-Bold.ttf",
+		"I":  "fonts/DejaVuSansCondensed-Oblique.ttf",
+		"BI": "fonts/DejaVuSansCondensed-BoldOblique.ttf",
+	},
+}
+
+// Font adalah font TTF tambahan (mis. unggahan organisasi) untuk satu gaya:
+// "" (regular), "B", "I", atau "BI".
+type Font struct {
+	Family string
+	Style  string
+	Data   []byte
+}
+
+// fontStyles adalah gaya yang dipakai renderer; gaya yang tidak tersedia
+// didaftarkan memakai berkas regular agar SetFontStyle tidak gagal.
+var fontStyles = []string{"", "B", "I", "BI"}
+
+// NormalizeFontStyle menyeragamkan gaya font ("b", "IB", "bold" -> "B"/"BI").
+func NormalizeFontStyle(v string) (string, bool) {
+	switch strings.ToUpper(strings.TrimSpace(v)) {
+	case "", "R", "REGULAR":
+		return "", true
+	case "B", "BOLD":
+		return "B", true
+	case "I", "ITALIC":
+		return "I", true
+	case "BI", "IB", "BOLDITALIC":
+		return "BI", true
+	default:
+		return "", false
+	}
+}
+
+// CheckFont memastikan data adalah TTF yang bisa dipakai gofpdf, sehingga font
+// rusak ditolak saat diunggah, bukan saat surat dirender.
+func CheckFont(data []byte) error {
+	if len(data) < 4 {
+		return errors.New("berkas font kosong")
+	}
+	switch string(data[:4]) {
+	case "\x00\x01\x00\x00", "true":
+	default:
+		return errors.New("font harus berupa TrueType (.ttf)")
+	}
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes("check", "", data)
+	pdf.SetFont("check", "", 12)
+	pdf.AddPage()
+	pdf.Cell(0, 6, "Aa")
+	return pdf.Output(io.Discard)
+}
+
+// setupFonts mendaftarkan font theme ke dokumen dan mengembalikan payload yang siap
+// ditulis. Urutan: font unggahan (t.Fonts), font bawaan, lalu font inti gofpdf.
+// Font inti hanya mengenal cp1252, jadi teksnya diterjemahkan; bila ada karakter di
+// luar cp1252, surat beralih ke UnicodeFont agar nama tidak rusak.
+func setupFonts(pdf *gofpdf.Fpdf, t *Theme, p Payload) Payload {
+	var custom []Font
+	for _, f := range t.Fonts {
+		if strings.EqualFold(f.Family, t.FontFamily) && len(f.Data) > 0 {
+			custom = append(custom, f)
+		}
+	}
+	if len(custom) > 0 {
+		registerFonts(pdf, t.FontFamily, custom)
+		return p
+	}
+	if registerBundled(pdf, t.FontFamily) {
+		return p
+	}
+	if !isCoreFont(t.FontFamily) {
+		// family tidak dikenal: jangan sampai SetFont gagal.
+		t.FontFamily = "Times"
+	}
+	if !fitsCP1252(p) {
+		registerBundled(pdf, UnicodeFont)
+		t.FontFamily = UnicodeFont
+		return p
+	}
+	return translatePayload(p, pdf.UnicodeTranslatorFromDescriptor(""))
+}
+
+func registerBundled(pdf *gofpdf.Fpdf, family string) bool {
+	files, ok := bundledFonts[strings.ToLower(family)]
+	if !ok {
+		return false
+	}
+	fonts := make([]Font, 0, len(files))
+	for style, name := range files {
+		data, err := bundledFS.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		fonts = append(fonts, Font{Family: family, Style: style, Data: data})
+	}
+	registerFonts(pdf, family, fonts)
+	return len(fonts) > 0
+}
+
+func registerFonts(pdf *gofpdf.Fpdf, family string, fonts []Font) {
+	byStyle := map[string][]byte{}
+	for _, f := range fonts {
+		if style, ok := NormalizeFontStyle(f.Style); ok {
+			byStyle[style] = f.Data
+		}
+	}
+	var regular []byte
+	for _, style := range fontStyles {
+		if regular = byStyle[style]; regular != nil {
+			break
+		}
+	}
+	for _, style := range fontStyles {
+		data := byStyle[style]
+		if data == nil {
+			if style == "BI" && byStyle["B"] != nil {
+				data = byStyle["B"]
+			} else {
+				data = regular
+			}
+		}
+		pdf.AddUTF8FontFromBytes(family, style, data)
+	}
+}
+
+func isCoreFont(family string) bool {
+	switch strings.ToLower(family) {
+	case "times", "helvetica", "arial", "courier":
+		return true
+	}
+	return false
+}
+
+// cp1252Extra adalah karakter cp1252 di rentang 0x80-0x9F.
+const cp1252Extra = "€‚ƒ„…†‡ˆ‰Š‹ŒŽ‘’“”•–—˜™š›œžŸ"
+
+func fitsCP1252(p Payload) bool {
+	ok := true
+	eachText(&p, func(s string) string {
+		for _, r := range s {
+			if r < 0x80 || (r >= 0xA0 && r <= 0xFF) || strings.ContainsRune(cp1252Extra, r) {
+				continue
+			}
+			ok = false
+			break
+		}
+		return s
+	})
+	return ok
+}
+
+func translatePayload(p Payload, tr func(string) string) Payload {
+	eachText(&p, tr)
+	return p
+}
+
+// eachText menerapkan fn ke semua teks yang dicetak dari payload. Header dan
+// slice disalin dulu supaya payload milik pemanggil tidak ikut berubah.
+// Verification tidak disentuh karena URL-nya dipakai sebagai isi QR.
+func eachText(p *Payload, fn func(string) string) {
+	list := func(in []string) []string {
+		if in == nil {
+			return nil
+		}
+		out := make([]string, len(in))
+		for i, s := range in {
+			out[i] = fn(s)
+		}
+		return out
+	}
+	if p.Header != nil {
+		h := *p.Header
+		h.Title = list(h.Title)
+		h.OrgName = fn(h.OrgName)
+		h.OrgUnit = fn(h.OrgUnit)
+		h.OrgAddress = fn(h.OrgAddress)
+		h.OrgPhone = fn(h.OrgPhone)
+		p.Header = &h
+	}
+	m := &p.Meta
+	m.Number, m.Subject, m.Lampiran = fn(m.Number), fn(m.Subject), fn(m.Lampiran)
+	m.ToRole, m.ToName, m.ToPlace, m.ToCity = fn(m.ToRole), fn(m.ToName), fn(m.ToPlace), fn(m.ToCity)
+	m.PlaceAndDate = fn(m.PlaceAndDate)
+	p.Body = list(p.Body)
+	p.BodyOpening = fn(p.BodyOpening)
+	p.BodyContent = list(p.BodyContent)
+	p.BodyClosing = fn(p.BodyClosing)
+	p.Footer = fn(p.Footer)
+	p.Tembusan = list(p.Tembusan)
+	if p.Signs != nil {
+		signs := make([]Signer, len(p.Signs))
+		for i, sg := range p.Signs {
+			sg.Name, sg.Role, sg.NIP, sg.StampText = fn(sg.Name), fn(sg.Role), fn(sg.NIP), fn(sg.StampText)
+			signs[i] = sg
+		}
+		p.Signs = signs
+	}
+}
@@ -0,0 +1,41 @@
+package suratpdf
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Gaya garis bawah kop surat.
+const (
+	LineSingle    = "single"
+	LineDouble    = "double"
+	LineThickThin = "thick_thin" // garis tebal lalu tipis, gaya kop surat resmi
+	LineNone      = "none"
+)
+
+// NormalizeLineStyle mengembalikan gaya garis yang dikenal; kosong/tidak dikenal = single.
+func NormalizeLineStyle(v string) string {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case LineDouble:
+		return LineDouble
+	case LineThickThin, "thick-thin":
+		return LineThickThin
+	case LineNone:
+		return LineNone
+	default:
+		return LineSingle
+	}
+}
+
+// ParseHexColor membaca warna "#RRGGBB" (tanda # opsional).
+func ParseHexColor(v string) (r, g, b int, ok bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "#")
+	if len(v) != 6 {
+		return 0, 0, 0, false
+	}
+	n, err := strconv.ParseUint(v, 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return int(n >> 16 & 0xff), int(n >> 8 & 0xff), int(n & 0xff), true
+}
@@ -4,59 +4,64 @@ import (
 	"bytes"
 	"encoding/base64"
 	"fmt"
+	"hash/crc32"
+	"net/http"
 	"os"
 	"strings"
 
 	"github.com/jung-kurt/gofpdf"
+	"github.com/skip2/go-qrcode"
 )
 
-// Render menghasilkan PDF surat dengan layout mendekati template contoh (F4).
+// Render menghasilkan PDF surat dengan layout mendekati template contoh (default F4).
 // Header: logo kiri/kanan + judul tengah + garis horizontal + tanggal kanan.
 // Nomor/Lampiran/Perihal/Hal di kiri.
 // Penerima dibold.
 // Tanda tangan grid 3 kolom per baris dengan cap dan opsional TTD.
 // Tembusan ditampilkan di bawah.
 func Render(p Payload, th *Theme) ([]byte, error) {
-	// Default theme jika tidak diisi
-	t := Theme{
-		FontFamily:   "Times",
-		FontSize:     12,
-		MarginTop:    15,
-		MarginRight:  20,
-		MarginBottom: 20,
-		MarginLeft:   20,
-	}
-	if th != nil {
-		if th.FontFamily != "" {
-			t.FontFamily = th.FontFamily
-		}
-		if th.FontSize > 0 {
-			t.FontSize = th.FontSize
-		}
-		if th.MarginTop > 0 {
-			t.MarginTop = th.MarginTop
-		}
-		if th.MarginRight > 0 {
-			t.MarginRight = th.MarginRight
-		}
-		if th.MarginBottom > 0 {
-			t.MarginBottom = th.MarginBottom
-		}
-		if th.MarginLeft > 0 {
-			t.MarginLeft = th.MarginLeft
-		}
-	}
+	return RenderWithAttachments(p, th, nil)
+}
+
+// RenderWithAttachments sama dengan Render, lalu menambahkan halaman lampiran
+// (PDF/gambar) di belakang halaman surat.
+func RenderWithAttachments(p Payload, th *Theme, atts []Attachment) ([]byte, error) {
+	t := ResolveTheme(th)
 
-	// F4/Legal size: 210 x 330 mm
+	pageW, pageH := PageSize(t)
 	pdf := gofpdf.NewCustom(&gofpdf.InitType{
 		UnitStr: "mm",
-		Size:    gofpdf.SizeType{Wd: 210, Ht: 330},
+		Size:    gofpdf.SizeType{Wd: pageW, Ht: pageH},
 	})
 	pdf.SetMargins(t.MarginLeft, t.MarginTop, t.MarginRight)
 	pdf.SetAutoPageBreak(true, t.MarginBottom)
-	pdf.AddPage()
+	p = setupFonts(pdf, &t, p)
 	pdf.SetFont(t.FontFamily, "", t.FontSize)
 
+	// letterPages = 0 selama halaman surat masih ditulis; kop ulang dan nomor
+	// halaman tidak dicetak di halaman lampiran.
+	letterPages := 0
+	inLetter := func() bool { return letterPages == 0 || pdf.PageNo() <= letterPages }
+	if t.RepeatHeader {
+		pdf.SetHeaderFunc(func() {
+			if pdf.PageNo() > 1 && inLetter() {
+				renderKop(pdf, p.Header)
+				pdf.Ln(2)
+			}
+		})
+	}
+	if t.PageNumbers {
+		pdf.SetFooterFunc(func() {
+			if !inLetter() {
+				return
+			}
+			pdf.SetY(-t.MarginBottom + 5)
+			pdf.SetFont(t.FontFamily, "", 9)
+			pdf.CellFormat(0, 5, fmt.Sprintf("Halaman %d dari %s", pdf.PageNo(), pageTotalAlias), "", 0, "C", false, 0, "")
+		})
+	}
+	pdf.AddPage()
+
 	lm, _, _, _ := pdf.GetMargins()
 	baseX := lm
 
@@ -67,7 +72,7 @@ func Render(p Payload, th *Theme) ([]byte, error) {
 
 	// Body
 	bodyX := contentX
-	bodyParas := collectBodyParas(p)
+	bodyParas := BodyParagraphs(p)
 	for _, para := range bodyParas {
 		pdf.SetX(bodyX)
 		multiCell(pdf, para)
@@ -85,6 +90,11 @@ func Render(p Payload, th *Theme) ([]byte, error) {
 		renderSignsGrid(pdf, p.Signs)
 	}
 
+	// Stempel verifikasi (QR) untuk surat yang sudah disetujui
+	if p.Verification != nil && strings.TrimSpace(p.Verification.Code) != "" {
+		renderVerification(pdf, *p.Verification, baseX, t)
+	}
+
 	// Tembusan
 	if len(p.Tembusan) > 0 {
 		pdf.Ln(4)
@@ -96,6 +106,12 @@ func Render(p Payload, th *Theme) ([]byte, error) {
 		}
 	}
 
+	letterPages = pdf.PageNo()
+	pdf.RegisterAlias(pageTotalAlias, fmt.Sprint(letterPages))
+	if err := appendAttachments(pdf, atts); err != nil {
+		return nil, err
+	}
+
 	var buf bytes.Buffer
 	if err := pdf.Output(&buf); err != nil {
 		return nil, err
@@ -155,9 +171,83 @@ func multiCell(pdf *gofpdf.Fpdf, text string) {
 	pdf.SetX(origX)
 }
 
-// collectBodyParas menyusun paragraf dari opening, content list, dan closing.
+// pageTotalAlias diganti jumlah halaman surat (tanpa lampiran) saat PDF ditulis.
+const pageTotalAlias = "{np}"
+
+// Ukuran kertas yang didukung (mm, portrait).
+var paperSizes = map[string][2]float64{
+	"F4":     {210, 330},
+	"A4":     {210, 297},
+	"LETTER": {215.9, 279.4},
+	"LEGAL":  {215.9, 355.6},
+}
+
+// PageSize mengembalikan lebar dan tinggi halaman (mm) sesuai PaperSize dan Orientation.
+func PageSize(t Theme) (float64, float64) {
+	size, ok := paperSizes[strings.ToUpper(strings.TrimSpace(t.PaperSize))]
+	if !ok {
+		size = paperSizes["F4"]
+	}
+	if strings.EqualFold(strings.TrimSpace(t.Orientation), "landscape") {
+		return size[1], size[0]
+	}
+	return size[0], size[1]
+}
+
+// ValidPaper true bila PaperSize dan Orientation dikenal (kosong = default).
+func ValidPaper(t Theme) bool {
+	if _, ok := paperSizes[strings.ToUpper(strings.TrimSpace(t.PaperSize))]; !ok && strings.TrimSpace(t.PaperSize) != "" {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(t.Orientation)) {
+	case "", "portrait", "landscape":
+		return true
+	}
+	return false
+}
+
+// ResolveTheme mengisi field theme yang kosong dengan nilai default.
+func ResolveTheme(th *Theme) Theme {
+	// Default theme jika tidak diisi
+	t := Theme{
+		FontFamily:   "Times",
+		FontSize:     12,
+		MarginTop:    15,
+		MarginRight:  20,
+		MarginBottom: 20,
+		MarginLeft:   20,
+	}
+	if th != nil {
+		if th.FontFamily != "" {
+			t.FontFamily = th.FontFamily
+		}
+		if th.FontSize > 0 {
+			t.FontSize = th.FontSize
+		}
+		if th.MarginTop > 0 {
+			t.MarginTop = th.MarginTop
+		}
+		if th.MarginRight > 0 {
+			t.MarginRight = th.MarginRight
+		}
+		if th.MarginBottom > 0 {
+			t.MarginBottom = th.MarginBottom
+		}
+		if th.MarginLeft > 0 {
+			t.MarginLeft = th.MarginLeft
+		}
+		t.PaperSize = th.PaperSize
+		t.Orientation = th.Orientation
+		t.PageNumbers = th.PageNumbers
+		t.RepeatHeader = th.RepeatHeader
+		t.Fonts = th.Fonts
+	}
+	return t
+}
+
+// BodyParagraphs menyusun paragraf dari opening, content list, dan closing.
 // Jika ketiganya kosong, akan fallback ke Body lama.
-func collectBodyParas(p Payload) []string {
+func BodyParagraphs(p Payload) []string {
 	var out []string
 	hasNew := strings.TrimSpace(p.BodyOpening) != "" || len(p.BodyContent) > 0 || strings.TrimSpace(p.BodyClosing) != ""
 	if hasNew {
@@ -180,65 +270,117 @@ func collectBodyParas(p Payload) []string {
 }
 
 func renderHeader(pdf *gofpdf.Fpdf, p Payload) {
+	_, _, rm, _ := pdf.GetMargins()
+	renderKop(pdf, p.Header)
+	// Tanggal di kanan
+	if strings.TrimSpace(p.Meta.PlaceAndDate) != "" {
+		origRight := rm
+		shift := 0.10 // geser tanggal 10 mm ke kiri
+		if origRight > shift {
+			pdf.SetRightMargin(origRight - shift)
+		}
+		pdf.CellFormat(0, 6, p.Meta.PlaceAndDate, "", 1, "R", false, 0, "")
+		pdf.SetRightMargin(origRight)
+	}
+	pdf.Ln(2)
+}
+
+// renderKop menggambar logo, baris judul, dan garis kop. Dipakai juga untuk kop
+// ulang di halaman lanjutan (Theme.RepeatHeader).
+func renderKop(pdf *gofpdf.Fpdf, h *Header) {
 	lm, _, rm, _ := pdf.GetMargins()
 	pageW, _ := pdf.GetPageSize()
 
 	// Logo + title
-	if p.Header != nil {
-		// Susun baris title dari field terstruktur bila Title kosong.
-		lines := p.Header.Title
-		if len(lines) == 0 {
-			if strings.TrimSpace(p.Header.OrgName) != "" {
-				lines = append(lines, p.Header.OrgName)
-			}
-			if strings.TrimSpace(p.Header.OrgUnit) != "" {
-				lines = append(lines, p.Header.OrgUnit)
-			}
-			if strings.TrimSpace(p.Header.OrgAddress) != "" {
-				lines = append(lines, p.Header.OrgAddress)
-			}
-			if strings.TrimSpace(p.Header.OrgPhone) != "" {
-				lines = append(lines, p.Header.OrgPhone)
-			}
-		}
+	if h != nil {
+		lines := HeaderLines(h)
 
 		y := pdf.GetY()
 		leftX := lm
 		rightX := pageW - rm - 30
 
 		// left logo (skip jika kosong)
-		if strings.TrimSpace(p.Header.LeftLogo) != "" {
-			_ = drawBase64OrFile(pdf, p.Header.LeftLogo, leftX, y, 25, 25)
+		if strings.TrimSpace(h.LeftLogo) != "" {
+			_ = drawBase64OrFile(pdf, h.LeftLogo, leftX, y, 25, 25)
 		}
 		// right logo
-		if strings.TrimSpace(p.Header.RightLogo) != "" {
-			_ = drawBase64OrFile(pdf, p.Header.RightLogo, rightX, y, 25, 25)
+		if strings.TrimSpace(h.RightLogo) != "" {
+			_ = drawBase64OrFile(pdf, h.RightLogo, rightX, y, 25, 25)
 		}
 
 		// center title
 		if len(lines) > 0 {
+			if r, g, b, ok := ParseHexColor(h.TextColor); ok {
+				pdf.SetTextColor(r, g, b)
+			}
 			pdf.SetXY(leftX, y)
 			for _, line := range lines {
 				pdf.CellFormat(pageW-lm-rm, 6, line, "", 1, "C", false, 0, "")
 			}
+			pdf.SetTextColor(0, 0, 0)
 			pdf.Ln(4)
 		}
 	}
 	// garis horizontal
+	renderHeaderLine(pdf, h, lm, pageW-rm)
+}
+
+// renderHeaderLine menggambar garis bawah kop sesuai LineStyle dan LineColor header.
+func renderHeaderLine(pdf *gofpdf.Fpdf, h *Header, x1, x2 float64) {
+	style, color := LineSingle, ""
+	if h != nil {
+		style, color = NormalizeLineStyle(h.LineStyle), h.LineColor
+	}
+	if style == LineNone {
+		pdf.Ln(3)
+		return
+	}
+	if r, g, b, ok := ParseHexColor(color); ok {
+		pdf.SetDrawColor(r, g, b)
+	}
+	width := pdf.GetLineWidth()
 	y := pdf.GetY()
-	pdf.Line(lm, y, pageW-rm, y)
+	switch style {
+	case LineDouble:
+		pdf.Line(x1, y, x2, y)
+		y += 1
+		pdf.Line(x1, y, x2, y)
+	case LineThickThin:
+		pdf.SetLineWidth(0.8)
+		pdf.Line(x1, y, x2, y)
+		pdf.SetLineWidth(width)
+		y += 1.2
+		pdf.Line(x1, y, x2, y)
+	default:
+		pdf.Line(x1, y, x2, y)
+	}
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.SetY(y)
 	pdf.Ln(3)
-	// Tanggal di kanan
-	if strings.TrimSpace(p.Meta.PlaceAndDate) != "" {
-		origRight := rm
-		shift := 0.10 // geser tanggal 10 mm ke kiri
-		if origRight > shift {
-			pdf.SetRightMargin(origRight - shift)
+}
+
+// HeaderLines mengembalikan baris judul kop. Bila Title kosong, baris dirangkai
+// dari field terstruktur OrgName, OrgUnit, OrgAddress, dan OrgPhone.
+func HeaderLines(h *Header) []string {
+	if h == nil {
+		return nil
+	}
+	lines := h.Title
+	if len(lines) == 0 {
+		if strings.TrimSpace(h.OrgName) != "" {
+			lines = append(lines, h.OrgName)
+		}
+		if strings.TrimSpace(h.OrgUnit) != "" {
+			lines = append(lines, h.OrgUnit)
+		}
+		if strings.TrimSpace(h.OrgAddress) != "" {
+			lines = append(lines, h.OrgAddress)
+		}
+		if strings.TrimSpace(h.OrgPhone) != "" {
+			lines = append(lines, h.OrgPhone)
 		}
-		pdf.CellFormat(0, 6, p.Meta.PlaceAndDate, "", 1, "R", false, 0, "")
-		pdf.SetRightMargin(origRight)
 	}
-	pdf.Ln(2)
+	return lines
 }
 
 func renderNumberTable(pdf *gofpdf.Fpdf, p Payload, baseX float64) {
@@ -302,18 +444,7 @@ func renderSignsGrid(pdf *gofpdf.Fpdf, signs []Signer) {
 		return
 	}
 	pdf.Ln(2)
-	n := len(signs)
-	// Layout: if 3 -> 2 top, 1 bottom centered. if 4 -> 2 top, 2 bottom. else max 3 per row.
-	var rows [][]Signer
-	if n == 3 {
-		rows = [][]Signer{signs[:2], signs[2:]}
-	} else if n == 4 {
-		rows = [][]Signer{signs[:2], signs[2:4]}
-	} else {
-		for i := 0; i < n; i += 3 {
-			rows = append(rows, signs[i:min(i+3, n)])
-		}
-	}
+	rows := SignerRows(signs)
 
 	pageW, _ := pdf.GetPageSize()
 	_, lm, rm, _ := pdf.GetMargins()
@@ -343,6 +474,23 @@ func renderSignsGrid(pdf *gofpdf.Fpdf, signs []Signer) {
 	}
 }
 
+// SignerRows membagi penanda tangan per baris grid.
+// Layout: if 3 -> 2 top, 1 bottom centered. if 4 -> 2 top, 2 bottom. else max 3 per row.
+func SignerRows(signs []Signer) [][]Signer {
+	n := len(signs)
+	var rows [][]Signer
+	if n == 3 {
+		rows = [][]Signer{signs[:2], signs[2:]}
+	} else if n == 4 {
+		rows = [][]Signer{signs[:2], signs[2:4]}
+	} else {
+		for i := 0; i < n; i += 3 {
+			rows = append(rows, signs[i:min(i+3, n)])
+		}
+	}
+	return rows
+}
+
 func renderSignerBlock(pdf *gofpdf.Fpdf, x, y, w, h float64, s Signer) {
 	pdf.SetXY(x, y)
 	pdf.SetFontStyle("B")
@@ -376,6 +524,46 @@ func renderSignerBlock(pdf *gofpdf.Fpdf, x, y, w, h float64, s Signer) {
 	}
 }
 
+// renderVerification menggambar QR code + kode verifikasi di bawah grid tanda tangan.
+func renderVerification(pdf *gofpdf.Fpdf, v Verification, baseX float64, t Theme) {
+	content := v.URL
+	if strings.TrimSpace(content) == "" {
+		content = v.Code
+	}
+	png, err := qrcode.Encode(content, qrcode.Medium, 256)
+	if err != nil {
+		return
+	}
+
+	qrSize := 25.0
+	_, pageH := pdf.GetPageSize()
+	_, _, _, bm := pdf.GetMargins()
+	if pdf.GetY()+qrSize > pageH-bm {
+		pdf.AddPage()
+	}
+	pdf.Ln(2)
+	y := pdf.GetY()
+
+	name := "verify_" + v.Code
+	pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+	pdf.ImageOptions(name, baseX, y, qrSize, qrSize, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+	pdf.SetFont(t.FontFamily, "", 8)
+	textX := baseX + qrSize + 3
+	pdf.SetXY(textX, y+4)
+	pdf.CellFormat(0, 4, "Dokumen ini disetujui secara elektronik.", "", 1, "", false, 0, "")
+	pdf.SetX(textX)
+	pdf.SetFontStyle("B")
+	pdf.CellFormat(0, 4, "Kode verifikasi: "+v.Code, "", 1, "", false, 0, "")
+	pdf.SetFontStyle("")
+	if strings.TrimSpace(v.URL) != "" {
+		pdf.SetX(textX)
+		pdf.CellFormat(0, 4, "Periksa keaslian: "+v.URL, "", 1, "", false, 0, "")
+	}
+	pdf.SetFont(t.FontFamily, "", t.FontSize)
+	pdf.SetY(y + qrSize)
+}
+
 func drawStamp(pdf *gofpdf.Fpdf, centerX, centerY, radius float64, imageSrc string, text string) {
 	if drawBase64OrFile(pdf, imageSrc, centerX-radius, centerY-radius, radius*2, radius*2) {
 		return
@@ -403,14 +591,25 @@ func drawBase64OrFile(pdf *gofpdf.Fpdf, src string, x, y, w, h float64) bool {
 			data = data[idx+1:]
 		}
 	}
-	// Try base64 decode first
+	// Try base64 decode first. Tipe gambar dideteksi dari isinya karena spesimen
+	// TTD/cap bisa PNG maupun JPEG.
 	if b, err := base64.StdEncoding.DecodeString(data); err == nil {
-		tmp := "/tmp/stamp_tmp.png"
-		if err := os.WriteFile(tmp, b, 0o644); err == nil {
-			pdf.ImageOptions(tmp, x, y, w, h, false, gofpdf.ImageOptions{}, 0, "")
-			_ = os.Remove(tmp)
-			return true
+		var imgType string
+		switch http.DetectContentType(b) {
+		case "image/png":
+			imgType = "PNG"
+		case "image/jpeg":
+			imgType = "JPG"
+		case "image/gif":
+			imgType = "GIF"
+		default:
+			return false
 		}
+		opt := gofpdf.ImageOptions{ImageType: imgType}
+		name := fmt.Sprintf("img_%08x", crc32.ChecksumIEEE(b))
+		pdf.RegisterImageOptionsReader(name, opt, bytes.NewReader(b))
+		pdf.ImageOptions(name, x, y, w, h, false, opt, 0, "")
+		return true
 	}
 	// Try as file path - but check if file exists first to avoid error
 	if _, err := os.Stat(src); err == nil {
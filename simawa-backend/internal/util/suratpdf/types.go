@@ -15,12 +15,20 @@ const (
 )
 
 type Theme struct {
-	FontFamily   string  `json:"font_family"` // e.g. "Times"
+	FontFamily   string  `json:"font_family"` // e.g. "Times", "DejaVuSans", atau font unggahan organisasi
 	FontSize     float64 `json:"font_size"`   // e.g. 12
 	MarginTop    float64 `json:"margin_top"`  // mm
 	MarginRight  float64 `json:"margin_right"`
 	MarginBottom float64 `json:"margin_bottom"`
 	MarginLeft   float64 `json:"margin_left"`
+
+	PaperSize    string `json:"paper_size,omitempty"`    // F4 (default), A4, Letter, Legal
+	Orientation  string `json:"orientation,omitempty"`   // portrait (default) / landscape
+	PageNumbers  bool   `json:"page_numbers,omitempty"`  // "Halaman x dari y" di kaki halaman surat
+	RepeatHeader bool   `json:"repeat_header,omitempty"` // kop diulang di halaman lanjutan
+
+	// Fonts TTF unggahan organisasi; diisi backend saat render, tidak disimpan.
+	Fonts []Font `json:"-"`
 }
 
 type Header struct {
@@ -40,6 +48,11 @@ type Header struct {
 	OrgUnit    string `json:"org_unit,omitempty"`
 	OrgAddress string `json:"org_address,omitempty"`
 	OrgPhone   string `json:"org_phone,omitempty"`
+
+	// Gaya kop dari profil kop organisasi: LineStyle (lihat Line*), warna "#RRGGBB".
+	LineStyle string `json:"line_style,omitempty"`
+	TextColor string `json:"text_color,omitempty"`
+	LineColor string `json:"line_color,omitempty"`
 }
 
 type Meta struct {
@@ -57,9 +70,14 @@ type Signer struct {
 	Name      string `json:"name"`
 	Role      string `json:"role"`
 	NIP       string `json:"nip,omitempty"`
-	Stamp     string `json:"stamp_base64,omitempty"` // optional base64 image
-	TTD       string `json:"ttd_base64,omitempty"`   // optional base64 image
+	Stamp     string `json:"stamp_base64,omitempty"` // diisi backend dari cap organisasi OrgID
+	TTD       string `json:"ttd_base64,omitempty"`   // diisi backend dari spesimen TTD UserID
 	StampText string `json:"stamp_text,omitempty"`   // fallback text when stamp image is absent
+
+	// UserID penanda tangan; TTD-nya hanya dicetak setelah user ini menyetujui surat.
+	// OrgID (opsional) organisasi yang capnya dibubuhkan bersama TTD tersebut.
+	UserID string `json:"user_id,omitempty"`
+	OrgID  string `json:"org_id,omitempty"`
 }
 
 type Payload struct {
@@ -75,4 +93,13 @@ type Payload struct {
 	Footer      string   `json:"footer"`                 // optional note/footer
 	Signs       []Signer `json:"signs"`                  // one or more signers
 	Tembusan    []string `json:"tembusan,omitempty"`
+
+	// Verification diisi backend saat surat disetujui; tidak diterima dari request.
+	Verification *Verification `json:"verification,omitempty"`
+}
+
+// Verification adalah stempel QR yang dicetak di samping tanda tangan surat yang disetujui.
+type Verification struct {
+	Code string `json:"code"` // kode verifikasi singkat, mis. "K7M2-QX9P"
+	URL  string `json:"url"`  // isi QR code, mengarah ke endpoint verifikasi publik
 }
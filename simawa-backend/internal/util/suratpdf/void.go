@@ -0,0 +1,84 @@
+package suratpdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/jung-kurt/gofpdf/contrib/gofpdi"
+)
+
+// VoidLabel adalah cap yang ditumpuk pada setiap halaman surat yang dibatalkan.
+const VoidLabel = "DIBATALKAN"
+
+// StampVoid menumpuk cap "DIBATALKAN" miring di setiap halaman PDF src beserta
+// alasan dan waktu pembatalan di kaki halaman. Isi asli tetap terbaca.
+func StampVoid(src []byte, reason string, at time.Time) (out []byte, err error) {
+	// gofpdi memakai panic untuk PDF yang tidak bisa dibaca
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("file surat tidak dapat dibaca sebagai PDF: %v", r)
+		}
+	}()
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetAutoPageBreak(false, 0)
+	registerBundled(pdf, UnicodeFont)
+	note := fmt.Sprintf("Dibatalkan %s", at.Format("02-01-2006 15:04"))
+	if r := strings.TrimSpace(reason); r != "" {
+		note += ": " + r
+	}
+
+	imp := gofpdi.NewImporter()
+	var rs io.ReadSeeker = bytes.NewReader(src)
+	first := imp.ImportPageFromStream(pdf, &rs, 1, "/MediaBox")
+	sizes := imp.GetPageSizes()
+	for page := 1; page <= len(sizes); page++ {
+		tpl := first
+		if page > 1 {
+			tpl = imp.ImportPageFromStream(pdf, &rs, page, "/MediaBox")
+		}
+		box := sizes[page]["/MediaBox"]
+		w, h := ptToMM(box["w"]), ptToMM(box["h"])
+		if w <= 0 || h <= 0 {
+			return nil, fmt.Errorf("ukuran halaman %d tidak valid", page)
+		}
+		pdf.AddPageFormat("P", gofpdf.SizeType{Wd: w, Ht: h})
+		imp.UseImportedTemplate(pdf, tpl, 0, 0, w, h)
+		stampVoidPage(pdf, w, h, note)
+	}
+	if err := pdf.Error(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func stampVoidPage(pdf *gofpdf.Fpdf, w, h float64, note string) {
+	size := math.Min(w, h) / 5 * 72 / 25.4 // ±1/5 lebar halaman
+	pdf.SetFont(UnicodeFont, "B", size)
+	pdf.SetTextColor(200, 0, 0)
+	pdf.SetDrawColor(200, 0, 0)
+	pdf.SetAlpha(0.35, "Normal")
+	tw := pdf.GetStringWidth(VoidLabel)
+	_, lh := pdf.GetFontSize()
+	cx, cy := w/2, h/2
+	pdf.TransformBegin()
+	pdf.TransformRotate(math.Atan2(h, w)*180/math.Pi, cx, cy)
+	pdf.SetLineWidth(1.5)
+	pdf.Rect(cx-tw/2-4, cy-lh/2-2, tw+8, lh+4, "D")
+	pdf.Text(cx-tw/2, cy+lh*0.35, VoidLabel)
+	pdf.TransformEnd()
+	pdf.SetAlpha(1, "Normal")
+
+	pdf.SetFont(UnicodeFont, "", 8)
+	pdf.SetXY(10, h-10)
+	pdf.CellFormat(w-20, 5, note, "", 0, "C", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+}
@@ -0,0 +1,131 @@
+package suratsign
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Algorithm adalah nama algoritma tanda tangan yang disimpan bersama surat.
+const Algorithm = "Ed25519"
+
+// Keystore memegang kunci Ed25519 lokal untuk menandatangani PDF surat yang sudah disetujui.
+type Keystore struct {
+	keyID string
+	priv  ed25519.PrivateKey
+	pub   ed25519.PublicKey
+}
+
+// Signature adalah tanda tangan terpisah (detached) atas isi file PDF.
+type Signature struct {
+	KeyID       string
+	ContentHash string // sha256 hex dari isi file
+	Value       string // base64 tanda tangan atas digest sha256
+}
+
+// LoadOrCreate membaca kunci privat PKCS#8 (PEM) dari path. Jika file belum ada,
+// kunci baru dibuat dan disimpan dengan permission 0600 agar instalasi baru langsung bisa menandatangani.
+func LoadOrCreate(path, keyID string) (*Keystore, error) {
+	if path == "" {
+		return nil, errors.New("sign key path empty")
+	}
+	if keyID == "" {
+		return nil, errors.New("sign key id empty")
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return create(path, keyID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read sign key: %w", err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("sign key %s: expected PKCS#8 PRIVATE KEY pem", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse sign key: %w", err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("sign key %s: only ed25519 keys are supported", path)
+	}
+	return &Keystore{keyID: keyID, priv: priv, pub: priv.Public().(ed25519.PublicKey)}, nil
+}
+
+func create(path, keyID string) (*Keystore, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("create sign key dir: %w", err)
+		}
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("write sign key: %w", err)
+	}
+	return &Keystore{keyID: keyID, priv: priv, pub: pub}, nil
+}
+
+// KeyID mengembalikan identitas kunci aktif.
+func (k *Keystore) KeyID() string { return k.keyID }
+
+// PublicKeyPEM mengekspor kunci publik (PKIX PEM) supaya pihak luar bisa memverifikasi sendiri.
+func (k *Keystore) PublicKeyPEM() (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(k.pub)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// Sign menghitung hash isi file lalu menandatangani digest-nya.
+func (k *Keystore) Sign(content []byte) Signature {
+	sum := sha256.Sum256(content)
+	return Signature{
+		KeyID:       k.keyID,
+		ContentHash: hex.EncodeToString(sum[:]),
+		Value:       base64.StdEncoding.EncodeToString(ed25519.Sign(k.priv, sum[:])),
+	}
+}
+
+// VerifySignature memeriksa bahwa tanda tangan tersimpan sah untuk hash yang tersimpan.
+func (k *Keystore) VerifySignature(sig Signature) error {
+	if sig.KeyID != k.keyID {
+		return fmt.Errorf("kunci penandatangan %q tidak tersedia", sig.KeyID)
+	}
+	digest, err := hex.DecodeString(sig.ContentHash)
+	if err != nil || len(digest) != sha256.Size {
+		return errors.New("hash konten tidak valid")
+	}
+	value, err := base64.StdEncoding.DecodeString(sig.Value)
+	if err != nil {
+		return errors.New("format tanda tangan tidak valid")
+	}
+	if !ed25519.Verify(k.pub, digest, value) {
+		return errors.New("tanda tangan tidak cocok")
+	}
+	return nil
+}
+
+// Digest mengembalikan sha256 hex dari isi file.
+func Digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}